@@ -1,21 +1,52 @@
 package main
 
 import (
+	"context"
 	"duckdb-server/config"
+	"duckdb-server/internal/logging"
 	grpcArrow "duckdb-server/internal/services/grpc_arrow"
+	httpAdmin "duckdb-server/internal/services/http_admin"
+	"duckdb-server/internal/tracing"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
 	"log"
-	"sync"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 func main() {
+	// .env is optional, its variables don't override the environment
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Fatalf("Error loading .env file, err: %v", err)
+	}
+
+	cfg, printConfig, err := loadConfig(flag.ExitOnError)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(2)
+	}
+	if printConfig {
+		if err := cfg.WriteYAML(os.Stdout); err != nil {
+			log.Fatalf("Error printing the configuration, err: %v", err)
+		}
+		return
+	}
+
 	{
-		err := godotenv.Load()
+		level, err := logging.ParseLevel(cfg.Log.Level)
 		if err != nil {
-			log.Fatal("Error loading .env file")
+			log.Fatalf("Error parsing LOG_LEVEL, err: %v", err)
+		}
+		if err := logging.Init(os.Stderr, logging.Config{Level: level, Format: cfg.Log.Format}); err != nil {
+			log.Fatalf("Error setting up logging, err: %v", err)
 		}
-		config.GetConfig()
 	}
 
 	// starting gRPC server
@@ -25,20 +56,116 @@ func main() {
 		// 	port = 9005
 		// )
 
-		// go grpc.InitServer(host, port)
+		// go grpc.InitServer(host, port, cfg)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Init(ctx, tracing.Config{
+		Exporter:     cfg.Tracing.Exporter,
+		OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+		OTLPInsecure: cfg.Tracing.OTLPInsecure,
+		File:         cfg.Tracing.File,
+		SampleRatio:  cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		slog.Error("error setting up tracing", "err", err)
+		os.Exit(1)
 	}
 
 	// starting gRPC server for arrow
-	{
-		var (
-			host = config.HOST
-			port = config.PORT
-		)
+	server, err := grpcArrow.InitServer(cfg, func() (*config.Config, error) {
+		cfg, _, err := loadConfig(flag.ContinueOnError)
+		return cfg, err
+	})
+	if err != nil {
+		slog.Error("error starting grpc_arrow server", "err", err)
+		os.Exit(1)
+	}
+
+	serveErr := make(chan error, 2)
+	go func() {
+		serveErr <- server.Serve()
+	}()
+
+	var adminServer *httpAdmin.Server
+	if cfg.Server.HTTPPort > 0 || len(cfg.Server.AdminListen) > 0 {
+		adminServer, err = httpAdmin.InitServer(cfg, server.Health(), server.Admin(), server.TLS())
+		if err != nil {
+			slog.Error("error starting http_admin server", "err", err)
+			server.Shutdown(0)
+			os.Exit(1)
+		}
 
-		go grpcArrow.InitServer(host, port)
+		go func() {
+			serveErr <- adminServer.Serve()
+		}()
 	}
 
-	wg := &sync.WaitGroup{}
-	wg.Add(2)
-	wg.Wait()
+	// SIGHUP reloads the configuration, the flags and the environment are
+	// those of the start so only the file can change
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-hup:
+				slog.Info("received SIGHUP, reloading the configuration")
+				if err := server.Reload(ctx); err != nil {
+					slog.Error("error reloading configuration", "err", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		slog.Info("received shutdown signal, draining requests")
+	case err := <-serveErr:
+		slog.Error("server stopped", "err", err)
+		exitCode = 1
+	}
+
+	// a second signal kills the process right away
+	stop()
+
+	if err := server.Shutdown(cfg.Server.ShutdownGracePeriod); err != nil {
+		slog.Error("error shutting down", "err", err)
+		exitCode = 1
+	}
+
+	// metrics stay available while the requests drain
+	if adminServer != nil {
+		if err := adminServer.Shutdown(context.Background()); err != nil {
+			slog.Error("error shutting down http_admin server", "err", err)
+			exitCode = 1
+		}
+	}
+
+	// flush the spans of the last requests
+	flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Error("error shutting down tracing", "err", err)
+		exitCode = 1
+	}
+	cancel()
+
+	slog.Info("server stopped")
+	os.Exit(exitCode)
+}
+
+// loadConfig loads the configuration from the file, the environment and the
+// command line, telling whether --print-config was given.
+func loadConfig(errorHandling flag.ErrorHandling) (*config.Config, bool, error) {
+	flags := flag.NewFlagSet(os.Args[0], errorHandling)
+	printConfig := flags.Bool("print-config", false, "print the configuration, secrets redacted, and exit")
+	cfg, err := config.Load(flags, os.Args[1:], os.LookupEnv)
+	if err != nil {
+		return nil, false, err
+	}
+	return cfg, *printConfig, nil
 }
@@ -1,68 +1,275 @@
+// Package config holds the configuration of the server, loaded by Load from
+// the defaults, a YAML file, the environment and the command line flags.
+//
+// Every setting has a key in the file, the path of its yaml tags, an
+// environment variable, its env tag, and a flag, the variable in lower case
+// with dashes, like --chunk-size for CHUNK_SIZE. Durations given as plain
+// numbers, as the environment variables always were, are in the unit of
+// their unit tag. The settings tagged reload can change while the server
+// runs, the others need a restart.
 package config
 
 import (
-	"log"
-	"os"
+	"net"
 	"strconv"
+	"time"
 )
 
-func getEnv(key string) string {
-	val := os.Getenv(key)
-	if val == "" {
-		log.Fatalf("Missing required environment variable: %s", key)
-	}
-	return val
+type Config struct {
+	Server       Server       `yaml:"server"`
+	Dirs         Dirs         `yaml:"dirs"`
+	DuckDB       DuckDB       `yaml:"duckdb"`
+	Log          Log          `yaml:"log"`
+	Tracing      Tracing      `yaml:"tracing"`
+	Profiling    Profiling    `yaml:"profiling"`
+	QueryHistory QueryHistory `yaml:"query_history"`
+	Health       Health       `yaml:"health"`
+	Stream       Stream       `yaml:"stream"`
+	TLS          TLS          `yaml:"tls"`
+	Auth         Auth         `yaml:"auth"`
+	Sandbox      Sandbox      `yaml:"sandbox"`
+	SQL          SQL          `yaml:"sql"`
+	Download     Download     `yaml:"download"`
+	S3           S3           `yaml:"s3"`
+	Admission    Admission    `yaml:"admission"`
 }
 
-func getEnvAsInt(key string) int {
-	val := os.Getenv(key)
-	if val == "" {
-		log.Fatalf("Missing required environment variable: %s", key)
-	}
+type Server struct {
+	// Host and Port are the address of the gRPC API when Listen is empty, an
+	// empty host listening on all interfaces.
+	Host string `yaml:"host" env:"HOST"`
+	Port int    `yaml:"port" env:"PORT"`
+	// Listen is the addresses of the gRPC API, host:port or unix:/path for
+	// Unix sockets.
+	Listen []string `yaml:"listen" env:"LISTEN"`
+	// AdminListen is the addresses serving the admin and health RPCs next to
+	// the HTTP endpoints, instead of the API addresses and HTTPPort.
+	AdminListen []string `yaml:"admin_listen" env:"ADMIN_LISTEN"`
+	// SocketMode is the permissions of the Unix sockets, in octal.
+	SocketMode string `yaml:"socket_mode" env:"SOCKET_MODE"`
+	// HTTPPort is the port of the HTTP server serving the probes, /metrics
+	// and /debug/pprof on Host, 0 disables it.
+	HTTPPort int `yaml:"http_port" env:"HTTP_PORT"`
+	// ShutdownGracePeriod is how long running requests get to finish on
+	// shutdown.
+	ShutdownGracePeriod time.Duration `yaml:"shutdown_grace_period" env:"SHUTDOWN_GRACE_PERIOD" unit:"s"`
+}
 
-	v, err := strconv.Atoi(val)
-	if err != nil {
-		log.Fatalf("Error parsing environment variable: %s, err: %v", key, err)
-	}
-	return v
+type Dirs struct {
+	TempDownload string `yaml:"temp_download" env:"TEMP_DOWNLOAD_DIR" required:"true"`
+	TempProf     string `yaml:"temp_prof" env:"TEMP_PROF_DIR" required:"true"`
+	TempDuckDB   string `yaml:"temp_duckdb" env:"TEMP_DUCKDB_DIR" required:"true"`
+	DuckDB       string `yaml:"duckdb" env:"DUCKDB_DIR" required:"true"`
 }
 
-type serviceConfig struct {
-	Host      string
-	Port      int
-	ProfDir   string
-	DuckDBDir string
+// DuckDB is the resources of the database. They can only change while the
+// server runs when Sandbox.LockConfiguration is off.
+type DuckDB struct {
+	// MemoryLimit is like 2GB or 80%.
+	MemoryLimit string `yaml:"memory_limit" env:"DUCKDB_MEMORY_LIMIT" reload:"true"`
+	Threads     int    `yaml:"threads" env:"DUCKDB_THREADS" reload:"true"`
 }
 
-var GRPC = serviceConfig{
-	ProfDir:   "/Users/pramodj/Documents/Projects/github/pramod-janardhana/go-duckdb/prof",
-	DuckDBDir: "/Users/pramodj/Documents/Projects/github/pramod-janardhana/go-duckdb/duckdb",
+type Log struct {
+	// Level is one of debug, info, warn and error. Queries and the query
+	// strings of remote sources are only logged in full at the debug level.
+	Level string `yaml:"level" env:"LOG_LEVEL" reload:"true"`
+	// Format is text or json.
+	Format string `yaml:"format" env:"LOG_FORMAT"`
 }
 
-var (
-	HOST string
-	PORT int
-)
+type Tracing struct {
+	// Exporter is where the OpenTelemetry spans go: none, otlp (gRPC to
+	// OTLPEndpoint), stdout or file (JSON lines in File).
+	Exporter     string `yaml:"exporter" env:"TRACING_EXPORTER"`
+	OTLPEndpoint string `yaml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	OTLPInsecure bool   `yaml:"otlp_insecure" env:"TRACING_OTLP_INSECURE"`
+	File         string `yaml:"file" env:"TRACING_FILE"`
+	// SampleRatio is the share of the traces started by the server that are
+	// sampled, the sampling decision of the caller wins otherwise.
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
 
-var (
-	TEMP_DOWNLOAD_DIR string
-	TEMP_PROF_DIR     string
-	TEMP_DUCKDB_DIR   string
-	DUCKDB_DIR        string
-)
+type Profiling struct {
+	// Enabled serves /debug/pprof on the HTTP port and enables the
+	// CaptureProfile RPC, storing the profiles in Dirs.TempProf.
+	Enabled bool `yaml:"enabled" env:"PROFILING_ENABLED"`
+	// MaxFiles and MaxAge bound the profiles kept, 0 meaning no limit.
+	MaxFiles int           `yaml:"max_files" env:"PROFILE_MAX_FILES"`
+	MaxAge   time.Duration `yaml:"max_age" env:"PROFILE_MAX_AGE" unit:"s"`
+}
+
+type QueryHistory struct {
+	// Enabled records every transformation in the query_history table of the
+	// database, MaxRows being the number of entries kept, 0 meaning no
+	// limit.
+	Enabled bool `yaml:"enabled" env:"QUERY_HISTORY_ENABLED"`
+	MaxRows int  `yaml:"max_rows" env:"QUERY_HISTORY_MAX_ROWS"`
+	// SlowThreshold is the duration from which a transformation is logged
+	// with its EXPLAIN ANALYZE output, 0 disabling the log.
+	SlowThreshold time.Duration `yaml:"slow_threshold" env:"SLOW_QUERY_THRESHOLD" unit:"ms"`
+}
+
+type Health struct {
+	// CheckInterval and CheckTimeout are how often the readiness checks run
+	// and how long each may take.
+	CheckInterval time.Duration `yaml:"check_interval" env:"HEALTH_CHECK_INTERVAL" unit:"s"`
+	CheckTimeout  time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT" unit:"s"`
+	// MinFreeDisk is the space, in bytes, Dirs.TempDownload and Dirs.DuckDB
+	// must have left for the server to be ready.
+	MinFreeDisk int64 `yaml:"min_free_disk" env:"HEALTH_MIN_FREE_DISK"`
+}
+
+type Stream struct {
+	// ChunkSize is the number of rows of the chunks of the Arrow responses,
+	// FileChunkSize the number of bytes of the chunks of the files.
+	ChunkSize     int `yaml:"chunk_size" env:"CHUNK_SIZE" required:"true" reload:"true"`
+	FileChunkSize int `yaml:"file_chunk_size" env:"FILE_CHUNK_SIZE" required:"true" reload:"true"`
+}
+
+// TLS of the gRPC listener, disabled unless a certificate is given. Setting a
+// client CA turns on mutual TLS.
+type TLS struct {
+	CertFile       string        `yaml:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile        string        `yaml:"key_file" env:"TLS_KEY_FILE"`
+	ClientCAFile   string        `yaml:"client_ca_file" env:"TLS_CLIENT_CA_FILE"`
+	ReloadInterval time.Duration `yaml:"reload_interval" env:"TLS_RELOAD_INTERVAL" unit:"s"`
+}
 
-var CHUNK_SIZE int
-var FILE_CHUNK_SIZE int
+// Auth is the bearer token authentication, disabled unless File is set. File
+// holds the roles and the API keys, JWTs are accepted when JWKSFile is set.
+type Auth struct {
+	File          string `yaml:"file" env:"AUTH_FILE"`
+	JWKSFile      string `yaml:"jwks_file" env:"AUTH_JWKS_FILE"`
+	JWTIssuer     string `yaml:"jwt_issuer" env:"AUTH_JWT_ISSUER"`
+	JWTAudience   string `yaml:"jwt_audience" env:"AUTH_JWT_AUDIENCE"`
+	JWTRolesClaim string `yaml:"jwt_roles_claim" env:"AUTH_JWT_ROLES_CLAIM"`
+}
+
+// Sandbox is the local files requests may read, every path being allowed when
+// Roots is empty. The server doesn't start with Roots set when DuckDB can't
+// restrict the files queries access. LockConfiguration stops queries from
+// changing the DuckDB settings, which the SQL validation already rejects, but
+// DuckDB.* then requires a restart to change.
+type Sandbox struct {
+	Roots             []string `yaml:"roots" env:"SANDBOX_ROOTS"`
+	LockConfiguration bool     `yaml:"lock_configuration" env:"DUCKDB_LOCK_CONFIGURATION"`
+}
+
+// SQL is the validation of the transformation queries given as SQL, the CTEs
+// a query defines being always allowed.
+type SQL struct {
+	MaxLength             int      `yaml:"max_length" env:"SQL_MAX_LENGTH"`
+	AllowedRelations      []string `yaml:"allowed_relations" env:"SQL_ALLOWED_RELATIONS"`
+	AllowedTableFunctions []string `yaml:"allowed_table_functions" env:"SQL_ALLOWED_TABLE_FUNCTIONS"`
+	DeniedFunctions       []string `yaml:"denied_functions" env:"SQL_DENIED_FUNCTIONS"`
+}
+
+// Download of the remote sources. Private and other special purpose addresses
+// are denied unless AllowedCIDRs is set.
+type Download struct {
+	Timeout        time.Duration `yaml:"timeout" env:"DOWNLOAD_TIMEOUT" unit:"s"`
+	ConnectTimeout time.Duration `yaml:"connect_timeout" env:"DOWNLOAD_CONNECT_TIMEOUT" unit:"s"`
+	Retries        int           `yaml:"retries" env:"DOWNLOAD_RETRIES"`
+	Backoff        time.Duration `yaml:"backoff" env:"DOWNLOAD_BACKOFF" unit:"s"`
+	MaxBytes       int64         `yaml:"max_bytes" env:"DOWNLOAD_MAX_BYTES"`
+	AllowHTTP      bool          `yaml:"allow_http" env:"DOWNLOAD_ALLOW_HTTP"`
+	AllowedHosts   []string      `yaml:"allowed_hosts" env:"DOWNLOAD_ALLOWED_HOSTS"`
+	DeniedHosts    []string      `yaml:"denied_hosts" env:"DOWNLOAD_DENIED_HOSTS"`
+	AllowedCIDRs   []string      `yaml:"allowed_cidrs" env:"DOWNLOAD_ALLOWED_CIDRS"`
+	DeniedCIDRs    []string      `yaml:"denied_cidrs" env:"DOWNLOAD_DENIED_CIDRS"`
+	// StreamRemoteSources pipes remote sources to DuckDB as they are
+	// downloaded instead of downloading them to Dirs.TempDownload first.
+	StreamRemoteSources bool `yaml:"stream_remote_sources" env:"STREAM_REMOTE_SOURCES"`
+	// CacheMaxBytes keeps up to this many bytes of http(s) sources in the
+	// cache directory of Dirs.TempDownload to be reused while unchanged, 0
+	// disables the cache. Cached sources are not streamed.
+	CacheMaxBytes int64 `yaml:"cache_max_bytes" env:"DOWNLOAD_CACHE_MAX_BYTES"`
+}
+
+// S3 is the S3-compatible object storage of the s3:// sources and
+// destinations. The credentials are taken from the AWS_* variables or the
+// instance metadata when AccessKeyID is not set, PathStyle is needed by most
+// stand-ins.
+type S3 struct {
+	Endpoint        string   `yaml:"endpoint" env:"S3_ENDPOINT"`
+	Region          string   `yaml:"region" env:"S3_REGION"`
+	AccessKeyID     string   `yaml:"access_key_id" env:"S3_ACCESS_KEY_ID"`
+	SecretAccessKey string   `yaml:"secret_access_key" env:"S3_SECRET_ACCESS_KEY" secret:"true"`
+	SessionToken    string   `yaml:"session_token" env:"S3_SESSION_TOKEN" secret:"true"`
+	PathStyle       bool     `yaml:"path_style" env:"S3_PATH_STYLE"`
+	PartSize        int64    `yaml:"part_size" env:"S3_PART_SIZE"`
+	AllowedBuckets  []string `yaml:"allowed_buckets" env:"S3_ALLOWED_BUCKETS"`
+}
+
+type Admission struct {
+	MaxConcurrentQueries int   `yaml:"max_concurrent_queries" env:"MAX_CONCURRENT_QUERIES" reload:"true"`
+	MemoryBudget         int64 `yaml:"memory_budget" env:"ADMISSION_MEMORY_BUDGET" reload:"true"`
+	// QueueSize is the number of requests waiting for a slot, 0 meaning no
+	// queue: requests arriving while every slot is taken are rejected.
+	QueueSize    int           `yaml:"queue_size" env:"ADMISSION_QUEUE_SIZE" reload:"true"`
+	QueueTimeout time.Duration `yaml:"queue_timeout" env:"ADMISSION_QUEUE_TIMEOUT" unit:"s" reload:"true"`
+}
 
-func GetConfig() {
-	HOST = getEnv("HOST")
-	PORT = getEnvAsInt("PORT")
+// Default returns the configuration used for the settings set nowhere else.
+func Default() *Config {
+	return &Config{
+		Server: Server{SocketMode: "0660", ShutdownGracePeriod: 30 * time.Second},
+		DuckDB: DuckDB{MemoryLimit: "2GB", Threads: 4},
+		Log:    Log{Level: "info", Format: "text"},
+		Tracing: Tracing{
+			Exporter:     "none",
+			OTLPEndpoint: "localhost:4317",
+			SampleRatio:  1,
+		},
+		Profiling: Profiling{MaxFiles: 20, MaxAge: 7 * 24 * time.Hour},
+		QueryHistory: QueryHistory{
+			Enabled: true,
+			MaxRows: 100000,
+		},
+		Health: Health{
+			CheckInterval: 10 * time.Second,
+			CheckTimeout:  5 * time.Second,
+			MinFreeDisk:   1 << 30,
+		},
+		TLS:  TLS{ReloadInterval: 30 * time.Second},
+		Auth: Auth{JWTRolesClaim: "roles"},
+		SQL: SQL{
+			MaxLength:             64 << 10,
+			AllowedRelations:      []string{"loadtest"},
+			AllowedTableFunctions: []string{"range", "generate_series", "unnest"},
+			DeniedFunctions:       []string{"current_setting", "getenv", "nextval", "setval"},
+		},
+		Download: Download{
+			Timeout:        600 * time.Second,
+			ConnectTimeout: 10 * time.Second,
+			Retries:        3,
+			Backoff:        time.Second,
+			MaxBytes:       4 << 30,
+		},
+		S3: S3{PartSize: 64 << 20},
+		Admission: Admission{
+			MaxConcurrentQueries: 2,
+			MemoryBudget:         2 << 30,
+			QueueSize:            16,
+			QueueTimeout:         60 * time.Second,
+		},
+	}
+}
 
-	TEMP_PROF_DIR = getEnv("TEMP_PROF_DIR")
-	TEMP_DUCKDB_DIR = getEnv("TEMP_DUCKDB_DIR")
-	DUCKDB_DIR = getEnv("DUCKDB_DIR")
-	TEMP_DOWNLOAD_DIR = getEnv("TEMP_DOWNLOAD_DIR")
+// Addresses returns the addresses of the gRPC API.
+func (s Server) Addresses() []string {
+	if len(s.Listen) > 0 {
+		return s.Listen
+	}
+	return []string{net.JoinHostPort(s.Host, strconv.Itoa(s.Port))}
+}
 
-	CHUNK_SIZE = getEnvAsInt("CHUNK_SIZE")
-	FILE_CHUNK_SIZE = getEnvAsInt("FILE_CHUNK_SIZE")
+// AdminAddresses returns the addresses of the HTTP server, which listens on
+// the host of the gRPC API unless AdminListen is set.
+func (s Server) AdminAddresses() []string {
+	if len(s.AdminListen) > 0 {
+		return s.AdminListen
+	}
+	return []string{net.JoinHostPort(s.Host, strconv.Itoa(s.HTTPPort))}
 }
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Change is a setting differing between two configurations, the secrets
+// redacted.
+type Change struct {
+	// Path is the key of the setting in the file and Env its environment
+	// variable.
+	Path     string
+	Env      string
+	Old, New string
+	// Reloadable is set when the setting can change while the server runs.
+	Reloadable bool
+}
+
+// Diff returns the settings of c differing from old.
+func (c *Config) Diff(old *Config) []Change {
+	var changes []Change
+	olds := settings(old)
+	for i, s := range settings(c) {
+		o := olds[i]
+		if reflect.DeepEqual(s.value.Interface(), o.value.Interface()) {
+			continue
+		}
+		changes = append(changes, Change{
+			Path:       s.path,
+			Env:        s.env,
+			Old:        o.text(),
+			New:        s.text(),
+			Reloadable: s.reloadable,
+		})
+	}
+	return changes
+}
+
+// text returns the value of the setting as it would be given in the
+// environment.
+func (s setting) text() string {
+	switch {
+	case s.secret && !s.value.IsZero():
+		return "REDACTED"
+	case s.value.Kind() == reflect.Slice:
+		return strings.Join(s.value.Interface().([]string), ",")
+	default:
+		return fmt.Sprint(s.value.Interface())
+	}
+}
@@ -0,0 +1,296 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Load returns the configuration made of, from the lowest precedence to the
+// highest, the defaults, the YAML file given by --config or CONFIG_FILE, the
+// environment variables, empty ones counting as unset, and the flags. The
+// flags are added to fs, which parses args, the other errors are all reported
+// at once.
+func Load(fs *flag.FlagSet, args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	c := Default()
+	all := settings(c)
+
+	file := fs.String("config", "", "YAML configuration `file`, CONFIG_FILE in the environment")
+	byFlag := make(map[string]setting, len(all))
+	for _, s := range all {
+		byFlag[s.flag] = s
+		fs.Var(&flagValue{isBool: s.value.Kind() == reflect.Bool}, s.flag, fmt.Sprintf("%s, %s in the environment", s.path, s.env))
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	env := func(key string) string {
+		val, _ := lookupEnv(key)
+		return val
+	}
+
+	var errs []error
+	if path := *file; path != "" || env("CONFIG_FILE") != "" {
+		if path == "" {
+			path = env("CONFIG_FILE")
+		}
+		errs = append(errs, loadFile(path, all)...)
+	}
+
+	for _, s := range all {
+		if val := env(s.env); val != "" {
+			if err := s.set(val); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.env, err))
+			}
+		}
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		s, ok := byFlag[f.Name]
+		if !ok {
+			return
+		}
+		if err := s.set(f.Value.String()); err != nil {
+			errs = append(errs, fmt.Errorf("--%s: %w", f.Name, err))
+		}
+	})
+
+	if err := c.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// WriteYAML writes the configuration in the format of the file, with the
+// secrets redacted.
+func (c *Config) WriteYAML(w io.Writer) error {
+	redacted := *c
+	for _, s := range settings(&redacted) {
+		if s.secret && !s.value.IsZero() {
+			s.value.SetString("REDACTED")
+		}
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&redacted); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// setting is a leaf of the configuration.
+type setting struct {
+	// path is the key of the setting in the file, like server.port
+	path string
+	env  string
+	flag string
+	// unit is the unit of the durations given as plain numbers
+	unit       time.Duration
+	required   bool
+	secret     bool
+	reloadable bool
+	value      reflect.Value
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+var units = map[string]time.Duration{"ms": time.Millisecond, "s": time.Second}
+
+// settings returns the settings of c, their values pointing into it.
+func settings(c *Config) []setting {
+	var all []setting
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			path := prefix + f.Tag.Get("yaml")
+			if f.Type.Kind() == reflect.Struct {
+				walk(v.Field(i), path+".")
+				continue
+			}
+
+			env := f.Tag.Get("env")
+			all = append(all, setting{
+				path:       path,
+				env:        env,
+				flag:       strings.ReplaceAll(strings.ToLower(env), "_", "-"),
+				unit:       units[f.Tag.Get("unit")],
+				required:   f.Tag.Get("required") == "true",
+				secret:     f.Tag.Get("secret") == "true",
+				reloadable: f.Tag.Get("reload") == "true",
+				value:      v.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(c).Elem(), "")
+	return all
+}
+
+// name returns how the setting is called in the errors.
+func (s setting) name() string {
+	return fmt.Sprintf("%s (%s)", s.path, s.env)
+}
+
+// set sets the setting from its text in a flag, an environment variable or
+// the file. Lists are comma separated.
+func (s setting) set(text string) error {
+	v := s.value
+	switch {
+	case v.Type() == durationType:
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			v.SetInt(n * int64(s.unit))
+			return nil
+		}
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", text)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(text)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", text)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int || v.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", text)
+		}
+		v.SetInt(n)
+	case v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", text)
+		}
+		v.SetFloat(f)
+	case v.Kind() == reflect.Slice:
+		var list []string
+		for _, item := range strings.Split(text, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		v.Set(reflect.ValueOf(list))
+	default:
+		panic(fmt.Sprintf("config: unsupported type %s of %s", v.Type(), s.path))
+	}
+	return nil
+}
+
+// setNode sets the setting from its node in the file.
+func (s setting) setNode(n *yaml.Node) error {
+	switch {
+	case n.Kind == yaml.ScalarNode && n.Tag == "!!null":
+		s.value.Set(reflect.Zero(s.value.Type()))
+		return nil
+	case n.Kind == yaml.SequenceNode && s.value.Kind() == reflect.Slice:
+		list := make([]string, 0, len(n.Content))
+		for _, item := range n.Content {
+			if item.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: %s: the items must be strings", item.Line, s.path)
+			}
+			list = append(list, item.Value)
+		}
+		s.value.Set(reflect.ValueOf(list))
+		return nil
+	case n.Kind == yaml.ScalarNode:
+		if err := s.set(n.Value); err != nil {
+			return fmt.Errorf("line %d: %s: %w", n.Line, s.path, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("line %d: %s: unexpected value", n.Line, s.path)
+	}
+}
+
+// loadFile sets the settings of the YAML file at path, rejecting unknown
+// keys.
+func loadFile(path string, all []setting) []error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []error{err}
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return []error{fmt.Errorf("%s: %w", path, err)}
+	}
+	if len(root.Content) == 0 {
+		// empty file
+		return nil
+	}
+
+	byPath := make(map[string]setting, len(all))
+	sections := make(map[string]bool)
+	for _, s := range all {
+		byPath[s.path] = s
+		for i, r := range s.path {
+			if r == '.' {
+				sections[s.path[:i]] = true
+			}
+		}
+	}
+
+	var errs []error
+	var walk func(n *yaml.Node, section string)
+	walk = func(n *yaml.Node, section string) {
+		if n.Kind != yaml.MappingNode {
+			errs = append(errs, fmt.Errorf("%s: line %d: %s must be a mapping", path, n.Line, section))
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			p := key.Value
+			if section != "" {
+				p = section + "." + key.Value
+			}
+
+			if s, ok := byPath[p]; ok {
+				if err := s.setNode(value); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", path, err))
+				}
+			} else if sections[p] {
+				walk(value, p)
+			} else {
+				errs = append(errs, fmt.Errorf("%s: line %d: unknown setting %s", path, key.Line, p))
+			}
+		}
+	}
+	walk(root.Content[0], "")
+	return errs
+}
+
+// flagValue keeps the text of a flag, set once all the flags are parsed.
+type flagValue struct {
+	text   string
+	isBool bool
+}
+
+func (f *flagValue) String() string {
+	return f.text
+}
+
+func (f *flagValue) Set(text string) error {
+	f.text = text
+	return nil
+}
+
+func (f *flagValue) IsBoolFlag() bool {
+	return f.isBool
+}
@@ -0,0 +1,259 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// required are the settings without defaults, in the environment.
+var required = map[string]string{
+	"PORT":              "9006",
+	"TEMP_DOWNLOAD_DIR": "/tmp/download",
+	"TEMP_PROF_DIR":     "/tmp/prof",
+	"TEMP_DUCKDB_DIR":   "/tmp/duckdb",
+	"DUCKDB_DIR":        "/data",
+	"CHUNK_SIZE":        "1000",
+	"FILE_CHUNK_SIZE":   "65536",
+}
+
+// load loads the configuration from the arguments, the required settings
+// and the environment variables, and the file when not empty.
+func load(t *testing.T, file string, env map[string]string, args ...string) (*Config, error) {
+	t.Helper()
+
+	all := map[string]string{}
+	for k, v := range required {
+		all[k] = v
+	}
+	for k, v := range env {
+		all[k] = v
+	}
+	if file != "" {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		all["CONFIG_FILE"] = path
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return Load(fs, args, func(key string) (string, bool) {
+		val, ok := all[key]
+		return val, ok
+	})
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := `
+log:
+  level: debug
+  format: json
+duckdb:
+  threads: 8
+  memory_limit: 4GB
+admission:
+  queue_size: 4
+`
+	c, err := load(t, file,
+		map[string]string{"DUCKDB_THREADS": "6", "DUCKDB_MEMORY_LIMIT": "3GB", "LOG_FORMAT": "", "ADMISSION_QUEUE_TIMEOUT": "5"},
+		"--duckdb-threads=2", "--max-concurrent-queries", "3")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		got, want any
+	}{
+		{"default", c.Tracing.Exporter, "none"},
+		{"file", c.Log.Level, "debug"},
+		{"file over an empty variable", c.Log.Format, "json"},
+		{"environment", c.Admission.QueueTimeout, 5 * time.Second},
+		{"environment over the file", c.DuckDB.MemoryLimit, "3GB"},
+		{"flag", c.Admission.MaxConcurrentQueries, 3},
+		{"flag over the environment and the file", c.DuckDB.Threads, 2},
+		{"file over a default", c.Admission.QueueSize, 4},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// --config wins over CONFIG_FILE
+	path := filepath.Join(t.TempDir(), "other.yaml")
+	if err := os.WriteFile(path, []byte("log:\n  level: error\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err = load(t, file, nil, "--config", path)
+	if err != nil || c.Log.Level != "error" {
+		t.Errorf("Load with --config = %v, log level %q", err, c.Log.Level)
+	}
+}
+
+func TestLoadUnknownKeys(t *testing.T) {
+	_, err := load(t, "server:\n  prot: 1\nlogs:\n  level: debug\nlog: debug\n", nil)
+	if err == nil {
+		t.Fatal("Load accepted unknown settings")
+	}
+	for _, want := range []string{"line 2: unknown setting server.prot", "line 3: unknown setting logs", "log must be a mapping"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q, want %q", err, want)
+		}
+	}
+}
+
+func TestLoadValues(t *testing.T) {
+	file := `
+server:
+  shutdown_grace_period: 45
+  listen: [":9006", "unix:/run/duckdb.sock"]
+query_history:
+  slow_threshold: 250
+download:
+  timeout: 1m30s
+  max_bytes: 1073741824
+  allowed_hosts:
+tracing:
+  sample_ratio: 0.5
+`
+	c, err := load(t, file, map[string]string{"SANDBOX_ROOTS": " /data, ,/srv ", "DOWNLOAD_ALLOW_HTTP": "true"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		got, want any
+	}{
+		{"seconds", c.Server.ShutdownGracePeriod, 45 * time.Second},
+		{"milliseconds", c.QueryHistory.SlowThreshold, 250 * time.Millisecond},
+		{"duration", c.Download.Timeout, 90 * time.Second},
+		{"size", c.Download.MaxBytes, int64(1 << 30)},
+		{"float", c.Tracing.SampleRatio, 0.5},
+		{"boolean", c.Download.AllowHTTP, true},
+		{"list", strings.Join(c.Server.Listen, "|"), ":9006|unix:/run/duckdb.sock"},
+		{"comma separated list", strings.Join(c.Sandbox.Roots, "|"), "/data|/srv"},
+		{"null list", len(c.Download.AllowedHosts), 0},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	for env, want := range map[string]string{
+		"SHUTDOWN_GRACE_PERIOD": "invalid duration",
+		"DOWNLOAD_MAX_BYTES":    "invalid integer",
+		"DOWNLOAD_ALLOW_HTTP":   "invalid boolean",
+		"TRACING_SAMPLE_RATIO":  "invalid number",
+	} {
+		if _, err := load(t, "", map[string]string{env: "4GB"}); err == nil || !strings.Contains(err.Error(), env+": "+want) {
+			t.Errorf("Load with %s=4GB = %v, want %q", env, err, want)
+		}
+	}
+	if _, err := load(t, "download:\n  timeout: soon\n", nil); err == nil || !strings.Contains(err.Error(), "line 2: download.timeout: invalid duration") {
+		t.Errorf("Load with an invalid duration in the file = %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	_, err := load(t, "", map[string]string{
+		"DUCKDB_DIR":           "",
+		"CHUNK_SIZE":           "-1",
+		"HTTP_PORT":            "9006",
+		"LOG_LEVEL":            "verbose",
+		"TRACING_EXPORTER":     "file",
+		"TRACING_SAMPLE_RATIO": "2",
+		"DUCKDB_THREADS":       "0",
+		"TLS_KEY_FILE":         "/tls/key.pem",
+		"AUTH_JWKS_FILE":       "/auth/jwks.json",
+		"S3_ACCESS_KEY_ID":     "key",
+		"LISTEN":               "unix:",
+		"ADMISSION_QUEUE_SIZE": "-1",
+	})
+	if err == nil {
+		t.Fatal("Load accepted an invalid configuration")
+	}
+
+	// every error is reported at once
+	for _, want := range []string{
+		"dirs.duckdb (DUCKDB_DIR) is required",
+		"stream.chunk_size (CHUNK_SIZE) can't be negative",
+		"server.http_port (HTTP_PORT) must differ from the gRPC port",
+		"log.level (LOG_LEVEL) must be debug, info, warn or error",
+		"tracing.file (TRACING_FILE) is required by the file exporter",
+		"tracing.sample_ratio (TRACING_SAMPLE_RATIO) must be between 0 and 1",
+		"duckdb.threads (DUCKDB_THREADS) must be between 1",
+		"tls.key_file (TLS_KEY_FILE) and tls.cert_file (TLS_CERT_FILE) go together",
+		"auth.jwks_file (AUTH_JWKS_FILE) requires auth.file",
+		"s3.secret_access_key (S3_SECRET_ACCESS_KEY) and s3.access_key_id",
+		"server.listen (LISTEN) has an",
+		"admission.queue_size (ADMISSION_QUEUE_SIZE) can't be negative",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("missing error %q in\n%v", want, err)
+		}
+	}
+
+	if _, err := load(t, "", nil); err != nil {
+		t.Errorf("Load with the required settings: %v", err)
+	}
+}
+
+func TestSecretsRedacted(t *testing.T) {
+	c, err := load(t, "", map[string]string{"S3_ACCESS_KEY_ID": "AKID", "S3_SECRET_ACCESS_KEY": "s3cr3t"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := c.WriteYAML(&out); err != nil {
+		t.Fatalf("WriteYAML: %v", err)
+	}
+	if strings.Contains(out.String(), "s3cr3t") || !strings.Contains(out.String(), "secret_access_key: REDACTED") {
+		t.Errorf("secret not redacted:\n%s", out.String())
+	}
+	// empty secrets are left empty, and the configuration untouched
+	if !strings.Contains(out.String(), `session_token: ""`) || c.S3.SecretAccessKey != "s3cr3t" {
+		t.Errorf("session token or configuration redacted:\n%s", out.String())
+	}
+
+	// the printed configuration loads back
+	path := filepath.Join(t.TempDir(), "printed.yaml")
+	if err := os.WriteFile(path, out.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := load(t, "", nil, "--config", path); err != nil {
+		t.Errorf("Load of the printed configuration: %v", err)
+	}
+
+	old := *c
+	c.S3.SecretAccessKey = "n3w"
+	c.DuckDB.Threads = 8
+	changes := c.Diff(&old)
+	if len(changes) != 2 {
+		t.Fatalf("changes %+v, want the threads and the secret", changes)
+	}
+	for _, ch := range changes {
+		switch ch.Path {
+		case "duckdb.threads":
+			if ch.Old != "4" || ch.New != "8" || !ch.Reloadable || ch.Env != "DUCKDB_THREADS" {
+				t.Errorf("change %+v", ch)
+			}
+		case "s3.secret_access_key":
+			if ch.Old != "REDACTED" || ch.New != "REDACTED" || ch.Reloadable {
+				t.Errorf("change %+v, want the secret redacted", ch)
+			}
+		default:
+			t.Errorf("unexpected change %+v", ch)
+		}
+	}
+}
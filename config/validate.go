@@ -0,0 +1,103 @@
+package config
+
+import (
+	"duckdb-server/internal/listen"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Validate checks the configuration, reporting all the invalid settings at
+// once.
+func (c *Config) Validate() error {
+	all := settings(c)
+	byPath := make(map[string]setting, len(all))
+	var errs []error
+	for _, s := range all {
+		byPath[s.path] = s
+		switch {
+		case s.required && s.value.IsZero():
+			errs = append(errs, fmt.Errorf("%s is required", s.name()))
+		case (s.value.Kind() == reflect.Int || s.value.Kind() == reflect.Int64) && s.value.Int() < 0:
+			errs = append(errs, fmt.Errorf("%s can't be negative", s.name()))
+		}
+	}
+
+	invalid := func(path, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s %s", byPath[path].name(), fmt.Sprintf(format, args...)))
+	}
+
+	if p := c.Server.Port; p > 65535 {
+		invalid("server.port", "must be a port number, not %d", p)
+	} else if p == 0 && len(c.Server.Listen) == 0 {
+		invalid("server.port", "is required unless server.listen (LISTEN) is set")
+	}
+	if p := c.Server.HTTPPort; p > 65535 {
+		invalid("server.http_port", "must be a port number, not %d", p)
+	} else if p != 0 && p == c.Server.Port {
+		invalid("server.http_port", "must differ from the gRPC port")
+	} else if p != 0 && len(c.Server.AdminListen) > 0 {
+		invalid("server.http_port", "can't be set with server.admin_listen (ADMIN_LISTEN), which serves the HTTP endpoints")
+	}
+	addresses := func(path string, addrs []string) {
+		for _, addr := range addrs {
+			if _, _, err := listen.Parse(addr); err != nil {
+				invalid(path, "has an %v", err)
+			}
+		}
+	}
+	addresses("server.listen", c.Server.Listen)
+	addresses("server.admin_listen", c.Server.AdminListen)
+	if _, err := listen.ParseMode(c.Server.SocketMode); err != nil {
+		invalid("server.socket_mode", "has %v", err)
+	}
+
+	if !slices.Contains([]string{"debug", "info", "warn", "error"}, strings.ToLower(c.Log.Level)) {
+		invalid("log.level", "must be debug, info, warn or error, not %q", c.Log.Level)
+	}
+	if !slices.Contains([]string{"text", "json"}, c.Log.Format) {
+		invalid("log.format", "must be text or json, not %q", c.Log.Format)
+	}
+
+	if !slices.Contains([]string{"none", "otlp", "stdout", "file"}, c.Tracing.Exporter) {
+		invalid("tracing.exporter", "must be none, otlp, stdout or file, not %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.Exporter == "file" && c.Tracing.File == "" {
+		invalid("tracing.file", "is required by the file exporter")
+	}
+	if r := c.Tracing.SampleRatio; r < 0 || r > 1 {
+		invalid("tracing.sample_ratio", "must be between 0 and 1, not %g", r)
+	}
+
+	if c.DuckDB.MemoryLimit == "" {
+		invalid("duckdb.memory_limit", "is required")
+	}
+	// DuckDB fails for good on more threads than an int32 holds
+	if t := c.DuckDB.Threads; t <= 0 || t > math.MaxInt32 {
+		invalid("duckdb.threads", "must be between 1 and %d, not %d", math.MaxInt32, t)
+	}
+	if c.Health.CheckInterval == 0 {
+		invalid("health.check_interval", "must be positive")
+	}
+	if c.Admission.MaxConcurrentQueries == 0 {
+		invalid("admission.max_concurrent_queries", "must be positive")
+	}
+
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		invalid("tls.key_file", "and tls.cert_file (TLS_CERT_FILE) go together")
+	}
+	if c.TLS.ClientCAFile != "" && c.TLS.CertFile == "" {
+		invalid("tls.client_ca_file", "requires tls.cert_file (TLS_CERT_FILE)")
+	}
+	if c.Auth.JWKSFile != "" && c.Auth.File == "" {
+		invalid("auth.jwks_file", "requires auth.file (AUTH_FILE)")
+	}
+	if (c.S3.AccessKeyID == "") != (c.S3.SecretAccessKey == "") {
+		invalid("s3.secret_access_key", "and s3.access_key_id (S3_ACCESS_KEY_ID) go together")
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,232 @@
+// Package admission limits how many queries run at once and how much memory
+// they are expected to use together. Requests that don't fit wait in a
+// priority queue, higher priorities first and FIFO within a priority.
+package admission
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+type Config struct {
+	// MaxConcurrent is the number of queries allowed to run at once.
+	MaxConcurrent int
+	// MemoryBudget is the total estimated memory, in bytes, of the running
+	// queries.
+	MemoryBudget int64
+	// MaxQueued is the number of requests allowed to wait, further requests
+	// are rejected right away. With 0 no request waits.
+	MaxQueued int
+	// QueueTimeout is how long a request waits before being rejected.
+	QueueTimeout time.Duration
+}
+
+type Controller struct {
+	cfg Config
+
+	mu      sync.Mutex
+	running int
+	inUse   int64
+	seq     uint64
+	queue   waitQueue
+}
+
+type Stats struct {
+	Running  int
+	Queued   int
+	InUse    int64
+	Capacity Config
+}
+
+func NewController(cfg Config) *Controller {
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = 1
+	}
+	return &Controller{cfg: cfg}
+}
+
+// Acquire blocks until a query of the given weight may run and returns the
+// function releasing its slot. It fails with RESOURCE_EXHAUSTED when the queue
+// is full or the request waited for longer than the queue timeout.
+func (c *Controller) Acquire(ctx context.Context, weight int64, priority int) (func(), error) {
+	c.mu.Lock()
+	// the limits can change, see SetConfig
+	cfg := c.cfg
+
+	// a query heavier than the whole budget runs on its own
+	if cfg.MemoryBudget > 0 && weight > cfg.MemoryBudget {
+		weight = cfg.MemoryBudget
+	}
+
+	if len(c.queue) == 0 && c.fits(weight) {
+		c.admit(weight)
+		c.mu.Unlock()
+		return c.releaseFunc(weight), nil
+	}
+
+	if len(c.queue) >= cfg.MaxQueued {
+		c.mu.Unlock()
+		return nil, exhausted("admission queue is full", cfg.QueueTimeout)
+	}
+
+	c.seq++
+	w := &waiter{weight: weight, priority: priority, seq: c.seq, ready: make(chan struct{})}
+	heap.Push(&c.queue, w)
+	c.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if cfg.QueueTimeout > 0 {
+		timer := time.NewTimer(cfg.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-w.ready:
+		return c.releaseFunc(weight), nil
+	case <-timeout:
+		if c.abandon(w) {
+			return c.releaseFunc(weight), nil
+		}
+		return nil, exhausted("timed out waiting for admission", cfg.QueueTimeout)
+	case <-ctx.Done():
+		if c.abandon(w) {
+			return c.releaseFunc(weight), nil
+		}
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// SetConfig changes the limits, the requests already admitted keeping their
+// slot. Waiting requests fitting the new limits are admitted right away.
+func (c *Controller) SetConfig(cfg Config) {
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = 1
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cfg = cfg
+	c.dispatch()
+}
+
+func (c *Controller) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return Stats{Running: c.running, Queued: len(c.queue), InUse: c.inUse, Capacity: c.cfg}
+}
+
+// abandon removes the waiter from the queue. It returns true when the waiter
+// got admitted in the meantime, in which case the caller owns the slot.
+func (c *Controller) abandon(w *waiter) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if w.index < 0 {
+		return true
+	}
+
+	heap.Remove(&c.queue, w.index)
+	// the waiter may have been blocking smaller requests behind it
+	c.dispatch()
+	return false
+}
+
+func (c *Controller) releaseFunc(weight int64) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			c.running--
+			c.inUse -= weight
+			c.dispatch()
+		})
+	}
+}
+
+// dispatch admits the waiters at the head of the queue for as long as they
+// fit. Must be called with c.mu held.
+func (c *Controller) dispatch() {
+	for len(c.queue) > 0 && c.fits(c.queue[0].weight) {
+		w := heap.Pop(&c.queue).(*waiter)
+		c.admit(w.weight)
+		close(w.ready)
+	}
+}
+
+func (c *Controller) fits(weight int64) bool {
+	if c.running >= c.cfg.MaxConcurrent {
+		return false
+	}
+	// nothing running means the query is admitted whatever its weight
+	return c.running == 0 || c.cfg.MemoryBudget <= 0 || c.inUse+weight <= c.cfg.MemoryBudget
+}
+
+func (c *Controller) admit(weight int64) {
+	c.running++
+	c.inUse += weight
+}
+
+func exhausted(msg string, retryAfter time.Duration) error {
+	if retryAfter <= 0 {
+		retryAfter = time.Second
+	}
+
+	st := status.New(codes.ResourceExhausted, msg)
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+type waiter struct {
+	weight   int64
+	priority int
+	seq      uint64
+	ready    chan struct{}
+	index    int
+}
+
+// waitQueue is a heap of waiters ordered by priority then arrival.
+type waitQueue []*waiter
+
+func (q waitQueue) Len() int { return len(q) }
+
+func (q waitQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q waitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waitQueue) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waitQueue) Pop() any {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*q = old[:n-1]
+	return w
+}
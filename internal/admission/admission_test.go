@@ -0,0 +1,294 @@
+package admission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// request is a call to Acquire running in the background.
+type request struct {
+	release func()
+	err     error
+	done    chan struct{}
+}
+
+func acquire(ctx context.Context, c *Controller, weight int64, priority int) *request {
+	r := &request{done: make(chan struct{})}
+	go func() {
+		defer close(r.done)
+		r.release, r.err = c.Acquire(ctx, weight, priority)
+	}()
+	return r
+}
+
+func (r *request) admitted(t *testing.T) bool {
+	t.Helper()
+
+	select {
+	case <-r.done:
+		if r.err != nil {
+			t.Fatalf("Acquire: %v", r.err)
+		}
+		return true
+	case <-time.After(50 * time.Millisecond):
+		return false
+	}
+}
+
+// waitQueued waits for n requests to be queued.
+func waitQueued(t *testing.T, c *Controller, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Stats().Queued != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d requests queued, want %d", c.Stats().Queued, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func mustAcquire(t *testing.T, c *Controller, weight int64) func() {
+	t.Helper()
+
+	release, err := c.Acquire(context.Background(), weight, 0)
+	if err != nil {
+		t.Fatalf("Acquire(%d): %v", weight, err)
+	}
+	return release
+}
+
+func TestAcquireLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		running []int64
+		weight  int64
+		admit   bool
+	}{
+		{"idle", Config{MaxConcurrent: 1, MaxQueued: 1}, nil, 10, true},
+		{"concurrency", Config{MaxConcurrent: 2, MaxQueued: 1}, []int64{1, 1}, 1, false},
+		{"below concurrency", Config{MaxConcurrent: 3, MaxQueued: 1}, []int64{1, 1}, 1, true},
+		{"zero concurrency means one", Config{MaxQueued: 1}, []int64{1}, 1, false},
+		{"within budget", Config{MaxConcurrent: 4, MemoryBudget: 100, MaxQueued: 1}, []int64{60}, 40, true},
+		{"over budget", Config{MaxConcurrent: 4, MemoryBudget: 100, MaxQueued: 1}, []int64{60}, 41, false},
+		{"heavier than the budget alone", Config{MaxConcurrent: 4, MemoryBudget: 100, MaxQueued: 1}, nil, 1000, true},
+		{"heavier than the budget", Config{MaxConcurrent: 4, MemoryBudget: 100, MaxQueued: 1}, []int64{1}, 1000, false},
+		{"no budget", Config{MaxConcurrent: 4, MaxQueued: 1}, []int64{1 << 40}, 1 << 40, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewController(tt.cfg)
+			var releases []func()
+			for _, w := range tt.running {
+				releases = append(releases, mustAcquire(t, c, w))
+			}
+
+			r := acquire(context.Background(), c, tt.weight, 0)
+			if got := r.admitted(t); got != tt.admit {
+				t.Fatalf("admitted = %t, want %t", got, tt.admit)
+			}
+			if tt.admit {
+				r.release()
+			} else {
+				// releasing the running requests admits it
+				for _, release := range releases {
+					release()
+				}
+				if !r.admitted(t) {
+					t.Fatal("not admitted once the others are done")
+				}
+				r.release()
+			}
+
+			for _, release := range releases {
+				release()
+			}
+			if stats := c.Stats(); stats.Running != 0 || stats.InUse != 0 || stats.Queued != 0 {
+				t.Errorf("stats %+v once everything is released", stats)
+			}
+		})
+	}
+}
+
+func TestAcquireOrder(t *testing.T) {
+	c := NewController(Config{MaxConcurrent: 1, MaxQueued: 10})
+	release := mustAcquire(t, c, 1)
+
+	// priority first, arrival within a priority
+	priorities := []int{0, 5, 0, 5, -1}
+	want := []int{1, 3, 0, 2, 4}
+
+	order := make(chan int, len(priorities))
+	for i, priority := range priorities {
+		r := acquire(context.Background(), c, 1, priority)
+		waitQueued(t, c, i+1)
+		go func(i int) {
+			<-r.done
+			order <- i
+			r.release()
+		}(i)
+	}
+
+	release()
+	for _, w := range want {
+		select {
+		case got := <-order:
+			if got != w {
+				t.Fatalf("request %d admitted, want %d", got, w)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("requests not admitted")
+		}
+	}
+}
+
+func TestAcquireQueueFull(t *testing.T) {
+	c := NewController(Config{MaxConcurrent: 1, MaxQueued: 1, QueueTimeout: 3 * time.Second})
+	release := mustAcquire(t, c, 1)
+	defer release()
+
+	queued := acquire(context.Background(), c, 1, 0)
+	waitQueued(t, c, 1)
+
+	_, err := c.Acquire(context.Background(), 1, 10)
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("Acquire = %v, want RESOURCE_EXHAUSTED", err)
+	}
+	var retry *errdetails.RetryInfo
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			retry = info
+		}
+	}
+	if retry == nil || retry.RetryDelay.AsDuration() != 3*time.Second {
+		t.Errorf("retry info %v, want a delay of 3s", retry)
+	}
+
+	release()
+	if !queued.admitted(t) {
+		t.Fatal("queued request not admitted")
+	}
+	queued.release()
+}
+
+func TestAcquireWithoutQueue(t *testing.T) {
+	c := NewController(Config{MaxConcurrent: 1, QueueTimeout: time.Second})
+	release := mustAcquire(t, c, 1)
+
+	if _, err := c.Acquire(context.Background(), 1, 0); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Acquire = %v, want RESOURCE_EXHAUSTED", err)
+	}
+	if stats := c.Stats(); stats.Queued != 0 {
+		t.Errorf("%d queued, want none", stats.Queued)
+	}
+
+	release()
+	mustAcquire(t, c, 1)()
+}
+
+func TestAcquireAbandoned(t *testing.T) {
+	t.Run("timeout", func(t *testing.T) {
+		c := NewController(Config{MaxConcurrent: 1, MaxQueued: 1, QueueTimeout: 10 * time.Millisecond})
+		release := mustAcquire(t, c, 1)
+		defer release()
+
+		_, err := c.Acquire(context.Background(), 1, 0)
+		if status.Code(err) != codes.ResourceExhausted {
+			t.Fatalf("Acquire = %v, want RESOURCE_EXHAUSTED", err)
+		}
+		if stats := c.Stats(); stats.Queued != 0 || stats.Running != 1 {
+			t.Errorf("stats %+v after a timeout", stats)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		c := NewController(Config{MaxConcurrent: 1, MaxQueued: 1})
+		release := mustAcquire(t, c, 1)
+		defer release()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		r := acquire(ctx, c, 1, 0)
+		waitQueued(t, c, 1)
+		cancel()
+		<-r.done
+		if status.Code(r.err) != codes.Canceled {
+			t.Fatalf("Acquire = %v, want CANCELED", r.err)
+		}
+		if stats := c.Stats(); stats.Queued != 0 || stats.Running != 1 {
+			t.Errorf("stats %+v after a cancellation", stats)
+		}
+	})
+
+	t.Run("a heavy waiter leaving unblocks lighter ones", func(t *testing.T) {
+		c := NewController(Config{MaxConcurrent: 4, MemoryBudget: 100, MaxQueued: 2})
+		release := mustAcquire(t, c, 50)
+		defer release()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		heavy := acquire(ctx, c, 80, 1)
+		waitQueued(t, c, 1)
+		light := acquire(context.Background(), c, 10, 0)
+		waitQueued(t, c, 2)
+		if light.admitted(t) {
+			t.Fatal("light request admitted ahead of the queue")
+		}
+
+		cancel()
+		<-heavy.done
+		if !light.admitted(t) {
+			t.Fatal("light request not admitted once the heavy one left")
+		}
+		light.release()
+	})
+}
+
+func TestRelease(t *testing.T) {
+	c := NewController(Config{MaxConcurrent: 2, MemoryBudget: 100})
+	release := mustAcquire(t, c, 30)
+	other := mustAcquire(t, c, 20)
+
+	release()
+	release()
+	if stats := c.Stats(); stats.Running != 1 || stats.InUse != 20 {
+		t.Errorf("stats %+v after releasing twice, want one request of 20", stats)
+	}
+	other()
+}
+
+func TestSetConfig(t *testing.T) {
+	c := NewController(Config{MaxConcurrent: 1, MaxQueued: 2})
+	release := mustAcquire(t, c, 1)
+	defer release()
+
+	first := acquire(context.Background(), c, 1, 0)
+	waitQueued(t, c, 1)
+	second := acquire(context.Background(), c, 1, 0)
+	waitQueued(t, c, 2)
+
+	c.SetConfig(Config{MaxConcurrent: 2, MaxQueued: 2})
+	if !first.admitted(t) {
+		t.Fatal("waiting request not admitted after raising the limit")
+	}
+	if second.admitted(t) {
+		t.Fatal("request admitted beyond the new limit")
+	}
+
+	// lowering the limit keeps the running requests
+	c.SetConfig(Config{MaxConcurrent: 0, MaxQueued: 2})
+	if stats := c.Stats(); stats.Running != 2 || stats.Capacity.MaxConcurrent != 1 {
+		t.Errorf("stats %+v after lowering the limit", stats)
+	}
+	first.release()
+	release()
+	if !second.admitted(t) {
+		t.Fatal("waiting request not admitted")
+	}
+	second.release()
+}
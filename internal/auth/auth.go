@@ -0,0 +1,173 @@
+// Package auth authenticates requests carrying a bearer token, either a
+// static API key or a JWT, and maps them to roles granting permissions.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Permission is something a role allows.
+type Permission string
+
+const (
+	// PermTransform allows running transformations.
+	PermTransform Permission = "transform"
+	// PermLocalPath allows reading files from the server file system.
+	PermLocalPath Permission = "local_path"
+	// PermRemoteDownload allows the server to download remote sources.
+	PermRemoteDownload Permission = "remote_download"
+	// PermRawSQL allows transformations given as SQL instead of a pipeline.
+	PermRawSQL Permission = "raw_sql"
+	// PermExport allows uploading exports to object storage.
+	PermExport Permission = "export"
+	// PermAdmin allows the administration RPCs.
+	PermAdmin Permission = "admin"
+)
+
+var (
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrInvalidToken    = errors.New("invalid token")
+)
+
+// Principal is an authenticated caller.
+type Principal struct {
+	Subject string
+	// Method is how the caller authenticated, "api_key" or "jwt".
+	Method string
+	Roles  []string
+}
+
+// Policy is the content of the auth file.
+type Policy struct {
+	// Roles maps every role to the permissions it grants.
+	Roles   map[string][]Permission `json:"roles"`
+	APIKeys []APIKey                `json:"api_keys"`
+}
+
+// APIKey is a static key, only its SHA-256 is stored.
+type APIKey struct {
+	Name   string   `json:"name"`
+	SHA256 string   `json:"sha256"`
+	Roles  []string `json:"roles"`
+}
+
+type Config struct {
+	// PolicyFile is the JSON file with the roles and the API keys.
+	PolicyFile string
+	// JWKSFile is the JSON Web Key Set JWTs are verified against, JWTs are
+	// rejected when not set.
+	JWKSFile string
+	Issuer   string
+	Audience string
+	// RolesClaim is the JWT claim holding the roles, "roles" by default.
+	RolesClaim string
+}
+
+type Authenticator struct {
+	policy  Policy
+	apiKeys map[[sha256.Size]byte]APIKey
+	jwt     *jwtVerifier
+}
+
+func NewAuthenticator(cfg Config) (*Authenticator, error) {
+	data, err := os.ReadFile(cfg.PolicyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", cfg.PolicyFile, err)
+	}
+
+	a := &Authenticator{policy: policy, apiKeys: map[[sha256.Size]byte]APIKey{}}
+	for _, key := range policy.APIKeys {
+		sum, err := hex.DecodeString(key.SHA256)
+		if err != nil || len(sum) != sha256.Size {
+			return nil, fmt.Errorf("api key %q: invalid sha256", key.Name)
+		}
+		if err := a.checkRoles(key.Roles); err != nil {
+			return nil, fmt.Errorf("api key %q: %w", key.Name, err)
+		}
+		a.apiKeys[[sha256.Size]byte(sum)] = key
+	}
+
+	if cfg.JWKSFile != "" {
+		rolesClaim := cfg.RolesClaim
+		if rolesClaim == "" {
+			rolesClaim = "roles"
+		}
+
+		a.jwt, err = newJWTVerifier(cfg.JWKSFile, cfg.Issuer, cfg.Audience, rolesClaim)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+// Authenticate returns the principal the bearer token belongs to.
+func (a *Authenticator) Authenticate(token string) (Principal, error) {
+	if token == "" {
+		return Principal{}, ErrUnauthenticated
+	}
+
+	// a JWT is made of three base64 parts separated by dots
+	if strings.Count(token, ".") == 2 {
+		if a.jwt == nil {
+			return Principal{}, fmt.Errorf("%w: JWTs are not accepted", ErrInvalidToken)
+		}
+		return a.jwt.verify(token)
+	}
+
+	sum := sha256.Sum256([]byte(token))
+	for hash, key := range a.apiKeys {
+		if subtle.ConstantTimeCompare(hash[:], sum[:]) == 1 {
+			return Principal{Subject: key.Name, Method: "api_key", Roles: key.Roles}, nil
+		}
+	}
+	return Principal{}, fmt.Errorf("%w: unknown API key", ErrInvalidToken)
+}
+
+// Allowed reports whether one of the roles of the principal grants the
+// permission.
+func (a *Authenticator) Allowed(p Principal, perm Permission) bool {
+	for _, role := range p.Roles {
+		for _, granted := range a.policy.Roles[role] {
+			if granted == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (a *Authenticator) checkRoles(roles []string) error {
+	for _, role := range roles {
+		if _, ok := a.policy.Roles[role]; !ok {
+			return fmt.Errorf("unknown role %q", role)
+		}
+	}
+	return nil
+}
+
+type principalKey struct{}
+
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal of an authenticated request.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writePolicy writes a policy with the API key "reader-key" of the role
+// reader, allowed to transform.
+func writePolicy(t *testing.T, policy string) string {
+	t.Helper()
+	if policy == "" {
+		sum := sha256.Sum256([]byte("reader-key"))
+		policy = `{
+			"roles": {"reader": ["transform"], "admin": ["admin", "transform"]},
+			"api_keys": [{"name": "ci", "sha256": "` + hex.EncodeToString(sum[:]) + `", "roles": ["reader"]}]
+		}`
+	}
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(policy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAuthenticate(t *testing.T) {
+	jwks, keys := writeJWKS(t)
+	a, err := NewAuthenticator(Config{PolicyFile: writePolicy(t, ""), JWKSFile: jwks})
+	if err != nil {
+		t.Fatalf("NewAuthenticator: %v", err)
+	}
+
+	p, err := a.Authenticate("reader-key")
+	if err != nil || p.Subject != "ci" || p.Method != "api_key" || len(p.Roles) != 1 || p.Roles[0] != "reader" {
+		t.Errorf("Authenticate(API key) = %+v, %v", p, err)
+	}
+	if !a.Allowed(p, PermTransform) || a.Allowed(p, PermAdmin) {
+		t.Errorf("reader allowed to transform %t and to administer %t", a.Allowed(p, PermTransform), a.Allowed(p, PermAdmin))
+	}
+
+	for _, token := range []string{"reader-key ", "Reader-key", "other-key"} {
+		if _, err := a.Authenticate(token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Authenticate(%q) = %v, want ErrInvalidToken", token, err)
+		}
+	}
+	if _, err := a.Authenticate(""); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Authenticate without token = %v, want ErrUnauthenticated", err)
+	}
+
+	// the roles claim defaults to roles
+	token := signJWT(t, map[string]any{"alg": "RS256", "kid": "rsa"},
+		map[string]any{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix(), "roles": "admin"},
+		rsaSigner(t, keys.rsa, crypto.SHA256))
+	p, err = a.Authenticate(token)
+	if err != nil || p.Subject != "alice" || !a.Allowed(p, PermAdmin) {
+		t.Errorf("Authenticate(JWT) = %+v, %v", p, err)
+	}
+
+	// JWTs are rejected without a key set
+	a, err = NewAuthenticator(Config{PolicyFile: writePolicy(t, "")})
+	if err != nil {
+		t.Fatalf("NewAuthenticator: %v", err)
+	}
+	if _, err := a.Authenticate(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Authenticate(JWT) without key set = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestNewAuthenticatorErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		want   string
+	}{
+		{"invalid JSON", `{"roles": `, "parsing"},
+		{"invalid hash", `{"api_keys": [{"name": "ci", "sha256": "abc"}]}`, "invalid sha256"},
+		{"unknown role", `{"roles": {}, "api_keys": [{"name": "ci", "sha256": "` + strings.Repeat("ab", sha256.Size) + `", "roles": ["admin"]}]}`, "unknown role"},
+	}
+	for _, tt := range tests {
+		_, err := NewAuthenticator(Config{PolicyFile: writePolicy(t, tt.policy)})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: NewAuthenticator = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
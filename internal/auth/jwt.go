@@ -0,0 +1,263 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// clockSkew is the leeway given when checking the time claims.
+const clockSkew = time.Minute
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// signingKey is a key of the set, restricted to the algorithm of its JWK
+// when the JWK declares one.
+type signingKey struct {
+	pub crypto.PublicKey
+	alg string
+}
+
+type jwtVerifier struct {
+	keys       map[string]signingKey
+	issuer     string
+	audience   string
+	rolesClaim string
+}
+
+func newJWTVerifier(jwksFile, issuer, audience, rolesClaim string) (*jwtVerifier, error) {
+	data, err := os.ReadFile(jwksFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", jwksFile, err)
+	}
+
+	v := &jwtVerifier{keys: map[string]signingKey{}, issuer: issuer, audience: audience, rolesClaim: rolesClaim}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		pub, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		v.keys[k.Kid] = signingKey{pub: pub, alg: k.Alg}
+	}
+
+	if len(v.keys) == 0 {
+		return nil, fmt.Errorf("no signing key in %s", jwksFile)
+	}
+	return v, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+var algorithms = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+func (v *jwtVerifier) verify(token string) (Principal, error) {
+	parts := strings.Split(token, ".")
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Principal{}, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+
+	hash, ok := algorithms[header.Alg]
+	if !ok {
+		return Principal{}, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, header.Alg)
+	}
+
+	key, ok := v.keys[header.Kid]
+	if !ok {
+		return Principal{}, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, header.Kid)
+	}
+	if key.alg != "" && key.alg != header.Alg {
+		return Principal{}, fmt.Errorf("%w: algorithm %q, key %q is for %q", ErrInvalidToken, header.Alg, header.Kid, key.alg)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: signature: %v", ErrInvalidToken, err)
+	}
+
+	h := hash.New()
+	h.Write([]byte(parts[0] + "." + parts[1]))
+	digest := h.Sum(nil)
+
+	switch pub := key.pub.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(header.Alg, "RS") || rsa.VerifyPKCS1v15(pub, hash, digest, sig) != nil {
+			return Principal{}, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if !strings.HasPrefix(header.Alg, "ES") || len(sig) != 2*size {
+			return Principal{}, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+		r, s := new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return Principal{}, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Principal{}, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+
+	if err := v.checkClaims(claims); err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	sub, _ := claims["sub"].(string)
+	return Principal{Subject: sub, Method: "jwt", Roles: stringList(claims[v.rolesClaim])}, nil
+}
+
+func (v *jwtVerifier) checkClaims(claims map[string]any) error {
+	now := time.Now()
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.New("missing exp claim")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(clockSkew)) {
+		return errors.New("token expired")
+	}
+
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(clockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return errors.New("token not valid yet")
+	}
+
+	if v.issuer != "" && claims["iss"] != v.issuer {
+		return errors.New("unexpected issuer")
+	}
+
+	if v.audience != "" {
+		found := false
+		for _, aud := range stringList(claims["aud"]) {
+			if aud == v.audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.New("unexpected audience")
+		}
+	}
+
+	return nil
+}
+
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// stringList accepts both a single string and a list of strings, as used by
+// the aud claim. A single string is split on spaces, like the scope claim.
+func stringList(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testKeys are the private keys of the JWKS written by writeJWKS.
+type testKeys struct {
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+// writeJWKS writes a set with the RSA key as "rsa", restricted to RS256, and
+// as "rsa-any", and the EC key as "ec".
+func writeJWKS(t *testing.T) (string, testKeys) {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	n, e := b64(rsaKey.N.Bytes()), b64([]byte{1, 0, 1})
+	x, y := b64(ecKey.X.FillBytes(make([]byte, 32))), b64(ecKey.Y.FillBytes(make([]byte, 32)))
+	set := map[string]any{"keys": []jwk{
+		{Kty: "RSA", Kid: "rsa", Alg: "RS256", Use: "sig", N: n, E: e},
+		{Kty: "RSA", Kid: "rsa-any", N: n, E: e},
+		{Kty: "EC", Kid: "ec", Crv: "P-256", X: x, Y: y},
+		// encryption keys are skipped
+		{Kty: "oct", Kid: "enc", Use: "enc"},
+	}}
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path, testKeys{rsa: rsaKey, ec: ecKey}
+}
+
+// signJWT returns a token with the header and the claims, signed by sign when
+// set.
+func signJWT(t *testing.T, header, claims map[string]any, sign func(signed []byte) []byte) string {
+	t.Helper()
+	segment := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	signed := segment(header) + "." + segment(claims)
+	var sig []byte
+	if sign != nil {
+		sig = sign([]byte(signed))
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func rsaSigner(t *testing.T, key *rsa.PrivateKey, hash crypto.Hash) func([]byte) []byte {
+	return func(signed []byte) []byte {
+		h := hash.New()
+		h.Write(signed)
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, hash, h.Sum(nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+}
+
+// ecSigner signs with ES256, r and s concatenated as JWS requires.
+func ecSigner(t *testing.T, key *ecdsa.PrivateKey) func([]byte) []byte {
+	return func(signed []byte) []byte {
+		digest := sha256.Sum256(signed)
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+}
+
+func TestVerifyJWT(t *testing.T) {
+	jwks, keys := writeJWKS(t)
+	v, err := newJWTVerifier(jwks, "https://issuer.example.com", "duckdb-server", "roles")
+	if err != nil {
+		t.Fatalf("newJWTVerifier: %v", err)
+	}
+
+	now := time.Now()
+	claims := func(change func(map[string]any)) map[string]any {
+		c := map[string]any{
+			"sub":   "alice",
+			"iss":   "https://issuer.example.com",
+			"aud":   "duckdb-server",
+			"exp":   now.Add(time.Hour).Unix(),
+			"roles": []string{"reader", "writer"},
+		}
+		if change != nil {
+			change(c)
+		}
+		return c
+	}
+	header := func(alg, kid string) map[string]any {
+		return map[string]any{"alg": alg, "kid": kid, "typ": "JWT"}
+	}
+	rs256 := rsaSigner(t, keys.rsa, crypto.SHA256)
+	es256 := ecSigner(t, keys.ec)
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"RS256", signJWT(t, header("RS256", "rsa"), claims(nil), rs256), true},
+		{"ES256", signJWT(t, header("ES256", "ec"), claims(nil), es256), true},
+		{"RS384 with a key without algorithm", signJWT(t, header("RS384", "rsa-any"), claims(nil), rsaSigner(t, keys.rsa, crypto.SHA384)), true},
+		{"RS384 with an RS256 key", signJWT(t, header("RS384", "rsa"), claims(nil), rsaSigner(t, keys.rsa, crypto.SHA384)), false},
+
+		{"bad signature", func() string {
+			token := signJWT(t, header("RS256", "rsa"), claims(nil), rs256)
+			forged := signJWT(t, header("RS256", "rsa"), claims(func(c map[string]any) { c["roles"] = "admin" }), nil)
+			return forged + token[strings.LastIndex(token, ".")+1:]
+		}(), false},
+		{"alg none", signJWT(t, header("none", "rsa"), claims(nil), nil), false},
+		{"HS256 keyed with the public key", signJWT(t, header("HS256", "rsa-any"), claims(nil), func(signed []byte) []byte {
+			mac := hmac.New(sha256.New, keys.rsa.N.Bytes())
+			mac.Write(signed)
+			return mac.Sum(nil)
+		}), false},
+		{"ES token for an RSA key", signJWT(t, header("ES256", "rsa-any"), claims(nil), es256), false},
+		{"RS token for an EC key", signJWT(t, header("RS256", "ec"), claims(nil), rs256), false},
+		{"truncated ES signature", signJWT(t, header("ES256", "ec"), claims(nil), func(signed []byte) []byte {
+			return es256(signed)[:63]
+		}), false},
+		{"unknown key", signJWT(t, header("RS256", "other"), claims(nil), rs256), false},
+		{"encryption key", signJWT(t, header("RS256", "enc"), claims(nil), rs256), false},
+
+		{"expired", signJWT(t, header("RS256", "rsa"), claims(func(c map[string]any) { c["exp"] = now.Add(-2 * clockSkew).Unix() }), rs256), false},
+		{"expired within the clock skew", signJWT(t, header("RS256", "rsa"), claims(func(c map[string]any) { c["exp"] = now.Add(-clockSkew / 2).Unix() }), rs256), true},
+		{"no expiry", signJWT(t, header("RS256", "rsa"), claims(func(c map[string]any) { delete(c, "exp") }), rs256), false},
+		{"not valid yet", signJWT(t, header("RS256", "rsa"), claims(func(c map[string]any) { c["nbf"] = now.Add(2 * clockSkew).Unix() }), rs256), false},
+		{"not valid yet within the clock skew", signJWT(t, header("RS256", "rsa"), claims(func(c map[string]any) { c["nbf"] = now.Add(clockSkew / 2).Unix() }), rs256), true},
+		{"wrong issuer", signJWT(t, header("RS256", "rsa"), claims(func(c map[string]any) { c["iss"] = "https://other.example.com" }), rs256), false},
+		{"no issuer", signJWT(t, header("RS256", "rsa"), claims(func(c map[string]any) { delete(c, "iss") }), rs256), false},
+		{"wrong audience", signJWT(t, header("RS256", "rsa"), claims(func(c map[string]any) { c["aud"] = "other" }), rs256), false},
+		{"audience list", signJWT(t, header("RS256", "rsa"), claims(func(c map[string]any) { c["aud"] = []string{"other", "duckdb-server"} }), rs256), true},
+		{"malformed header", "!" + signJWT(t, header("RS256", "rsa"), claims(nil), rs256), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := v.verify(tt.token)
+			if !tt.valid {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("verify = %+v, %v, want ErrInvalidToken", p, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if p.Subject != "alice" || p.Method != "jwt" || len(p.Roles) != 2 || p.Roles[0] != "reader" {
+				t.Errorf("principal %+v", p)
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package fetch
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	indexFile = "index.json"
+	// partialSuffix marks the downloads in progress.
+	partialSuffix = ".partial"
+)
+
+// Cache keeps the downloads in a directory, so that a URL fetched again is
+// only downloaded when it changed. Files are named after the SHA-256 of their
+// content and shared by the URLs serving the same content. A cached copy is
+// revalidated with the ETag and Last-Modified of its response, and used
+// without a request when the caller asks for a checksum it matches, as long
+// as it was downloaded from an allowed URL. The least recently used files are
+// evicted once the cache exceeds its size.
+type Cache struct {
+	fetcher  *Fetcher
+	dir      string
+	maxBytes int64
+
+	mu    sync.Mutex
+	urls  map[string]*cachedURL
+	blobs map[string]*blob
+	size  int64
+	stats CacheStats
+}
+
+// cachedURL is the last response received for a URL.
+type cachedURL struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	SHA256       string `json:"sha256"`
+}
+
+// blob is a cached file.
+type blob struct {
+	Size     int64     `json:"size"`
+	LastUsed time.Time `json:"last_used"`
+	// refs counts the callers using the file, it can't be evicted until they
+	// release it.
+	refs int
+}
+
+type index struct {
+	URLs  map[string]*cachedURL `json:"urls"`
+	Blobs map[string]*blob      `json:"blobs"`
+}
+
+type CacheStats struct {
+	Files    int
+	URLs     int
+	Bytes    int64
+	MaxBytes int64
+	// Hits are requests served from the cache without a request, their
+	// checksum matching a cached file.
+	Hits uint64
+	// Revalidations are requests served from the cache after a 304 answer.
+	Revalidations uint64
+	// Misses are requests that downloaded the URL.
+	Misses    uint64
+	Evictions uint64
+}
+
+// NewCache opens the cache in dir, keeping the files listed in its index and
+// removing the others.
+func NewCache(f *Fetcher, dir string, maxBytes int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	c := &Cache{
+		fetcher:  f,
+		dir:      dir,
+		maxBytes: maxBytes,
+		urls:     map[string]*cachedURL{},
+		blobs:    map[string]*blob{},
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, indexFile))
+	switch {
+	case err == nil:
+		var idx index
+		if err := json.Unmarshal(data, &idx); err != nil {
+			slog.Warn("ignoring corrupted download cache index", "err", err)
+			break
+		}
+		for sum, b := range idx.Blobs {
+			if info, err := os.Stat(c.blobPath(sum)); err == nil && info.Size() == b.Size {
+				c.blobs[sum] = b
+				c.size += b.Size
+			}
+		}
+		for u, cu := range idx.URLs {
+			if c.blobs[cu.SHA256] != nil {
+				c.urls[u] = cu
+			}
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.Name() != indexFile && c.blobs[e.Name()] == nil {
+			os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evict()
+	c.save()
+	return c, nil
+}
+
+func (c *Cache) blobPath(sum string) string {
+	return filepath.Join(c.dir, sum)
+}
+
+// errEvicted is returned when the copy of a URL revalidated with a 304 was
+// evicted meanwhile.
+var errEvicted = errors.New("cached copy evicted during revalidation")
+
+// Get returns the path of a cached copy of the URL, downloading it when it is
+// missing or changed. When sha256Hex is set, the content must have this
+// SHA-256. The file can't be evicted until release is called.
+func (c *Cache) Get(ctx context.Context, rawURL, sha256Hex string) (string, func(), error) {
+	want, err := decodeSum(sha256Hex)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// the URL must be allowed even when it isn't requested
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := c.fetcher.CheckURL(u); err != nil {
+		return "", nil, err
+	}
+
+	c.mu.Lock()
+	if want != nil {
+		if sum := hex.EncodeToString(want); c.blobs[sum] != nil && c.downloadedFromAllowedURL(sum) {
+			c.stats.Hits++
+			path, release := c.acquire(sum)
+			c.mu.Unlock()
+			return path, release, nil
+		}
+	}
+
+	// a pinned checksum not in the cache can't be the cached copy
+	var cond validators
+	if cu := c.urls[rawURL]; cu != nil && want == nil {
+		cond = validators{etag: cu.ETag, lastModified: cu.LastModified}
+	}
+	c.mu.Unlock()
+
+	path, release, err := c.download(ctx, rawURL, cond, want)
+	if errors.Is(err, errEvicted) {
+		path, release, err = c.download(ctx, rawURL, validators{}, want)
+	}
+	return path, release, err
+}
+
+// downloadedFromAllowedURL reports whether the file was downloaded from a URL
+// the fetcher still allows, so that a checksum doesn't serve what the policy
+// denies. c.mu must be held.
+func (c *Cache) downloadedFromAllowedURL(sum string) bool {
+	for rawURL, cu := range c.urls {
+		if cu.SHA256 != sum {
+			continue
+		}
+		if u, err := url.Parse(rawURL); err == nil && c.fetcher.CheckURL(u) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// download fetches the URL to the cache, revalidating the cached copy when
+// cond is set.
+func (c *Cache) download(ctx context.Context, rawURL string, cond validators, want []byte) (path string, release func(), err error) {
+	out, err := os.CreateTemp(c.dir, "*"+partialSuffix)
+	if err != nil {
+		return "", nil, err
+	}
+	tmpPath := out.Name()
+	defer os.Remove(tmpPath)
+
+	current, sum, err := c.fetcher.fetch(ctx, rawURL, cond, want, out, func() error {
+		if _, err := out.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		return out.Truncate(0)
+	})
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if errors.Is(err, errNotModified) {
+		cu := c.urls[rawURL]
+		if cu == nil || c.blobs[cu.SHA256] == nil {
+			return "", nil, errEvicted
+		}
+		c.stats.Revalidations++
+		path, release = c.acquire(cu.SHA256)
+		return path, release, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	c.stats.Misses++
+	key := hex.EncodeToString(sum)
+	if c.blobs[key] == nil {
+		info, err := os.Stat(tmpPath)
+		if err != nil {
+			return "", nil, err
+		}
+		if err := os.Rename(tmpPath, c.blobPath(key)); err != nil {
+			return "", nil, err
+		}
+		c.blobs[key] = &blob{Size: info.Size()}
+		c.size += info.Size()
+	}
+	c.urls[rawURL] = &cachedURL{ETag: current.etag, LastModified: current.lastModified, SHA256: key}
+
+	path, release = c.acquire(key)
+	c.evict()
+	c.save()
+	return path, release, nil
+}
+
+// acquire marks the file as used until release is called. c.mu must be held.
+func (c *Cache) acquire(sum string) (string, func()) {
+	b := c.blobs[sum]
+	b.refs++
+	b.LastUsed = time.Now()
+
+	var once sync.Once
+	return c.blobPath(sum), func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			b.refs--
+			c.evict()
+			c.save()
+		})
+	}
+}
+
+// evict removes the least recently used files not in use until the cache
+// fits in its size. c.mu must be held.
+func (c *Cache) evict() {
+	if c.maxBytes <= 0 || c.size <= c.maxBytes {
+		return
+	}
+
+	sums := make([]string, 0, len(c.blobs))
+	for sum, b := range c.blobs {
+		if b.refs == 0 {
+			sums = append(sums, sum)
+		}
+	}
+	sort.Slice(sums, func(i, j int) bool {
+		return c.blobs[sums[i]].LastUsed.Before(c.blobs[sums[j]].LastUsed)
+	})
+
+	for _, sum := range sums {
+		if c.size <= c.maxBytes {
+			break
+		}
+		if err := os.Remove(c.blobPath(sum)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			slog.Error("error evicting from the download cache", "sha256", sum, "err", err)
+			continue
+		}
+		c.size -= c.blobs[sum].Size
+		delete(c.blobs, sum)
+		c.stats.Evictions++
+	}
+
+	for u, cu := range c.urls {
+		if c.blobs[cu.SHA256] == nil {
+			delete(c.urls, u)
+		}
+	}
+}
+
+// save writes the index, replacing it atomically. c.mu must be held.
+func (c *Cache) save() {
+	data, err := json.Marshal(index{URLs: c.urls, Blobs: c.blobs})
+	if err == nil {
+		tmpPath := filepath.Join(c.dir, indexFile+partialSuffix)
+		if err = os.WriteFile(tmpPath, data, 0o600); err == nil {
+			err = os.Rename(tmpPath, filepath.Join(c.dir, indexFile))
+		}
+	}
+	if err != nil {
+		slog.Error("error saving the download cache index", "err", err)
+	}
+}
+
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Files = len(c.blobs)
+	stats.URLs = len(c.urls)
+	stats.Bytes = c.size
+	stats.MaxBytes = c.maxBytes
+	return stats
+}
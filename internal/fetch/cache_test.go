@@ -0,0 +1,280 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const modified = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+// serveVersioned serves the content with an ETag on /etag and a Last-Modified
+// on /modified, answering matching conditional requests with a 304. Other
+// paths serve ten times their last letter, without validators.
+func serveVersioned(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/etag":
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	case "/modified":
+		w.Header().Set("Last-Modified", modified)
+		if r.Header.Get("If-Modified-Since") == modified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	default:
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(strings.Repeat(r.URL.Path[len(r.URL.Path)-1:], 10)))
+		return
+	}
+	serveContent(w, r)
+}
+
+func newCache(t *testing.T, cfg Config, dir string, maxBytes int64) *Cache {
+	t.Helper()
+
+	c, err := NewCache(newFetcher(t, cfg), dir, maxBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// get gets the URL from the cache, returning the content of the cached copy
+// and its path.
+func get(t *testing.T, c *Cache, rawURL, sha256Hex string) (string, string, func(), error) {
+	t.Helper()
+
+	path, release, err := c.Get(context.Background(), rawURL, sha256Hex)
+	if err != nil {
+		return "", "", nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data), path, release, nil
+}
+
+// partials returns the downloads in progress left in the directory.
+func partials(t *testing.T, dir string) []string {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+partialSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestCacheRevalidation(t *testing.T) {
+	srv, requests := serve(t, serveVersioned)
+
+	for _, path := range []string{"/etag", "/modified"} {
+		t.Run(path, func(t *testing.T) {
+			c := newCache(t, testConfig(), t.TempDir(), 0)
+			before := requests.Load()
+
+			got, first, release, err := get(t, c, srv.URL+path, "")
+			if err != nil || got != content {
+				t.Fatalf("Get = %q, %v", got, err)
+			}
+			release()
+
+			got, second, release, err := get(t, c, srv.URL+path, "")
+			if err != nil || got != content || second != first {
+				t.Fatalf("Get after a 304 = %q at %s, %v, want the copy at %s", got, second, err, first)
+			}
+			release()
+
+			if n := requests.Load() - before; n != 2 {
+				t.Errorf("%d requests, want 2", n)
+			}
+			if stats := c.Stats(); stats.Misses != 1 || stats.Revalidations != 1 || stats.Files != 1 || stats.URLs != 1 {
+				t.Errorf("stats %+v, want a miss and a revalidation", stats)
+			}
+		})
+	}
+}
+
+func TestCacheChecksum(t *testing.T) {
+	srv, requests := serve(t, serveVersioned)
+	dir := t.TempDir()
+	c := newCache(t, testConfig(), dir, 0)
+
+	// a mismatch caches nothing
+	if _, _, _, err := get(t, c, srv.URL+"/etag", sum("other")); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("Get = %v, want ErrChecksumMismatch", err)
+	}
+	if stats := c.Stats(); stats.Files != 0 || stats.URLs != 0 {
+		t.Errorf("stats %+v after a mismatch, want an empty cache", stats)
+	}
+	if p := partials(t, dir); len(p) != 0 {
+		t.Errorf("partial downloads left: %q", p)
+	}
+
+	_, _, release, err := get(t, c, srv.URL+"/etag", sum(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	// the pinned checksum is served without a request, for any allowed URL
+	before := requests.Load()
+	for _, rawURL := range []string{srv.URL + "/etag", srv.URL + "/modified"} {
+		got, _, release, err := get(t, c, rawURL, sum(content))
+		if err != nil || got != content {
+			t.Fatalf("Get(%s) = %q, %v", rawURL, got, err)
+		}
+		release()
+	}
+	if n := requests.Load() - before; n != 0 {
+		t.Errorf("%d requests for a pinned checksum in the cache", n)
+	}
+	if stats := c.Stats(); stats.Hits != 2 {
+		t.Errorf("%d hits, want 2", stats.Hits)
+	}
+
+	// nor the URL, nor the one the copy was downloaded from, can be denied
+	u, _ := url.Parse(srv.URL)
+	for _, rawURL := range []string{"file:///etc/passwd", "http://user:pass@" + u.Host + "/etag"} {
+		if _, _, _, err := get(t, c, rawURL, sum(content)); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Get(%s) = %v, want ErrForbidden", rawURL, err)
+		}
+	}
+	cfg := testConfig()
+	cfg.DeniedHosts = []string{"127.0.0.1"}
+	c = newCache(t, cfg, dir, 0)
+	before = requests.Load()
+	got, _, release, err := get(t, c, "http://localhost:"+u.Port()+"/etag", sum(content))
+	if err != nil || got != content {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+	release()
+	if stats := c.Stats(); stats.Hits != 0 || requests.Load()-before != 1 {
+		t.Errorf("a copy downloaded from a denied host was served, stats %+v", stats)
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	srv, _ := serve(t, serveVersioned)
+	dir := t.TempDir()
+	c := newCache(t, testConfig(), dir, 25)
+
+	// a is in use, b is the least recently used of the others
+	a, pathA, releaseA, err := get(t, c, srv.URL+"/a", "")
+	if err != nil || a != strings.Repeat("a", 10) {
+		t.Fatalf("Get = %q, %v", a, err)
+	}
+	var paths []string
+	for _, name := range []string{"b", "c"} {
+		time.Sleep(time.Millisecond)
+		_, path, release, err := get(t, c, srv.URL+"/"+name, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+		paths = append(paths, path)
+	}
+
+	for path, kept := range map[string]bool{pathA: true, paths[0]: false, paths[1]: true} {
+		if _, err := os.Stat(path); (err == nil) != kept {
+			t.Errorf("%s kept %t, want %t", filepath.Base(path), err == nil, kept)
+		}
+	}
+	if stats := c.Stats(); stats.Evictions != 1 || stats.Bytes != 20 || stats.URLs != 2 {
+		t.Errorf("stats %+v, want b evicted", stats)
+	}
+
+	releaseA()
+	releaseA()
+	if stats := c.Stats(); stats.Evictions != 1 {
+		t.Errorf("%d evictions once a is released, want 1", stats.Evictions)
+	}
+}
+
+func TestCacheEvictedDuringRevalidation(t *testing.T) {
+	var c *Cache
+	srv, requests := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			// evicted while the request is in flight
+			c.mu.Lock()
+			c.maxBytes = 1
+			c.evict()
+			c.maxBytes = 0
+			c.mu.Unlock()
+		}
+		serveVersioned(w, r)
+	})
+	c = newCache(t, testConfig(), t.TempDir(), 0)
+
+	_, _, release, err := get(t, c, srv.URL+"/etag", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	got, _, release, err := get(t, c, srv.URL+"/etag", "")
+	if err != nil || got != content {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+	release()
+	if n := requests.Load(); n != 3 {
+		t.Errorf("%d requests, want the 304 followed by a download", n)
+	}
+	if stats := c.Stats(); stats.Misses != 2 || stats.Evictions != 1 || stats.Files != 1 {
+		t.Errorf("stats %+v", stats)
+	}
+}
+
+func TestCacheRestart(t *testing.T) {
+	srv, requests := serve(t, serveVersioned)
+	dir := t.TempDir()
+	c := newCache(t, testConfig(), dir, 0)
+
+	_, path, release, err := get(t, c, srv.URL+"/etag", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	// left by a crash
+	for _, name := range []string{"x" + partialSuffix, indexFile + partialSuffix, "stray"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c = newCache(t, testConfig(), dir, 0)
+	if stats := c.Stats(); stats.Files != 1 || stats.URLs != 1 || stats.Bytes != int64(len(content)) {
+		t.Errorf("stats %+v after a restart, want the cached copy", stats)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != indexFile && e.Name() != filepath.Base(path) {
+			t.Errorf("%s kept after a restart", e.Name())
+		}
+	}
+
+	before := requests.Load()
+	got, again, release, err := get(t, c, srv.URL+"/etag", "")
+	if err != nil || got != content || again != path {
+		t.Fatalf("Get = %q at %s, %v", got, again, err)
+	}
+	release()
+	if stats := c.Stats(); stats.Revalidations != 1 || requests.Load()-before != 1 {
+		t.Errorf("stats %+v, want the copy revalidated", stats)
+	}
+}
@@ -0,0 +1,474 @@
+// Package fetch downloads remote sources. It only connects to allowed hosts
+// and addresses, checked once the host name is resolved and on every
+// redirect, and bounds the time and the size of the downloads.
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"duckdb-server/internal/logging"
+	"duckdb-server/internal/progress"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrForbidden        = errors.New("destination not allowed")
+	ErrTooLarge         = errors.New("download exceeds the maximum size")
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	ErrContentType      = errors.New("unexpected content type")
+
+	// errNotModified is a 304 answer to a conditional request.
+	errNotModified = errors.New("not modified")
+)
+
+// validators identify a version of a resource for conditional requests.
+type validators struct {
+	etag         string
+	lastModified string
+}
+
+// StatusError is a response that is not a 200 OK.
+type StatusError struct {
+	StatusCode int
+	// RetryAfter is the delay asked by the server, if any.
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+func (e *StatusError) temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+type Config struct {
+	// Timeout bounds every attempt, from connecting to reading the last byte.
+	Timeout        time.Duration
+	ConnectTimeout time.Duration
+	// Retries is the number of attempts after the first one, made on network
+	// errors, 429 and 5xx responses.
+	Retries int
+	// Backoff is the delay before the first retry, doubled on every retry.
+	Backoff time.Duration
+	// MaxBytes is the largest download accepted, no limit when 0.
+	MaxBytes     int64
+	MaxRedirects int
+	// AllowHTTP allows plain http URLs, only https is allowed otherwise.
+	AllowHTTP bool
+	// AllowedHosts limits the host names to download from when not empty.
+	// "*.example.com" matches the subdomains of example.com.
+	AllowedHosts []string
+	DeniedHosts  []string
+	// AllowedCIDRs are the addresses that can be connected to. When empty,
+	// every public address is allowed, but not the loopback, private,
+	// link-local and other special purpose ones.
+	AllowedCIDRs []string
+	// DeniedCIDRs are never connected to, even when allowed.
+	DeniedCIDRs []string
+}
+
+type Fetcher struct {
+	cfg          Config
+	client       *http.Client
+	allowedCIDRs []netip.Prefix
+	deniedCIDRs  []netip.Prefix
+}
+
+// specialPurpose are the ranges that are not reachable on the internet, they
+// are denied unless AllowedCIDRs is set.
+var specialPurpose = mustPrefixes(
+	"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16",
+	"172.16.0.0/12", "192.0.0.0/24", "192.0.2.0/24", "192.168.0.0/16", "198.18.0.0/15",
+	"198.51.100.0/24", "203.0.113.0/24", "224.0.0.0/4", "240.0.0.0/4",
+	"::/128", "::1/128", "64:ff9b::/96", "100::/64", "2001:db8::/32", "fc00::/7", "fe80::/10", "ff00::/8",
+)
+
+func mustPrefixes(cidrs ...string) []netip.Prefix {
+	prefixes, err := parsePrefixes(cidrs)
+	if err != nil {
+		panic(err)
+	}
+	return prefixes
+}
+
+func parsePrefixes(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func New(cfg Config) (*Fetcher, error) {
+	allowed, err := parsePrefixes(cfg.AllowedCIDRs)
+	if err != nil {
+		return nil, fmt.Errorf("allowed CIDRs: %w", err)
+	}
+	denied, err := parsePrefixes(cfg.DeniedCIDRs)
+	if err != nil {
+		return nil, fmt.Errorf("denied CIDRs: %w", err)
+	}
+
+	if cfg.MaxRedirects <= 0 {
+		cfg.MaxRedirects = 5
+	}
+
+	f := &Fetcher{cfg: cfg, allowedCIDRs: allowed, deniedCIDRs: denied}
+
+	dialer := &net.Dialer{
+		Timeout: cfg.ConnectTimeout,
+		// the address is resolved by then, so that a host name can't point
+		// to a denied address when connecting after being checked
+		Control: func(network, address string, _ syscall.RawConn) error {
+			return f.checkAddress(address)
+		},
+	}
+
+	f.client = &http.Client{
+		Transport: &http.Transport{
+			// a proxy would be the address checked, not the destination
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   cfg.ConnectTimeout,
+			ResponseHeaderTimeout: cfg.Timeout,
+			MaxIdleConns:          16,
+			IdleConnTimeout:       90 * time.Second,
+			ForceAttemptHTTP2:     true,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > cfg.MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", cfg.MaxRedirects)
+			}
+			return f.CheckURL(req.URL)
+		},
+	}
+	return f, nil
+}
+
+// CheckURL checks the scheme and the host name of a URL. The addresses are
+// checked when connecting.
+func (f *Fetcher) CheckURL(u *url.URL) error {
+	switch {
+	case u.Scheme == "https":
+	case u.Scheme == "http" && f.cfg.AllowHTTP:
+	default:
+		return fmt.Errorf("%w: scheme %q", ErrForbidden, u.Scheme)
+	}
+
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials in URL", ErrForbidden)
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrForbidden)
+	}
+	if matchHost(f.cfg.DeniedHosts, host) {
+		return fmt.Errorf("%w: host %s", ErrForbidden, host)
+	}
+	if len(f.cfg.AllowedHosts) > 0 && !matchHost(f.cfg.AllowedHosts, host) {
+		return fmt.Errorf("%w: host %s", ErrForbidden, host)
+	}
+	return nil
+}
+
+func matchHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Fetcher) checkAddress(address string) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrForbidden, err)
+	}
+	addr := addrPort.Addr().Unmap()
+
+	if containsAddr(f.deniedCIDRs, addr) {
+		return fmt.Errorf("%w: address %s", ErrForbidden, addr)
+	}
+	if len(f.allowedCIDRs) > 0 {
+		if !containsAddr(f.allowedCIDRs, addr) {
+			return fmt.Errorf("%w: address %s", ErrForbidden, addr)
+		}
+		return nil
+	}
+	if containsAddr(specialPurpose, addr) {
+		return fmt.Errorf("%w: address %s", ErrForbidden, addr)
+	}
+	return nil
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Head returns the size of the resource, -1 when unknown. It makes a single
+// attempt.
+func (f *Fetcher) Head(ctx context.Context, rawURL string) (int64, error) {
+	req, err := f.newRequest(ctx, http.MethodHead, rawURL)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return 0, &StatusError{StatusCode: res.StatusCode}
+	}
+	return res.ContentLength, nil
+}
+
+func (f *Fetcher) newRequest(ctx context.Context, method, rawURL string) (*http.Request, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.CheckURL(u); err != nil {
+		return nil, err
+	}
+	return http.NewRequestWithContext(ctx, method, u.String(), nil)
+}
+
+// DownloadToFile downloads the URL to a new file at path, retrying on
+// temporary failures. When sha256Hex is set, the content must have this
+// SHA-256. The file is removed when the download fails.
+func (f *Fetcher) DownloadToFile(ctx context.Context, rawURL, path, sha256Hex string) (err error) {
+	want, err := decodeSum(sha256Hex)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	_, _, err = f.fetch(ctx, rawURL, validators{}, want, out, func() error {
+		if _, err := out.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		return out.Truncate(0)
+	})
+	return err
+}
+
+// Stream writes the content of the URL to w as it arrives. As w can't be
+// rewound, a failed attempt is only retried when nothing was written yet, and
+// a checksum mismatch is only known once everything was written.
+func (f *Fetcher) Stream(ctx context.Context, rawURL string, w io.Writer, sha256Hex string) error {
+	want, err := decodeSum(sha256Hex)
+	if err != nil {
+		return err
+	}
+
+	out := &countingWriter{w: w}
+	_, _, err = f.fetch(ctx, rawURL, validators{}, want, out, func() error {
+		if out.n > 0 {
+			return fmt.Errorf("%d bytes already written", out.n)
+		}
+		return nil
+	})
+	return err
+}
+
+func decodeSum(sha256Hex string) ([]byte, error) {
+	if sha256Hex == "" {
+		return nil, nil
+	}
+	want, err := hex.DecodeString(sha256Hex)
+	if err != nil || len(want) != sha256.Size {
+		return nil, fmt.Errorf("invalid sha256 %q", sha256Hex)
+	}
+	return want, nil
+}
+
+// fetch downloads the URL to out, retrying failed attempts once rewind
+// succeeds. When cond is set, the request is conditional and errNotModified
+// is returned if the version it identifies is still current. The validators
+// and the SHA-256 of the content are returned.
+func (f *Fetcher) fetch(ctx context.Context, rawURL string, cond validators, want []byte, out io.Writer, rewind func() error) (validators, []byte, error) {
+	backoff := f.cfg.Backoff
+	for attempt := 0; ; attempt++ {
+		sum := sha256.New()
+		current, err := f.download(ctx, rawURL, cond, io.MultiWriter(out, sum))
+		if err == nil {
+			if want != nil && !bytes.Equal(sum.Sum(nil), want) {
+				return validators{}, nil, fmt.Errorf("%w: got sha256 %x", ErrChecksumMismatch, sum.Sum(nil))
+			}
+			return current, sum.Sum(nil), nil
+		}
+
+		delay, retry := f.retryDelay(err, backoff)
+		if !retry || attempt >= f.cfg.Retries {
+			return validators{}, nil, err
+		}
+		if rewindErr := rewind(); rewindErr != nil {
+			slog.WarnContext(ctx, "download failed, not retrying", logging.Source(rawURL), "rewind_err", rewindErr, "err", err)
+			return validators{}, nil, err
+		}
+
+		slog.WarnContext(ctx, "download failed, retrying", logging.Source(rawURL), "delay", delay, "err", err)
+		select {
+		case <-ctx.Done():
+			return validators{}, nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		backoff *= 2
+	}
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// maxRetryAfter caps the delay a server can ask for before a retry.
+const maxRetryAfter = time.Minute
+
+// retryDelay tells whether a failed attempt is worth retrying and after how
+// long, adding up to 50% of jitter to the backoff.
+func (f *Fetcher) retryDelay(err error, backoff time.Duration) (time.Duration, bool) {
+	if errors.Is(err, ErrForbidden) || errors.Is(err, ErrTooLarge) || errors.Is(err, ErrContentType) || errors.Is(err, errNotModified) || errors.Is(err, context.Canceled) {
+		return 0, false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		if !statusErr.temporary() {
+			return 0, false
+		}
+		if statusErr.RetryAfter > backoff {
+			backoff = min(statusErr.RetryAfter, maxRetryAfter)
+		}
+	}
+
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	return backoff + time.Duration(rand.Int63n(int64(backoff)/2+1)), true
+}
+
+func (f *Fetcher) download(ctx context.Context, rawURL string, cond validators, out io.Writer) (validators, error) {
+	if f.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.cfg.Timeout)
+		defer cancel()
+	}
+
+	req, err := f.newRequest(ctx, http.MethodGet, rawURL)
+	if err != nil {
+		return validators{}, err
+	}
+
+	if cond.etag != "" {
+		req.Header.Set("If-None-Match", cond.etag)
+	}
+	if cond.lastModified != "" {
+		req.Header.Set("If-Modified-Since", cond.lastModified)
+	}
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return validators{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotModified && cond != (validators{}) {
+		return validators{}, errNotModified
+	}
+	if res.StatusCode != http.StatusOK {
+		return validators{}, &StatusError{StatusCode: res.StatusCode, RetryAfter: retryAfter(res.Header.Get("Retry-After"))}
+	}
+
+	// error pages are often served with a 200
+	if mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mediaType == "text/html" {
+		return validators{}, fmt.Errorf("%w %s", ErrContentType, mediaType)
+	}
+
+	body := io.Reader(res.Body)
+	if f.cfg.MaxBytes > 0 {
+		if res.ContentLength > f.cfg.MaxBytes {
+			return validators{}, fmt.Errorf("%w: %d bytes", ErrTooLarge, res.ContentLength)
+		}
+		body = io.LimitReader(res.Body, f.cfg.MaxBytes+1)
+	}
+
+	n, err := io.Copy(progress.Writer(ctx, out, res.ContentLength), body)
+	if err != nil {
+		return validators{}, err
+	}
+	if f.cfg.MaxBytes > 0 && n > f.cfg.MaxBytes {
+		return validators{}, fmt.Errorf("%w: more than %d bytes", ErrTooLarge, f.cfg.MaxBytes)
+	}
+	if res.ContentLength >= 0 && n != res.ContentLength {
+		return validators{}, fmt.Errorf("truncated download: got %d of %d bytes", n, res.ContentLength)
+	}
+	return validators{etag: res.Header.Get("ETag"), lastModified: res.Header.Get("Last-Modified")}, nil
+}
+
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
@@ -0,0 +1,391 @@
+package fetch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const content = "a,b\n1,2\n3,4\n"
+
+func sum(data string) string {
+	s := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(s[:])
+}
+
+// testConfig allows the loopback test servers.
+func testConfig() Config {
+	return Config{
+		Timeout:        5 * time.Second,
+		ConnectTimeout: time.Second,
+		Backoff:        time.Millisecond,
+		AllowHTTP:      true,
+		AllowedCIDRs:   []string{"127.0.0.0/8"},
+	}
+}
+
+func newFetcher(t *testing.T, cfg Config) *Fetcher {
+	t.Helper()
+
+	f, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// serve starts a server answering with handler, counting its requests.
+func serve(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func serveContent(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/csv")
+	fmt.Fprint(w, content)
+}
+
+// download downloads the URL to a temporary file, returning its content.
+func download(t *testing.T, f *Fetcher, rawURL, sha256Hex string) (string, error) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "download.csv")
+	err := f.DownloadToFile(context.Background(), rawURL, path, sha256Hex)
+	data, readErr := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(readErr, os.ErrNotExist) {
+			t.Errorf("the file of a failed download was kept")
+		}
+		return "", err
+	}
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	return string(data), nil
+}
+
+func TestDownloadToFile(t *testing.T) {
+	srv, _ := serve(t, serveContent)
+	f := newFetcher(t, testConfig())
+
+	tests := []struct {
+		name    string
+		sha256  string
+		wantErr error
+	}{
+		{"no checksum", "", nil},
+		{"checksum", sum(content), nil},
+		{"upper case checksum", strings.ToUpper(sum(content)), nil},
+		{"checksum mismatch", sum("other"), ErrChecksumMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := download(t, f, srv.URL+"/data.csv", tt.sha256)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != content {
+				t.Errorf("content = %q, want %q", got, content)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"zz", sum(content)[:10]} {
+		if _, err := download(t, f, srv.URL, invalid); err == nil {
+			t.Errorf("invalid checksum %q accepted", invalid)
+		}
+	}
+}
+
+func TestForbidden(t *testing.T) {
+	srv, requests := serve(t, serveContent)
+	u, _ := url.Parse(srv.URL)
+
+	tests := []struct {
+		name   string
+		cfg    func(*Config)
+		rawURL string
+	}{
+		{"loopback by default", func(c *Config) { c.AllowedCIDRs = nil }, srv.URL},
+		{"denied CIDR", func(c *Config) { c.DeniedCIDRs = []string{"127.0.0.1/32"} }, srv.URL},
+		{"plain http", func(c *Config) { c.AllowHTTP = false }, srv.URL},
+		{"other scheme", nil, "file:///etc/passwd"},
+		{"credentials", nil, "http://user:pass@" + u.Host},
+		{"denied host", func(c *Config) { c.DeniedHosts = []string{"127.0.0.1"} }, srv.URL},
+		{"host not allowed", func(c *Config) { c.AllowedHosts = []string{"*.example.com"} }, srv.URL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			if tt.cfg != nil {
+				tt.cfg(&cfg)
+			}
+
+			before := requests.Load()
+			_, err := download(t, newFetcher(t, cfg), tt.rawURL, "")
+			if !errors.Is(err, ErrForbidden) {
+				t.Fatalf("err = %v, want ErrForbidden", err)
+			}
+			if requests.Load() != before {
+				t.Error("the server was reached")
+			}
+		})
+	}
+}
+
+func TestMatchHost(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		host     string
+		want     bool
+	}{
+		{[]string{"example.com"}, "example.com", true},
+		{[]string{"Example.COM "}, "example.com", true},
+		{[]string{"example.com"}, "www.example.com", false},
+		{[]string{"*.example.com"}, "www.example.com", true},
+		{[]string{"*.example.com"}, "example.com", false},
+		{[]string{"*.example.com"}, "badexample.com", false},
+		{nil, "example.com", false},
+	}
+	for _, tt := range tests {
+		if got := matchHost(tt.patterns, tt.host); got != tt.want {
+			t.Errorf("matchHost(%q, %q) = %v, want %v", tt.patterns, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestRedirects(t *testing.T) {
+	var target string
+	srv, _ := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/data.csv":
+			serveContent(w, r)
+		case "/moved":
+			http.Redirect(w, r, "/data.csv", http.StatusFound)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		case "/elsewhere":
+			http.Redirect(w, r, target, http.StatusFound)
+		}
+	})
+	u, _ := url.Parse(srv.URL)
+
+	tests := []struct {
+		name    string
+		path    string
+		target  string
+		cfg     func(*Config)
+		wantErr error
+	}{
+		{name: "same host", path: "/moved"},
+		{name: "denied host", path: "/elsewhere", target: "http://localhost:" + u.Port() + "/data.csv", cfg: func(c *Config) { c.DeniedHosts = []string{"localhost"} }, wantErr: ErrForbidden},
+		{name: "denied address", path: "/elsewhere", target: "http://127.0.0.2:" + u.Port() + "/data.csv", cfg: func(c *Config) { c.AllowedCIDRs = []string{"127.0.0.1/32"} }, wantErr: ErrForbidden},
+		{name: "other scheme", path: "/elsewhere", target: "file:///etc/passwd", wantErr: ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			if tt.cfg != nil {
+				tt.cfg(&cfg)
+			}
+			target = tt.target
+
+			got, err := download(t, newFetcher(t, cfg), srv.URL+tt.path, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != content {
+				t.Errorf("content = %q, want %q", got, content)
+			}
+		})
+	}
+
+	t.Run("too many redirects", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.MaxRedirects = 3
+		_, err := download(t, newFetcher(t, cfg), srv.URL+"/loop", "")
+		if err == nil || !strings.Contains(err.Error(), "redirects") {
+			t.Fatalf("err = %v, want too many redirects", err)
+		}
+	})
+}
+
+func TestSizeCaps(t *testing.T) {
+	body := strings.Repeat("x", 100)
+	srv, requests := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("chunked") {
+			// no Content-Length, the size is only known while reading
+			w.Write([]byte(body[:50]))
+			w.(http.Flusher).Flush()
+			w.Write([]byte(body[50:]))
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		w.Write([]byte(body))
+	})
+
+	tests := []struct {
+		name     string
+		maxBytes int64
+		query    string
+		wantErr  error
+	}{
+		{"no limit", 0, "", nil},
+		{"at the limit", 100, "", nil},
+		{"content length over the limit", 99, "", ErrTooLarge},
+		{"chunked at the limit", 100, "?chunked", nil},
+		{"chunked over the limit", 60, "?chunked", ErrTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.MaxBytes = tt.maxBytes
+			cfg.Retries = 2
+
+			before := requests.Load()
+			got, err := download(t, newFetcher(t, cfg), srv.URL+"/"+tt.query, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != body {
+				t.Errorf("got %d bytes, want %d", len(got), len(body))
+			}
+			if n := requests.Load() - before; n != 1 {
+				t.Errorf("%d requests, oversized downloads must not be retried", n)
+			}
+		})
+	}
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32
+		fail         func(http.ResponseWriter)
+		retries      int
+		wantStatus   int
+		wantErr      error
+		wantRequests int32
+	}{
+		{name: "recovers", failures: 2, fail: status(http.StatusServiceUnavailable), retries: 2, wantRequests: 3},
+		{name: "too many requests", failures: 1, fail: status(http.StatusTooManyRequests), retries: 1, wantRequests: 2},
+		{name: "gives up", failures: 5, fail: status(http.StatusBadGateway), retries: 2, wantStatus: http.StatusBadGateway, wantRequests: 3},
+		{name: "no retries", failures: 1, fail: status(http.StatusInternalServerError), retries: 0, wantStatus: http.StatusInternalServerError, wantRequests: 1},
+		{name: "not found", failures: 1, fail: status(http.StatusNotFound), retries: 3, wantStatus: http.StatusNotFound, wantRequests: 1},
+		{name: "truncated", failures: 1, fail: truncated, retries: 1, wantRequests: 2},
+		{name: "error page", failures: 1, fail: htmlPage, retries: 3, wantErr: ErrContentType, wantRequests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var failed atomic.Int32
+			srv, requests := serve(t, func(w http.ResponseWriter, r *http.Request) {
+				if failed.Add(1) <= tt.failures {
+					tt.fail(w)
+					return
+				}
+				serveContent(w, r)
+			})
+
+			cfg := testConfig()
+			cfg.Retries = tt.retries
+			got, err := download(t, newFetcher(t, cfg), srv.URL, sum(content))
+
+			var statusErr *StatusError
+			switch {
+			case tt.wantStatus != 0:
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.wantStatus {
+					t.Fatalf("err = %v, want status %d", err, tt.wantStatus)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatal(err)
+			case got != content:
+				t.Errorf("content = %q, want %q", got, content)
+			}
+			if n := requests.Load(); n != tt.wantRequests {
+				t.Errorf("%d requests, want %d", n, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func status(code int) func(http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(code)
+	}
+}
+
+// truncated announces more bytes than it sends.
+func truncated(w http.ResponseWriter) {
+	w.Header().Set("Content-Length", fmt.Sprint(len(content)+10))
+	w.Write([]byte(content))
+}
+
+func htmlPage(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, "<html>maintenance</html>")
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"3", 3 * time.Second},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := retryAfter(tt.value); got != tt.want {
+			t.Errorf("retryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got := retryAfter(date); got < 59*time.Minute || got > time.Hour {
+		t.Errorf("retryAfter(%q) = %s, want about an hour", date, got)
+	}
+}
+
+func TestHead(t *testing.T) {
+	srv, _ := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		serveContent(w, r)
+	})
+	f := newFetcher(t, testConfig())
+
+	size, err := f.Head(context.Background(), srv.URL+"/data.csv")
+	if err != nil || size != int64(len(content)) {
+		t.Errorf("Head = %d, %v, want %d", size, err, len(content))
+	}
+
+	var statusErr *StatusError
+	if _, err := f.Head(context.Background(), srv.URL+"/missing"); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Head of a missing file = %v, want a 404", err)
+	}
+}
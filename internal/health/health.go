@@ -0,0 +1,146 @@
+// Package health runs the readiness checks of the server and keeps their last
+// report, served over gRPC and HTTP.
+package health
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Check is a readiness check, Run returning why the server is not ready.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Result is the outcome of a check.
+type Result struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// Report is the outcome of all the checks, the server being ready when they
+// all pass.
+type Report struct {
+	Ready     bool      `json:"ready"`
+	Checks    []Result  `json:"checks"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Failed returns the names of the failed checks.
+func (r Report) Failed() []string {
+	var names []string
+	for _, c := range r.Checks {
+		if !c.OK {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+type Checker struct {
+	checks []Check
+	// timeout bounds every check
+	timeout time.Duration
+
+	mu       sync.Mutex
+	last     Report
+	draining bool
+}
+
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{checks: checks, timeout: timeout}
+}
+
+// Run runs the checks one after the other and returns their report, which
+// Last returns until the next run.
+func (c *Checker) Run(ctx context.Context) Report {
+	report := Report{Ready: true, CheckedAt: time.Now().UTC()}
+	for _, check := range c.checks {
+		result := Result{Name: check.Name, OK: true}
+		if err := c.run(ctx, check); err != nil {
+			result.OK, result.Error = false, err.Error()
+			report.Ready = false
+		}
+		report.Checks = append(report.Checks, result)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.last = c.drained(report)
+	return c.last
+}
+
+func (c *Checker) run(ctx context.Context, check Check) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	return check.Run(ctx)
+}
+
+// Last returns the report of the last run.
+func (c *Checker) Last() Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.last
+}
+
+// Watch runs the checks every interval until ctx is done, passing the
+// reports to f.
+func (c *Checker) Watch(ctx context.Context, interval time.Duration, f func(Report)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			f(c.Run(ctx))
+		}
+	}
+}
+
+// Drain reports the server as not ready from now on, as it is shutting down.
+func (c *Checker) Drain() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.draining = true
+	c.last = c.drained(c.last)
+}
+
+func (c *Checker) drained(r Report) Report {
+	if !c.draining {
+		return r
+	}
+	r.Ready = false
+	r.Checks = append(r.Checks[:len(r.Checks):len(r.Checks)], Result{Name: "shutdown", Error: "the server is shutting down"})
+	return r
+}
+
+// DiskSpace checks that dir is writable and has at least minFree bytes
+// available.
+func DiskSpace(name, dir string, minFree int64) Check {
+	return Check{Name: name, Run: func(ctx context.Context) error {
+		var fs syscall.Statfs_t
+		if err := syscall.Statfs(dir, &fs); err != nil {
+			return err
+		}
+		if free := int64(fs.Bavail) * int64(fs.Bsize); free < minFree {
+			return fmt.Errorf("%d bytes available in %s, less than %d", free, dir, minFree)
+		}
+
+		f, err := os.CreateTemp(dir, ".health-*")
+		if err != nil {
+			return err
+		}
+		f.Close()
+		return os.Remove(f.Name())
+	}}
+}
@@ -0,0 +1,128 @@
+// Package listen opens the listeners of the servers, on TCP addresses and
+// Unix sockets.
+package listen
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// unixPrefix starts the addresses of Unix sockets, like unix:/run/app.sock.
+const unixPrefix = "unix:"
+
+// Parse returns the network and the address of addr, a host:port TCP address
+// or unix:/path for a Unix socket. An empty host listens on all interfaces.
+func Parse(addr string) (network, address string, err error) {
+	if path, ok := strings.CutPrefix(addr, unixPrefix); ok {
+		if path == "" {
+			return "", "", fmt.Errorf("invalid address %q: missing socket path", addr)
+		}
+		return "unix", path, nil
+	}
+
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid address %q: expected host:port or unix:/path", addr)
+	}
+	if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+		return "", "", fmt.Errorf("invalid address %q: invalid port %q", addr, port)
+	}
+	return "tcp", addr, nil
+}
+
+// ParseMode parses the permissions of the Unix sockets, in octal like 0660.
+func ParseMode(mode string) (os.FileMode, error) {
+	m, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || m > 0o777 {
+		return 0, fmt.Errorf("invalid permissions %q, expected octal like 0660", mode)
+	}
+	return os.FileMode(m), nil
+}
+
+// Listen listens on all the addresses, the Unix sockets getting the
+// permissions mode. Either all the listeners are opened or none.
+func Listen(addrs []string, mode os.FileMode) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, len(addrs))
+	for _, addr := range addrs {
+		lis, err := listen(addr, mode)
+		if err != nil {
+			Close(listeners)
+			return nil, err
+		}
+		listeners = append(listeners, lis)
+	}
+	return listeners, nil
+}
+
+func listen(addr string, mode os.FileMode) (net.Listener, error) {
+	network, address, err := Parse(addr)
+	if err != nil {
+		return nil, err
+	}
+	if network == "tcp" {
+		return net.Listen(network, address)
+	}
+
+	if err := removeStaleSocket(address); err != nil {
+		return nil, err
+	}
+	// the socket file is removed when the listener is closed
+	lis, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(address, mode); err != nil {
+		lis.Close()
+		return nil, fmt.Errorf("failed to set the permissions of %s: %w", address, err)
+	}
+	return lis, nil
+}
+
+// removeStaleSocket removes the socket at path left behind by a server that
+// didn't stop cleanly. Sockets still accepting connections are left alone.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode().Type() != os.ModeSocket {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return fmt.Errorf("%s is in use by another process", path)
+	}
+	return os.Remove(path)
+}
+
+// Serve calls serve on every listener, each in its own goroutine. It returns
+// the first error returned by serve, or nil once they all returned nil.
+func Serve(listeners []net.Listener, serve func(net.Listener) error) error {
+	errs := make(chan error, len(listeners))
+	for _, lis := range listeners {
+		go func() {
+			errs <- serve(lis)
+		}()
+	}
+	for range listeners {
+		if err := <-errs; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes all the listeners.
+func Close(listeners []net.Listener) {
+	for _, lis := range listeners {
+		lis.Close()
+	}
+}
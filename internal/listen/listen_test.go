@@ -0,0 +1,152 @@
+package listen
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		addr             string
+		network, address string
+		err              string
+	}{
+		{"127.0.0.1:9006", "tcp", "127.0.0.1:9006", ""},
+		{":9006", "tcp", ":9006", ""},
+		{"[::1]:9006", "tcp", "[::1]:9006", ""},
+		{"localhost:65535", "tcp", "localhost:65535", ""},
+		{"unix:/run/duckdb.sock", "unix", "/run/duckdb.sock", ""},
+		{"unix:duckdb.sock", "unix", "duckdb.sock", ""},
+		{"unix:", "", "", "missing socket path"},
+		{"localhost", "", "", "expected host:port or unix:/path"},
+		{"/run/duckdb.sock", "", "", "expected host:port or unix:/path"},
+		{"::1:9006", "", "", "expected host:port or unix:/path"},
+		{":0", "", "", `invalid port "0"`},
+		{":65536", "", "", `invalid port "65536"`},
+		{":grpc", "", "", `invalid port "grpc"`},
+		{"", "", "", "expected host:port or unix:/path"},
+	}
+	for _, tt := range tests {
+		network, address, err := Parse(tt.addr)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Parse(%q) = %v, want %q", tt.addr, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || network != tt.network || address != tt.address {
+			t.Errorf("Parse(%q) = %q, %q, %v, want %q, %q", tt.addr, network, address, err, tt.network, tt.address)
+		}
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		mode  string
+		want  os.FileMode
+		valid bool
+	}{
+		{"0660", 0o660, true},
+		{"600", 0o600, true},
+		{"0777", 0o777, true},
+		{"0", 0, true},
+		{"1777", 0, false},
+		{"0680", 0, false},
+		{"rw-rw----", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseMode(tt.mode)
+		if (err == nil) != tt.valid || got != tt.want {
+			t.Errorf("ParseMode(%q) = %v, %v, want %v", tt.mode, got, err, tt.want)
+		}
+	}
+}
+
+// staleSocket leaves a socket at path that nothing listens on, like a server
+// that crashed.
+func staleSocket(t *testing.T, path string) {
+	t.Helper()
+
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis.(*net.UnixListener).SetUnlinkOnClose(false)
+	lis.Close()
+}
+
+func TestListen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "duckdb.sock")
+	staleSocket(t, path)
+
+	// port 0 is rejected, a free port is taken instead
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := free.Addr().String()
+	free.Close()
+
+	listeners, err := Listen([]string{addr, "unix:" + path}, 0o600)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	if len(listeners) != 2 || listeners[0].Addr().Network() != "tcp" || listeners[1].Addr().Network() != "unix" {
+		t.Fatalf("listeners %v", listeners)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Type() != os.ModeSocket || info.Mode().Perm() != 0o600 {
+		t.Errorf("socket mode %v, want a socket with 0600", info.Mode())
+	}
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("stale socket not replaced: %v", err)
+	}
+	conn.Close()
+
+	// the socket is in use now
+	if _, err := Listen([]string{"unix:" + path}, 0o600); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Errorf("Listen on a socket in use = %v", err)
+	}
+
+	Close(listeners)
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Errorf("socket left once closed: %v", err)
+	}
+}
+
+func TestListenFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "duckdb.sock")
+	file := filepath.Join(dir, "data.csv")
+	if err := os.WriteFile(file, []byte("a,b\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// a path that is not a socket is never removed
+	if _, err := Listen([]string{"unix:" + path, "unix:" + file}, 0o660); err == nil || !strings.Contains(err.Error(), "is not a socket") {
+		t.Fatalf("Listen on a regular file = %v", err)
+	}
+	if data, err := os.ReadFile(file); err != nil || string(data) != "a,b\n" {
+		t.Errorf("regular file changed: %q, %v", data, err)
+	}
+	// nor are the listeners already opened left behind
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Errorf("socket of the first address left: %v", err)
+	}
+
+	if _, err := Listen([]string{"unix:" + path, "localhost"}, 0o660); err == nil {
+		t.Error("Listen accepted an invalid address")
+	}
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Errorf("socket of the first address left: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+// Package logging sets up the structured logger of the server. Records logged
+// with the context of a request carry its ID and trace ID, and the helpers
+// below keep queries and credentials out of the logs unless debugging.
+package logging
+
+import (
+	"context"
+	"duckdb-server/internal/requestid"
+	"duckdb-server/internal/tracing"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+type Config struct {
+	Level slog.Level
+	// Format is FormatText or FormatJSON.
+	Format string
+}
+
+// debug is set when the debug level is enabled, queries and sources are
+// then logged in full.
+var debug atomic.Bool
+
+// level is the level of the logger, changed by SetLevel.
+var level slog.LevelVar
+
+// secretKeys are the attribute keys whose values are never logged.
+var secretKeys = []string{"authorization", "password", "secret", "token", "credential"}
+
+// Init installs the logger as the default one, the log package included.
+func Init(w io.Writer, cfg Config) error {
+	opts := &slog.HandlerOptions{Level: &level, ReplaceAttr: redactSecrets}
+
+	var handler slog.Handler
+	switch cfg.Format {
+	case FormatText, "":
+		handler = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+
+	SetLevel(cfg.Level)
+	slog.SetDefault(slog.New(contextHandler{handler}))
+	return nil
+}
+
+// SetLevel changes the level of the logger installed by Init.
+func SetLevel(l slog.Level) {
+	level.Set(l)
+	debug.Store(l <= slog.LevelDebug)
+}
+
+// ParseLevel parses debug, info, warn or error.
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(s))
+	return level, err
+}
+
+// contextHandler adds the request and trace IDs of the context to the
+// records.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := requestid.FromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+func redactSecrets(groups []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	for _, secret := range secretKeys {
+		if strings.Contains(key, secret) {
+			return slog.String(a.Key, "REDACTED")
+		}
+	}
+	return a
+}
+
+// SQL logs a query in full at the debug level, and only its hash and length
+// otherwise as it may hold data.
+func SQL(query string) slog.Attr {
+	if debug.Load() {
+		return slog.String("query", query)
+	}
+	return slog.Group("query", slog.String("hash", tracing.HashQuery(query)), slog.Int("length", len(query)))
+}
+
+// Source logs the path or URL of a source. The credentials and the query
+// string of URLs, which may hold a signature, are left out unless debugging.
+func Source(p string) slog.Attr {
+	return slog.String("source", RedactURL(p))
+}
+
+// RedactURL removes the credentials and the query string of a URL unless
+// debugging. Paths are returned as is.
+func RedactURL(p string) string {
+	u, err := url.Parse(p)
+	if debug.Load() || err != nil || u.Scheme == "" {
+		return p
+	}
+
+	if u.User != nil {
+		u.User = url.User("REDACTED")
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = "REDACTED"
+	}
+	return u.String()
+}
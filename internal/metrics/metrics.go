@@ -0,0 +1,105 @@
+// Package metrics holds the Prometheus metrics of the server: the RPCs, the
+// data they stream, the time spent in every phase of a transformation and
+// the resources in use.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	grpc "google.golang.org/grpc"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "duckdb_server"
+
+// Phases of a transformation.
+const (
+	PhaseDownload  = "download"
+	PhaseLoad      = "load"
+	PhaseTransform = "transform"
+	PhaseView      = "view"
+	PhaseQuery     = "query"
+	PhaseEncode    = "encode"
+	PhaseSend      = "send"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	RPCs = promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_requests_total",
+		Help:      "RPCs handled, by method and status code.",
+	}, []string{"method", "code"})
+
+	RPCDuration = promauto.With(registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_duration_seconds",
+		Help:      "Duration of the RPCs, by method.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 16),
+	}, []string{"method"})
+
+	RowsSent = promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rows_sent_total",
+		Help:      "Rows streamed to the clients, by method.",
+	}, []string{"method"})
+
+	BytesSent = promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bytes_sent_total",
+		Help:      "Bytes of data streamed to the clients, by method.",
+	}, []string{"method"})
+
+	ChunksSent = promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "chunks_sent_total",
+		Help:      "Chunks streamed to the clients, by method.",
+	}, []string{"method"})
+
+	// PhaseSeconds is a counter rather than a histogram, as the encode and
+	// send phases are timed for every chunk.
+	PhaseSeconds = promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "phase_seconds_total",
+		Help:      "Time spent in every phase of the transformations, by method.",
+	}, []string{"method", "phase"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// StartPhase starts timing a phase of the request, the returned function
+// ends it.
+func StartPhase(ctx context.Context, phase string) func() {
+	method, _ := grpc.Method(ctx)
+	start := time.Now()
+	return func() {
+		PhaseSeconds.WithLabelValues(method, phase).Add(time.Since(start).Seconds())
+	}
+}
+
+// GaugeFunc registers a gauge whose value is computed by fn on every scrape.
+func GaugeFunc(name, help string, labels prometheus.Labels, fn func() float64) {
+	registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        name,
+		Help:        help,
+		ConstLabels: labels,
+	}, fn))
+}
+
+// Handler serves the metrics in the Prometheus format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
@@ -0,0 +1,246 @@
+// Package objectstore reads sources from and writes exports to S3-compatible
+// object storage, addressed by s3://bucket/key URIs.
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"duckdb-server/internal/progress"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const (
+	scheme = "s3://"
+
+	// minPartSize is the smallest part S3 accepts in a multipart upload.
+	minPartSize     = 5 << 20
+	defaultPartSize = 64 << 20
+)
+
+var (
+	ErrInvalidURI       = errors.New("invalid s3 URI")
+	ErrForbidden        = errors.New("bucket not allowed")
+	ErrNotFound         = errors.New("object not found")
+	ErrTooLarge         = errors.New("object exceeds the maximum size")
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+)
+
+type Config struct {
+	// Endpoint is host[:port] or a URL, an http:// URL disabling TLS. AWS
+	// S3 is used when empty.
+	Endpoint string
+	Region   string
+	// The credentials are taken from the AWS_* environment variables or the
+	// instance metadata when AccessKeyID is empty.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// PathStyle addresses buckets as endpoint/bucket instead of
+	// bucket.endpoint, as most S3 stand-ins require.
+	PathStyle bool
+	// PartSize is the size of the parts of multipart uploads.
+	PartSize uint64
+	// AllowedBuckets limits the buckets that can be accessed when not empty.
+	AllowedBuckets []string
+}
+
+type Store struct {
+	client   *minio.Client
+	partSize uint64
+	buckets  map[string]bool
+}
+
+// IsURI reports whether p is an s3:// URI.
+func IsURI(p string) bool {
+	return len(p) >= len(scheme) && strings.EqualFold(p[:len(scheme)], scheme)
+}
+
+// ParseURI splits an s3://bucket/key URI.
+func ParseURI(uri string) (bucket, key string, err error) {
+	if !IsURI(uri) {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidURI, uri)
+	}
+
+	bucket, key, _ = strings.Cut(uri[len(scheme):], "/")
+	if bucket == "" || key == "" || strings.HasSuffix(key, "/") {
+		return "", "", fmt.Errorf("%w: %q, expected s3://bucket/key", ErrInvalidURI, uri)
+	}
+	return bucket, key, nil
+}
+
+func New(cfg Config) (*Store, error) {
+	endpoint, secure := "s3.amazonaws.com", true
+	if cfg.Endpoint != "" {
+		endpoint = cfg.Endpoint
+		if strings.Contains(cfg.Endpoint, "://") {
+			u, err := url.Parse(cfg.Endpoint)
+			if err != nil {
+				return nil, fmt.Errorf("invalid endpoint: %w", err)
+			}
+			endpoint, secure = u.Host, u.Scheme != "http"
+		}
+	}
+
+	var creds *credentials.Credentials
+	if cfg.AccessKeyID != "" {
+		creds = credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, cfg.SessionToken)
+	} else {
+		creds = credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.IAM{},
+		})
+	}
+
+	lookup := minio.BucketLookupAuto
+	if cfg.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:        creds,
+		Secure:       secure,
+		Region:       cfg.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	partSize := cfg.PartSize
+	if partSize == 0 {
+		partSize = defaultPartSize
+	}
+	if partSize < minPartSize {
+		return nil, fmt.Errorf("part size must be at least %d bytes", minPartSize)
+	}
+
+	s := &Store{client: client, partSize: partSize, buckets: map[string]bool{}}
+	for _, bucket := range cfg.AllowedBuckets {
+		s.buckets[bucket] = true
+	}
+	return s, nil
+}
+
+func (s *Store) locate(uri string) (string, string, error) {
+	bucket, key, err := ParseURI(uri)
+	if err != nil {
+		return "", "", err
+	}
+	if len(s.buckets) > 0 && !s.buckets[bucket] {
+		return "", "", fmt.Errorf("%w: %s", ErrForbidden, bucket)
+	}
+	return bucket, key, nil
+}
+
+// Size returns the size of an object.
+func (s *Store) Size(ctx context.Context, uri string) (int64, error) {
+	bucket, key, err := s.locate(uri)
+	if err != nil {
+		return 0, err
+	}
+
+	info, err := s.client.StatObject(ctx, bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return 0, convertError(uri, err)
+	}
+	return info.Size, nil
+}
+
+// Download writes an object to a new file at path. When sha256Hex is set, the
+// content must have this SHA-256. The file is removed when the download
+// fails.
+func (s *Store) Download(ctx context.Context, uri, path string, maxBytes int64, sha256Hex string) (err error) {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	return s.Stream(ctx, uri, out, maxBytes, sha256Hex)
+}
+
+// Stream writes an object to w as it arrives. A checksum mismatch is only
+// known once everything was written.
+func (s *Store) Stream(ctx context.Context, uri string, w io.Writer, maxBytes int64, sha256Hex string) error {
+	bucket, key, err := s.locate(uri)
+	if err != nil {
+		return err
+	}
+
+	var want []byte
+	if sha256Hex != "" {
+		want, err = hex.DecodeString(sha256Hex)
+		if err != nil || len(want) != sha256.Size {
+			return fmt.Errorf("invalid sha256 %q", sha256Hex)
+		}
+	}
+
+	obj, err := s.client.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return convertError(uri, err)
+	}
+	defer obj.Close()
+
+	info, err := obj.Stat()
+	if err != nil {
+		return convertError(uri, err)
+	}
+	if maxBytes > 0 && info.Size > maxBytes {
+		return fmt.Errorf("%w: %d bytes", ErrTooLarge, info.Size)
+	}
+
+	sum := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(progress.Writer(ctx, w, info.Size), sum), obj); err != nil {
+		return convertError(uri, err)
+	}
+
+	if want != nil && !bytes.Equal(sum.Sum(nil), want) {
+		return fmt.Errorf("%w: got sha256 %x", ErrChecksumMismatch, sum.Sum(nil))
+	}
+	return nil
+}
+
+// Upload writes a file to an object, with a multipart upload when the file is
+// larger than the part size.
+func (s *Store) Upload(ctx context.Context, path, uri, contentType string) error {
+	bucket, key, err := s.locate(uri)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.FPutObject(ctx, bucket, key, path, minio.PutObjectOptions{
+		ContentType: contentType,
+		PartSize:    s.partSize,
+	})
+	if err != nil {
+		return convertError(uri, err)
+	}
+	return nil
+}
+
+func convertError(uri string, err error) error {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NoSuchBucket":
+		return fmt.Errorf("%w: %s", ErrNotFound, uri)
+	case "AccessDenied":
+		return fmt.Errorf("%w: %s: %v", ErrForbidden, uri, err)
+	}
+	return err
+}
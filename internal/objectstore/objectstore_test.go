@@ -0,0 +1,394 @@
+package objectstore
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 is a local S3 stand-in serving path-style requests from memory. It
+// doesn't check the signatures, only that requests are signed.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]fakeObject
+	// uploads are the parts of the multipart uploads in progress, by id
+	uploads map[string]map[int][]byte
+	// denied buckets answer AccessDenied
+	denied   map[string]bool
+	requests []string
+}
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	t.Helper()
+
+	s := &fakeS3{
+		objects: map[string]fakeObject{},
+		uploads: map[string]map[int][]byte{},
+		denied:  map[string]bool{},
+	}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return s, srv
+}
+
+func (s *fakeS3) put(bucket, key string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[bucket+"/"+key] = fakeObject{data: data}
+}
+
+func (s *fakeS3) get(bucket, key string) (fakeObject, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.objects[bucket+"/"+key]
+	return obj, ok
+}
+
+// requested returns the requests made so far, like "PUT ?uploads".
+func (s *fakeS3) requested() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	op := r.Method
+	for _, param := range []string{"uploads", "uploadId", "partNumber"} {
+		if query.Has(param) {
+			op += " ?" + param
+		}
+	}
+	s.requests = append(s.requests, op)
+
+	if !strings.Contains(r.Header.Get("Authorization"), "Credential=AK/") {
+		s.fail(w, r, http.StatusForbidden, "AccessDenied")
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if s.denied[bucket] {
+		s.fail(w, r, http.StatusForbidden, "AccessDenied")
+		return
+	}
+	name := bucket + "/" + key
+
+	switch {
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		obj, ok := s.objects[name]
+		if !ok {
+			s.fail(w, r, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		sum := md5.Sum(obj.data)
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Length", strconv.Itoa(len(obj.data)))
+		w.Header().Set("Content-Type", "application/octet-stream")
+		if r.Method == http.MethodGet {
+			w.Write(obj.data)
+		}
+
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		id := strconv.Itoa(len(s.uploads) + 1)
+		s.uploads[id] = map[int][]byte{}
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>", bucket, key, id)
+
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		parts, ok := s.uploads[query.Get("uploadId")]
+		if !ok {
+			s.fail(w, r, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		n, _ := strconv.Atoi(query.Get("partNumber"))
+		data, err := readBody(r)
+		if err != nil {
+			s.fail(w, r, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		parts[n] = data
+		w.Header().Set("ETag", fmt.Sprintf(`"part%d"`, n))
+
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		parts, ok := s.uploads[query.Get("uploadId")]
+		if !ok {
+			s.fail(w, r, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		numbers := make([]int, 0, len(parts))
+		for n := range parts {
+			numbers = append(numbers, n)
+		}
+		sort.Ints(numbers)
+		var data []byte
+		for _, n := range numbers {
+			data = append(data, parts[n]...)
+		}
+		delete(s.uploads, query.Get("uploadId"))
+		s.objects[name] = fakeObject{data: data, contentType: r.Header.Get("Content-Type")}
+		fmt.Fprintf(w, `<CompleteMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><ETag>"multipart"</ETag></CompleteMultipartUploadResult>`, bucket, key)
+
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(s.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodPut:
+		data, err := readBody(r)
+		if err != nil {
+			s.fail(w, r, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		s.objects[name] = fakeObject{data: data, contentType: r.Header.Get("Content-Type")}
+		w.Header().Set("ETag", `"single"`)
+
+	default:
+		s.fail(w, r, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (s *fakeS3) fail(w http.ResponseWriter, r *http.Request, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message><Resource>%s</Resource></Error>", code, code, r.URL.Path)
+	}
+}
+
+// readBody reads the body of an upload, decoding the aws-chunked encoding of
+// the payloads signed chunk by chunk.
+func readBody(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var data []byte
+	br := bufio.NewReader(r.Body)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return data, nil
+		}
+		chunk := make([]byte, size+2)
+		if _, err := io.ReadFull(br, chunk); err != nil {
+			return nil, err
+		}
+		data = append(data, chunk[:size]...)
+	}
+}
+
+func newTestStore(t *testing.T, endpoint string, cfg Config) *Store {
+	t.Helper()
+
+	cfg.Endpoint = endpoint
+	cfg.Region = "us-east-1"
+	cfg.AccessKeyID = "AK"
+	cfg.SecretAccessKey = "secret"
+	cfg.PathStyle = true
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestParseURI(t *testing.T) {
+	tests := []struct {
+		uri    string
+		bucket string
+		key    string
+	}{
+		{"s3://bucket/key.csv", "bucket", "key.csv"},
+		{"S3://bucket/dir/key.csv", "bucket", "dir/key.csv"},
+		{"s3://bucket/", "", ""},
+		{"s3://bucket", "", ""},
+		{"s3:///key", "", ""},
+		{"s3://bucket/dir/", "", ""},
+		{"https://bucket/key", "", ""},
+		{"/local/path.csv", "", ""},
+	}
+	for _, tt := range tests {
+		bucket, key, err := ParseURI(tt.uri)
+		if tt.bucket == "" {
+			if !errors.Is(err, ErrInvalidURI) {
+				t.Errorf("ParseURI(%q) = %v, want ErrInvalidURI", tt.uri, err)
+			}
+			continue
+		}
+		if err != nil || bucket != tt.bucket || key != tt.key {
+			t.Errorf("ParseURI(%q) = %q, %q, %v, want %q, %q", tt.uri, bucket, key, err, tt.bucket, tt.key)
+		}
+	}
+}
+
+func TestNewPartSize(t *testing.T) {
+	if _, err := New(Config{AccessKeyID: "AK", SecretAccessKey: "secret", PartSize: minPartSize - 1}); err == nil {
+		t.Error("a part size below the S3 minimum was accepted")
+	}
+}
+
+func TestDownload(t *testing.T) {
+	fake, srv := newFakeS3(t)
+	data := []byte("a,b\n1,2\n")
+	fake.put("data", "in/source.csv", data)
+	fake.put("private", "source.csv", data)
+	fake.denied["private"] = true
+
+	store := newTestStore(t, srv.URL, Config{AllowedBuckets: []string{"data", "private"}})
+
+	tests := []struct {
+		name     string
+		uri      string
+		maxBytes int64
+		sha256   string
+		wantErr  error
+	}{
+		{name: "object", uri: "s3://data/in/source.csv"},
+		{name: "checksum", uri: "s3://data/in/source.csv", sha256: sha256Hex(data)},
+		{name: "at the size limit", uri: "s3://data/in/source.csv", maxBytes: int64(len(data))},
+		{name: "checksum mismatch", uri: "s3://data/in/source.csv", sha256: sha256Hex([]byte("other")), wantErr: ErrChecksumMismatch},
+		{name: "too large", uri: "s3://data/in/source.csv", maxBytes: int64(len(data)) - 1, wantErr: ErrTooLarge},
+		{name: "missing", uri: "s3://data/missing.csv", wantErr: ErrNotFound},
+		{name: "access denied", uri: "s3://private/source.csv", wantErr: ErrForbidden},
+		{name: "bucket not allowed", uri: "s3://other/source.csv", wantErr: ErrForbidden},
+		{name: "invalid URI", uri: "s3://data/", wantErr: ErrInvalidURI},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "source.csv")
+			err := store.Download(context.Background(), tt.uri, path, tt.maxBytes, tt.sha256)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			got, readErr := os.ReadFile(path)
+			if err != nil {
+				if !errors.Is(readErr, os.ErrNotExist) {
+					t.Error("the file of a failed download was kept")
+				}
+				return
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("content = %q, want %q", got, data)
+			}
+		})
+	}
+
+	for _, req := range fake.requested() {
+		if strings.HasPrefix(req, "GET") && strings.Contains(req, "other") {
+			t.Errorf("a bucket that is not allowed was requested")
+		}
+	}
+}
+
+func TestSize(t *testing.T) {
+	fake, srv := newFakeS3(t)
+	fake.put("data", "source.csv", make([]byte, 1234))
+	store := newTestStore(t, srv.URL, Config{})
+
+	size, err := store.Size(context.Background(), "s3://data/source.csv")
+	if err != nil || size != 1234 {
+		t.Errorf("Size = %d, %v, want 1234", size, err)
+	}
+	if _, err := store.Size(context.Background(), "s3://data/missing.csv"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Size of a missing object = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUpload(t *testing.T) {
+	tests := []struct {
+		name          string
+		size          int
+		wantMultipart bool
+	}{
+		{"single request", 1 << 10, false},
+		{"multipart", 2*minPartSize + 1<<20, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, srv := newFakeS3(t)
+			store := newTestStore(t, srv.URL, Config{PartSize: minPartSize})
+
+			data := bytes.Repeat([]byte("0123456789abcdef"), tt.size/16)
+			path := filepath.Join(t.TempDir(), "export.parquet")
+			if err := os.WriteFile(path, data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := store.Upload(context.Background(), path, "s3://exports/out/export.parquet", "application/vnd.apache.parquet"); err != nil {
+				t.Fatal(err)
+			}
+
+			obj, ok := fake.get("exports", "out/export.parquet")
+			if !ok {
+				t.Fatal("the object was not created")
+			}
+			if !bytes.Equal(obj.data, data) {
+				t.Errorf("uploaded %d bytes, want %d", len(obj.data), len(data))
+			}
+
+			multipart := false
+			for _, req := range fake.requested() {
+				multipart = multipart || req == "POST ?uploads"
+			}
+			if multipart != tt.wantMultipart {
+				t.Errorf("multipart upload = %v, want %v", multipart, tt.wantMultipart)
+			}
+			if !multipart && obj.contentType != "application/vnd.apache.parquet" {
+				t.Errorf("content type %q", obj.contentType)
+			}
+		})
+	}
+}
+
+func TestUploadNotAllowed(t *testing.T) {
+	fake, srv := newFakeS3(t)
+	store := newTestStore(t, srv.URL, Config{AllowedBuckets: []string{"exports"}})
+
+	path := filepath.Join(t.TempDir(), "export.parquet")
+	if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Upload(context.Background(), path, "s3://other/export.parquet", ""); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Upload = %v, want ErrForbidden", err)
+	}
+	if len(fake.requested()) != 0 {
+		t.Errorf("requests made: %q", fake.requested())
+	}
+}
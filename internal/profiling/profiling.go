@@ -0,0 +1,273 @@
+// Package profiling captures CPU, heap and goroutine profiles on demand, over
+// a time window or over one request, and keeps a bounded number of them on
+// disk.
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindCPU       Kind = "cpu"
+	KindHeap      Kind = "heap"
+	KindGoroutine Kind = "goroutine"
+)
+
+const fileSuffix = ".pprof"
+
+var (
+	ErrBusy    = errors.New("a CPU profile is already being captured")
+	ErrArmed   = errors.New("a profile is already requested for this request")
+	ErrUnknown = errors.New("unknown profile kind")
+)
+
+type Config struct {
+	// Dir is where the profiles are stored.
+	Dir string
+	// MaxFiles is the number of profiles kept, the oldest being removed
+	// first. No limit when 0.
+	MaxFiles int
+	// MaxAge is how long profiles are kept, no limit when 0.
+	MaxAge time.Duration
+}
+
+// Profile is a captured profile, in the pprof format.
+type Profile struct {
+	// Name is the name of the file in Config.Dir.
+	Name string
+	Data []byte
+}
+
+type Profiler struct {
+	cfg Config
+	// cpu is held while a CPU profile is captured, Go allowing only one at a
+	// time
+	cpu sync.Mutex
+
+	mu sync.Mutex
+	// armed are the requests to profile, by request ID
+	armed map[string]*armedRequest
+}
+
+type armedRequest struct {
+	kind Kind
+	done chan result
+}
+
+type result struct {
+	data []byte
+	err  error
+}
+
+func New(cfg Config) (*Profiler, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	p := &Profiler{cfg: cfg, armed: map[string]*armedRequest{}}
+	p.prune()
+	return p, nil
+}
+
+// Capture profiles the whole process: the CPU over d, or a snapshot of the
+// heap or the goroutines.
+func (p *Profiler) Capture(ctx context.Context, kind Kind, d time.Duration) (Profile, error) {
+	var buf bytes.Buffer
+	switch kind {
+	case KindCPU:
+		if !p.cpu.TryLock() {
+			return Profile{}, ErrBusy
+		}
+		defer p.cpu.Unlock()
+
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			return Profile{}, err
+		}
+
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			pprof.StopCPUProfile()
+			return Profile{}, ctx.Err()
+		case <-timer.C:
+			pprof.StopCPUProfile()
+		}
+	default:
+		if err := snapshot(kind, &buf); err != nil {
+			return Profile{}, err
+		}
+	}
+
+	return p.save(kind, "", buf.Bytes())
+}
+
+// CaptureRequest waits for the request with the given ID to run and profiles
+// it: the CPU while it runs, or the heap or the goroutines just before it
+// ends. The request must start after the call. The CPU profile covers the
+// whole process, its samples are labelled with the request ID (see Do).
+func (p *Profiler) CaptureRequest(ctx context.Context, kind Kind, requestID string) (Profile, error) {
+	if kind != KindCPU && kind != KindHeap && kind != KindGoroutine {
+		return Profile{}, ErrUnknown
+	}
+
+	r := &armedRequest{kind: kind, done: make(chan result, 1)}
+	p.mu.Lock()
+	if p.armed[requestID] != nil {
+		p.mu.Unlock()
+		return Profile{}, ErrArmed
+	}
+	p.armed[requestID] = r
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		if p.armed[requestID] == r {
+			delete(p.armed, requestID)
+		}
+		p.mu.Unlock()
+	}()
+
+	select {
+	case res := <-r.done:
+		if res.err != nil {
+			return Profile{}, res.err
+		}
+		return p.save(kind, requestID, res.data)
+	case <-ctx.Done():
+		return Profile{}, ctx.Err()
+	}
+}
+
+// Do runs a request, profiling it when asked by CaptureRequest. The CPU
+// samples taken while it runs are labelled with its ID and method.
+func (p *Profiler) Do(ctx context.Context, requestID, method string, f func(context.Context)) {
+	p.mu.Lock()
+	r := p.armed[requestID]
+	delete(p.armed, requestID)
+	p.mu.Unlock()
+
+	if r != nil {
+		defer p.track(r)()
+	}
+	pprof.Do(ctx, pprof.Labels("request_id", requestID, "method", method), f)
+}
+
+// track starts profiling an armed request, the returned function ends it.
+func (p *Profiler) track(r *armedRequest) func() {
+	if r.kind != KindCPU {
+		return func() {
+			var buf bytes.Buffer
+			err := snapshot(r.kind, &buf)
+			r.done <- result{data: buf.Bytes(), err: err}
+		}
+	}
+
+	if !p.cpu.TryLock() {
+		r.done <- result{err: ErrBusy}
+		return func() {}
+	}
+
+	var buf bytes.Buffer
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		p.cpu.Unlock()
+		r.done <- result{err: err}
+		return func() {}
+	}
+	return func() {
+		pprof.StopCPUProfile()
+		p.cpu.Unlock()
+		r.done <- result{data: buf.Bytes()}
+	}
+}
+
+func snapshot(kind Kind, w io.Writer) error {
+	var name string
+	switch kind {
+	case KindHeap:
+		name = "heap"
+	case KindGoroutine:
+		name = "goroutine"
+	default:
+		return ErrUnknown
+	}
+	return pprof.Lookup(name).WriteTo(w, 0)
+}
+
+// save writes the profile to Config.Dir, named after its kind, the time and
+// the request ID if any, and applies the retention limits.
+func (p *Profiler) save(kind Kind, requestID string, data []byte) (Profile, error) {
+	prefix := fmt.Sprintf("%s-%s-", kind, time.Now().UTC().Format("20060102T150405Z"))
+	if requestID != "" {
+		prefix += requestID + "-"
+	}
+
+	f, err := os.CreateTemp(p.cfg.Dir, prefix+"*"+fileSuffix)
+	if err != nil {
+		return Profile{}, err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return Profile{}, err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return Profile{}, err
+	}
+
+	p.prune()
+	return Profile{Name: filepath.Base(f.Name()), Data: data}, nil
+}
+
+// prune removes the profiles past the retention limits.
+func (p *Profiler) prune() {
+	entries, err := os.ReadDir(p.cfg.Dir)
+	if err != nil {
+		slog.Error("error listing profiles", "err", err)
+		return
+	}
+
+	type profileFile struct {
+		name    string
+		modTime time.Time
+	}
+	var files []profileFile
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !strings.HasSuffix(e.Name(), fileSuffix) {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			files = append(files, profileFile{name: e.Name(), modTime: info.ModTime()})
+		}
+	}
+
+	// newest first
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+
+	for i, f := range files {
+		tooMany := p.cfg.MaxFiles > 0 && i >= p.cfg.MaxFiles
+		tooOld := p.cfg.MaxAge > 0 && time.Since(f.modTime) > p.cfg.MaxAge
+		if tooMany || tooOld {
+			if err := os.Remove(filepath.Join(p.cfg.Dir, f.name)); err != nil {
+				slog.Error("error removing profile", "name", f.name, "err", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Package progress reports the progress of transfers, like downloads, to
+// whoever started them, through the context.
+package progress
+
+import (
+	"context"
+	"io"
+)
+
+// Func is called as a transfer goes on, with the bytes transferred so far and
+// the total, -1 when unknown. It must be safe for concurrent use.
+type Func func(done, total int64)
+
+type contextKey struct{}
+
+// NewContext returns a context whose transfers are reported to f.
+func NewContext(ctx context.Context, f Func) context.Context {
+	return context.WithValue(ctx, contextKey{}, f)
+}
+
+// Writer returns w, counting the bytes written to it when the transfers of
+// the context are reported. A transfer starting over, like a retried
+// download, takes a new writer and is reported from 0 again.
+func Writer(ctx context.Context, w io.Writer, total int64) io.Writer {
+	f, _ := ctx.Value(contextKey{}).(Func)
+	if f == nil {
+		return w
+	}
+
+	f(0, total)
+	return &writer{w: w, f: f, total: total}
+}
+
+type writer struct {
+	w     io.Writer
+	f     Func
+	done  int64
+	total int64
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.done += int64(n)
+	w.f(w.done, w.total)
+	return n, err
+}
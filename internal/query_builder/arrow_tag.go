@@ -0,0 +1,7 @@
+//go:build !duckdb_arrow
+
+package querybuilder
+
+// go-duckdb only has its Arrow interface with the duckdb_arrow build tag, as
+// in go build -tags duckdb_arrow ./...
+var _ = buildWithTheDuckDBArrowTag
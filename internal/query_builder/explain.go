@@ -0,0 +1,225 @@
+package querybuilder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Explain returns the plan DuckDB renders for the query. With analyze, the
+// query is run and the plan annotated with the rows produced and the time
+// spent by every operator.
+func (qb DuckDBQueryBuilder) Explain(ctx context.Context, query string, analyze bool) (string, error) {
+	explain := "EXPLAIN "
+	if analyze {
+		explain = "EXPLAIN ANALYZE "
+	}
+	return qb.explain(ctx, explain, query)
+}
+
+// ExplainPlan returns the plan of the query rendered by DuckDB as JSON, along
+// with its structured form. With analyze, the query is run once and the plan
+// annotated with the rows produced and the time spent by every operator.
+func (qb DuckDBQueryBuilder) ExplainPlan(ctx context.Context, query string, analyze bool) (string, *Plan, error) {
+	explain := "EXPLAIN (FORMAT JSON) "
+	if analyze {
+		explain = "EXPLAIN (ANALYZE, FORMAT JSON) "
+	}
+
+	start := time.Now()
+	text, err := qb.explain(ctx, explain, query)
+	if err != nil {
+		return "", nil, err
+	}
+	elapsed := time.Since(start).Seconds()
+
+	plan, err := ParsePlan(text, analyze)
+	if err != nil {
+		return text, nil, err
+	}
+	if analyze {
+		plan.TotalSeconds = &elapsed
+	}
+	return text, plan, nil
+}
+
+func (qb DuckDBQueryBuilder) explain(ctx context.Context, explain, query string) (string, error) {
+	if err := checkText(query); err != nil {
+		return "", err
+	}
+
+	rows, err := qb.con.QueryContext(ctx, explain+query)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var plan strings.Builder
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return "", err
+		}
+		plan.WriteString(value)
+	}
+	return plan.String(), rows.Err()
+}
+
+// Plan is the structured form of a plan rendered by ExplainPlan.
+type Plan struct {
+	// TotalSeconds is the time the query took, only set with analyze.
+	TotalSeconds *float64  `json:"total_seconds,omitempty"`
+	Root         *Operator `json:"root"`
+}
+
+// Operator is an operator of a plan, along with its inputs.
+type Operator struct {
+	Name string `json:"name"`
+	// Details are the sections of the operator, like its projections or
+	// filters, as "name: values" with the values separated by newlines.
+	Details              []string `json:"details,omitempty"`
+	EstimatedCardinality *int64   `json:"estimated_cardinality,omitempty"`
+	// Cardinality and Seconds are the rows produced and the time spent by the
+	// operator, only set with analyze.
+	Cardinality *int64      `json:"cardinality,omitempty"`
+	Seconds     *float64    `json:"seconds,omitempty"`
+	Children    []*Operator `json:"children,omitempty"`
+}
+
+// estimatedCardPattern is an estimated cardinality, like 100 or ~100.
+var estimatedCardPattern = regexp.MustCompile(`^~?(\d+)`)
+
+// jsonOperator is an operator of a plan rendered as JSON. The analyzed plans
+// name the operators and their figures differently.
+type jsonOperator struct {
+	Name         string   `json:"name"`
+	OperatorType string   `json:"operator_type"`
+	Cardinality  *int64   `json:"operator_cardinality"`
+	Timing       *float64 `json:"operator_timing"`
+	// ExtraInfo maps the sections of the operator to a value or a list of
+	// values, in the order DuckDB renders them
+	ExtraInfo json.RawMessage `json:"extra_info"`
+	Children  []jsonOperator  `json:"children"`
+}
+
+// ParsePlan parses a plan rendered by EXPLAIN (FORMAT JSON), a list of the
+// root operators of the statements, or by EXPLAIN (ANALYZE, FORMAT JSON), the
+// profile of the query.
+func ParsePlan(text string, analyze bool) (*Plan, error) {
+	var roots []jsonOperator
+	if analyze {
+		var profile jsonOperator
+		if err := json.Unmarshal([]byte(text), &profile); err != nil {
+			return nil, err
+		}
+		roots = profile.Children
+		// the profile starts with the operator running the others
+		for len(roots) == 1 && roots[0].OperatorType == "EXPLAIN_ANALYZE" {
+			roots = roots[0].Children
+		}
+	} else if err := json.Unmarshal([]byte(text), &roots); err != nil {
+		return nil, err
+	}
+	if len(roots) != 1 {
+		return nil, fmt.Errorf("%d root operators in the plan, want 1", len(roots))
+	}
+
+	root, err := roots[0].operator(analyze)
+	if err != nil {
+		return nil, err
+	}
+	return &Plan{Root: root}, nil
+}
+
+func (j jsonOperator) operator(analyze bool) (*Operator, error) {
+	name := j.Name
+	if analyze {
+		name = j.OperatorType
+	}
+	op := &Operator{Name: strings.TrimSpace(name)}
+	if op.Name == "" {
+		return nil, fmt.Errorf("operator without a name")
+	}
+
+	if analyze {
+		if j.Cardinality == nil || j.Timing == nil {
+			return nil, fmt.Errorf("%s has no cardinality or timing", op.Name)
+		}
+		op.Cardinality, op.Seconds = j.Cardinality, j.Timing
+	}
+
+	sections, err := extraInfo(j.ExtraInfo)
+	if err != nil {
+		return nil, fmt.Errorf("%s extra info: %w", op.Name, err)
+	}
+	for _, section := range sections {
+		if section.name == "Estimated Cardinality" && len(section.values) == 1 {
+			if m := estimatedCardPattern.FindStringSubmatch(section.values[0]); m != nil {
+				card, err := strconv.ParseInt(m[1], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("%s estimated cardinality: %w", op.Name, err)
+				}
+				op.EstimatedCardinality = &card
+				continue
+			}
+		}
+		op.Details = append(op.Details, section.name+": "+strings.Join(section.values, "\n"))
+	}
+
+	for _, child := range j.Children {
+		c, err := child.operator(analyze)
+		if err != nil {
+			return nil, err
+		}
+		op.Children = append(op.Children, c)
+	}
+	return op, nil
+}
+
+// infoSection is a section of the extra info of an operator.
+type infoSection struct {
+	name   string
+	values []string
+}
+
+// extraInfo returns the sections of the extra info of an operator in order,
+// which a map would lose.
+func extraInfo(raw json.RawMessage) ([]infoSection, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, fmt.Errorf("not an object")
+	}
+
+	var sections []infoSection
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		section := infoSection{name: t.(string)}
+
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		switch v := value.(type) {
+		case []any:
+			for _, item := range v {
+				section.values = append(section.values, fmt.Sprint(item))
+			}
+		default:
+			section.values = []string{fmt.Sprint(v)}
+		}
+		sections = append(sections, section)
+	}
+	return sections, nil
+}
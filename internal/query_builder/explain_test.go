@@ -0,0 +1,171 @@
+package querybuilder
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// names returns the operators of the tree in depth-first order, the children
+// of an operator in parentheses.
+func names(op *Operator) string {
+	if len(op.Children) == 0 {
+		return op.Name
+	}
+	children := make([]string, len(op.Children))
+	for i, child := range op.Children {
+		children[i] = names(child)
+	}
+	return op.Name + "(" + strings.Join(children, ", ") + ")"
+}
+
+// leaves returns the operators without inputs.
+func leaves(op *Operator) []*Operator {
+	if len(op.Children) == 0 {
+		return []*Operator{op}
+	}
+	var all []*Operator
+	for _, child := range op.Children {
+		all = append(all, leaves(child)...)
+	}
+	return all
+}
+
+func TestExplainPlan(t *testing.T) {
+	db := openTestDB(t)
+	qb := DuckDBQueryBuilder{con: db}
+	for _, stmt := range []string{
+		"CREATE TABLE a AS SELECT range id FROM range(100)",
+		"CREATE TABLE b AS SELECT range id FROM range(10)",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	const query = "SELECT count(*) FROM a JOIN b USING (id)"
+
+	for _, analyze := range []bool{false, true} {
+		text, plan, err := qb.ExplainPlan(context.Background(), query, analyze)
+		if err != nil {
+			t.Fatalf("ExplainPlan(analyze %t): %v\n%s", analyze, err, text)
+		}
+
+		if got := names(plan.Root); !strings.Contains(got, "HASH_JOIN(") {
+			t.Errorf("operators %s, want a hash join", got)
+		}
+		if (plan.TotalSeconds != nil) != analyze {
+			t.Errorf("total seconds %v with analyze %t", plan.TotalSeconds, analyze)
+		}
+
+		scans := leaves(plan.Root)
+		if len(scans) != 2 {
+			t.Fatalf("%d leaves, want the scans of a and b", len(scans))
+		}
+		for i, table := range []string{"a", "b"} {
+			scan := scans[i]
+			if !strings.HasSuffix(scan.Name, "SCAN") || !strings.Contains(strings.Join(scan.Details, "\n"), table) {
+				t.Errorf("leaf %s %q, want the scan of %s", scan.Name, scan.Details, table)
+			}
+			if scan.EstimatedCardinality == nil {
+				t.Errorf("no estimated cardinality for the scan of %s", table)
+			}
+			if analyze && (scan.Cardinality == nil || scan.Seconds == nil) {
+				t.Errorf("no cardinality or timing for the scan of %s", table)
+			}
+		}
+	}
+
+	// the text plan is still rendered
+	text, err := qb.Explain(context.Background(), query, false)
+	if err != nil || !strings.Contains(text, "HASH_JOIN") {
+		t.Errorf("Explain = %q, %v", text, err)
+	}
+}
+
+func TestParsePlan(t *testing.T) {
+	const explained = `[
+	{
+		"name": "PROJECTION",
+		"children": [
+			{
+				"name": "HASH_JOIN",
+				"children": [
+					{"name": "SEQ_SCAN ", "children": [], "extra_info": {"Table": "a", "Estimated Cardinality": "100"}},
+					{"name": "SEQ_SCAN ", "children": [], "extra_info": {"Table": "b", "Estimated Cardinality": "~10"}}
+				],
+				"extra_info": {"Join Type": "INNER", "Conditions": "id = id"}
+			}
+		],
+		"extra_info": {"Projections": ["#0", "#1"], "Estimated Cardinality": "100"}
+	}
+]`
+
+	plan, err := ParsePlan(explained, false)
+	if err != nil {
+		t.Fatalf("ParsePlan: %v", err)
+	}
+	if got := names(plan.Root); got != "PROJECTION(HASH_JOIN(SEQ_SCAN, SEQ_SCAN))" {
+		t.Errorf("operators %s", got)
+	}
+	if details := plan.Root.Details; len(details) != 1 || details[0] != "Projections: #0\n#1" {
+		t.Errorf("details %q", details)
+	}
+	join := plan.Root.Children[0]
+	if want := []string{"Join Type: INNER", "Conditions: id = id"}; strings.Join(join.Details, "|") != strings.Join(want, "|") {
+		t.Errorf("details %q, want %q", join.Details, want)
+	}
+	if join.EstimatedCardinality != nil {
+		t.Errorf("estimated cardinality %d", *join.EstimatedCardinality)
+	}
+	if ec := join.Children[1].EstimatedCardinality; ec == nil || *ec != 10 {
+		t.Errorf("estimated cardinality %v, want 10", ec)
+	}
+
+	const analyzed = `{
+	"latency": 0.0,
+	"children": [
+		{
+			"operator_type": "EXPLAIN_ANALYZE",
+			"operator_cardinality": 0,
+			"operator_timing": 0.0,
+			"children": [
+				{
+					"operator_type": "TABLE_SCAN",
+					"operator_cardinality": 42,
+					"operator_timing": 0.5,
+					"extra_info": {"Text": "a", "Estimated Cardinality": "40"},
+					"children": []
+				}
+			]
+		}
+	]
+}`
+	plan, err = ParsePlan(analyzed, true)
+	if err != nil {
+		t.Fatalf("ParsePlan(analyze): %v", err)
+	}
+	scan := plan.Root
+	if scan.Name != "TABLE_SCAN" || *scan.Cardinality != 42 || *scan.Seconds != 0.5 || *scan.EstimatedCardinality != 40 {
+		t.Errorf("root %+v, want the scan", scan)
+	}
+
+	tests := []struct {
+		text    string
+		analyze bool
+	}{
+		{"", false},
+		{"{}", false},
+		{"[]", false},
+		{`[{"name": "A"}, {"name": "B"}]`, false},
+		{`[{"name": ""}]`, false},
+		{`[{"name": "A", "extra_info": []}]`, false},
+		{`[{"name": "A"}]`, true},
+		{`{"children": []}`, true},
+		{`{"children": [{"operator_type": "TABLE_SCAN"}]}`, true},
+	}
+	for _, tt := range tests {
+		if _, err := ParsePlan(tt.text, tt.analyze); err == nil {
+			t.Errorf("ParsePlan(%q, %t) succeeded", tt.text, tt.analyze)
+		}
+	}
+}
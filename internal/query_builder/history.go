@@ -0,0 +1,164 @@
+package querybuilder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HISTORY_TABLE records the transformations run by the server.
+const HISTORY_TABLE = "query_history"
+
+// HistoryEntry is a transformation recorded in the history table.
+type HistoryEntry struct {
+	StartedAt time.Time
+	RequestID string
+	Method    string
+	Caller    string
+	Source    string
+	// QueryHash identifies the query with its values left out, see
+	// sqlcheck.Normalize.
+	QueryHash string
+	SQL       string
+	RowsOut   int64
+	BytesOut  int64
+	// PhaseMillis is the time spent in every phase of the request.
+	PhaseMillis    map[string]float64
+	DurationMillis float64
+	// PeakMemoryBytes is the highest memory use of DuckDB seen while the
+	// request ran, other requests included.
+	PeakMemoryBytes int64
+	Status          string
+	Error           string
+	// Plan is the EXPLAIN ANALYZE output of the slow queries.
+	Plan string
+}
+
+// HistoryFilter selects history entries, empty fields match every entry.
+type HistoryFilter struct {
+	RequestID string
+	// Caller and Source match the entries containing them.
+	Caller      string
+	Source      string
+	QueryHash   string
+	Status      string
+	MinDuration time.Duration
+	Since       time.Time
+	Until       time.Time
+	// Limit caps the number of entries returned, the newest first.
+	Limit int
+}
+
+// CreateHistoryTable creates the history table if needed.
+func (qb DuckDBQueryBuilder) CreateHistoryTable() error {
+	return qb.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		started_at TIMESTAMP NOT NULL,
+		request_id VARCHAR,
+		method VARCHAR,
+		caller VARCHAR,
+		source VARCHAR,
+		query_hash VARCHAR,
+		sql VARCHAR,
+		rows_out BIGINT,
+		bytes_out BIGINT,
+		phase_ms VARCHAR,
+		duration_ms DOUBLE,
+		peak_memory_bytes BIGINT,
+		status VARCHAR,
+		error VARCHAR,
+		plan VARCHAR
+	)`, QuoteIdent(HISTORY_TABLE)))
+}
+
+// RecordHistory adds an entry to the history table, keeping the newest
+// maxRows entries when maxRows is positive.
+func (qb DuckDBQueryBuilder) RecordHistory(ctx context.Context, e HistoryEntry, maxRows int) error {
+	phases, err := json.Marshal(e.PhaseMillis)
+	if err != nil {
+		return err
+	}
+
+	_, err = qb.con.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", QuoteIdent(HISTORY_TABLE)),
+		e.StartedAt.UTC(), e.RequestID, e.Method, e.Caller, e.Source, e.QueryHash, e.SQL,
+		e.RowsOut, e.BytesOut, string(phases), e.DurationMillis, e.PeakMemoryBytes, e.Status, e.Error, e.Plan)
+	if err != nil || maxRows <= 0 {
+		return err
+	}
+
+	table := QuoteIdent(HISTORY_TABLE)
+	_, err = qb.con.ExecContext(ctx, fmt.Sprintf(
+		"DELETE FROM %s WHERE started_at < (SELECT min(started_at) FROM (SELECT started_at FROM %s ORDER BY started_at DESC LIMIT ?))",
+		table, table), maxRows)
+	return err
+}
+
+// History returns the entries matching the filter, the newest first.
+func (qb DuckDBQueryBuilder) History(ctx context.Context, f HistoryFilter) ([]HistoryEntry, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	add := func(cond string, arg any) {
+		conds = append(conds, cond)
+		args = append(args, arg)
+	}
+
+	if f.RequestID != "" {
+		add("request_id = ?", f.RequestID)
+	}
+	if f.Caller != "" {
+		add("contains(caller, ?)", f.Caller)
+	}
+	if f.Source != "" {
+		add("contains(source, ?)", f.Source)
+	}
+	if f.QueryHash != "" {
+		add("query_hash = ?", f.QueryHash)
+	}
+	if f.Status != "" {
+		add("status = ?", f.Status)
+	}
+	if f.MinDuration > 0 {
+		add("duration_ms >= ?", float64(f.MinDuration)/float64(time.Millisecond))
+	}
+	if !f.Since.IsZero() {
+		add("started_at >= ?", f.Since.UTC())
+	}
+	if !f.Until.IsZero() {
+		add("started_at < ?", f.Until.UTC())
+	}
+
+	query := fmt.Sprintf(`SELECT started_at, request_id, method, caller, source, query_hash, sql, rows_out,
+		bytes_out, phase_ms, duration_ms, peak_memory_bytes, status, error, plan FROM %s`, QuoteIdent(HISTORY_TABLE))
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY started_at DESC LIMIT ?"
+	args = append(args, f.Limit)
+
+	rows, err := qb.con.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []HistoryEntry
+	for rows.Next() {
+		var (
+			e      HistoryEntry
+			phases string
+		)
+		err := rows.Scan(&e.StartedAt, &e.RequestID, &e.Method, &e.Caller, &e.Source, &e.QueryHash, &e.SQL, &e.RowsOut,
+			&e.BytesOut, &phases, &e.DurationMillis, &e.PeakMemoryBytes, &e.Status, &e.Error, &e.Plan)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(phases), &e.PhaseMillis); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
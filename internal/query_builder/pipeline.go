@@ -0,0 +1,699 @@
+package querybuilder
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Pipeline is a declarative description of a transformation. It is compiled
+// to a chain of CTEs where every step reads from the previous one, the first
+// step reading from the source relation.
+type Pipeline struct {
+	Steps []Step `json:"steps"`
+}
+
+// Step holds exactly one of the step kinds.
+type Step struct {
+	Select       *SelectStep       `json:"select,omitempty"`
+	Rename       *RenameStep       `json:"rename,omitempty"`
+	FillNulls    *FillNullsStep    `json:"fill_nulls,omitempty"`
+	Filter       *FilterStep       `json:"filter,omitempty"`
+	Derive       *DeriveStep       `json:"derive,omitempty"`
+	Aggregate    *AggregateStep    `json:"aggregate,omitempty"`
+	GroupingSets *GroupingSetsStep `json:"grouping_sets,omitempty"`
+	Sort         *SortStep         `json:"sort,omitempty"`
+	Limit        *LimitStep        `json:"limit,omitempty"`
+	Subtotal     *SubtotalStep     `json:"subtotal,omitempty"`
+}
+
+// SelectStep keeps the listed columns, in that order.
+type SelectStep struct {
+	Columns []string `json:"columns"`
+}
+
+type Rename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// RenameStep renames columns and keeps every other column as is.
+type RenameStep struct {
+	Columns []Rename `json:"columns"`
+}
+
+// FillNullsStep replaces NULLs of the listed columns, or of every VARCHAR
+// column when none are listed, with Value. Value is cast to the type of the
+// column when it is known, DuckDB casts it implicitly otherwise.
+type FillNullsStep struct {
+	Columns []string `json:"columns"`
+	Value   string   `json:"value"`
+}
+
+type Condition struct {
+	Column string   `json:"column"`
+	Op     string   `json:"op"`
+	Values []string `json:"values"`
+}
+
+// FilterStep keeps the rows matching all the conditions, or any of them when
+// Any is set.
+type FilterStep struct {
+	Conditions []Condition `json:"conditions"`
+	Any        bool        `json:"any"`
+}
+
+// Operand is either a column reference or a literal.
+type Operand struct {
+	Column  string  `json:"column,omitempty"`
+	Literal *string `json:"literal,omitempty"`
+}
+
+// DeriveStep adds (or replaces) the column Name computed by applying Function
+// to Args.
+type DeriveStep struct {
+	Name     string    `json:"name"`
+	Function string    `json:"function"`
+	Args     []Operand `json:"args"`
+}
+
+type Measure struct {
+	Column   string `json:"column"`
+	Function string `json:"function"`
+	Alias    string `json:"alias"`
+}
+
+// AggregateStep groups by GroupBy and computes Measures.
+type AggregateStep struct {
+	GroupBy  []string  `json:"group_by"`
+	Measures []Measure `json:"measures"`
+}
+
+type GroupingSet struct {
+	Columns []string `json:"columns"`
+}
+
+// GroupingSetsStep computes Measures over every grouping set. The output has
+// a column for every column used in any of the sets.
+type GroupingSetsStep struct {
+	Sets     []GroupingSet `json:"sets"`
+	Measures []Measure     `json:"measures"`
+}
+
+type SortKey struct {
+	Column     string `json:"column"`
+	Descending bool   `json:"descending"`
+	NullsFirst bool   `json:"nulls_first"`
+}
+
+type SortStep struct {
+	Keys []SortKey `json:"keys"`
+}
+
+// LimitStep keeps Count rows after skipping Offset rows, every remaining row
+// when Count is 0. Like in the protobuf JSON mapping the values may be given
+// as strings.
+type LimitStep struct {
+	Count  int64 `json:"count,string"`
+	Offset int64 `json:"offset,string"`
+}
+
+// Column is a column of a relation as reported by DESCRIBE. Type is empty when
+// it can't be known before running the query, for instance for the result of
+// an arithmetic operation.
+type Column struct {
+	Name string
+	Type string
+}
+
+// ErrInvalidPipeline wraps every validation error returned by Compile.
+var ErrInvalidPipeline = errors.New("invalid pipeline")
+
+// filterOps maps the filter operators to their SQL form and number of values,
+// -1 meaning at least one.
+var filterOps = map[string]struct {
+	sql   string
+	arity int
+}{
+	"eq":          {"=", 1},
+	"ne":          {"<>", 1},
+	"lt":          {"<", 1},
+	"le":          {"<=", 1},
+	"gt":          {">", 1},
+	"ge":          {">=", 1},
+	"like":        {"LIKE", 1},
+	"not_like":    {"NOT LIKE", 1},
+	"ilike":       {"ILIKE", 1},
+	"in":          {"IN", -1},
+	"not_in":      {"NOT IN", -1},
+	"between":     {"BETWEEN", 2},
+	"is_null":     {"IS NULL", 0},
+	"is_not_null": {"IS NOT NULL", 0},
+}
+
+// deriveFuncs lists the functions a derive step may use with their minimum
+// and maximum number of arguments, -1 meaning unbounded.
+var deriveFuncs = map[string][2]int{
+	"upper":      {1, 1},
+	"lower":      {1, 1},
+	"trim":       {1, 1},
+	"length":     {1, 1},
+	"abs":        {1, 1},
+	"round":      {1, 2},
+	"floor":      {1, 1},
+	"ceil":       {1, 1},
+	"year":       {1, 1},
+	"month":      {1, 1},
+	"day":        {1, 1},
+	"date_trunc": {2, 2},
+	"substring":  {2, 3},
+	"replace":    {3, 3},
+	"concat":     {1, -1},
+	"coalesce":   {1, -1},
+	"add":        {2, 2},
+	"subtract":   {2, 2},
+	"multiply":   {2, 2},
+	"divide":     {2, 2},
+}
+
+// deriveTypes maps the functions with a fixed result type to that type. abs
+// and coalesce return the type of their first argument, the type of the other
+// functions depends on their arguments in ways not worth replicating.
+var deriveTypes = map[string]string{
+	"upper":     "VARCHAR",
+	"lower":     "VARCHAR",
+	"trim":      "VARCHAR",
+	"substring": "VARCHAR",
+	"replace":   "VARCHAR",
+	"concat":    "VARCHAR",
+	"length":    "BIGINT",
+	"year":      "BIGINT",
+	"month":     "BIGINT",
+	"day":       "BIGINT",
+}
+
+var arithmeticOps = map[string]string{
+	"add":      "+",
+	"subtract": "-",
+	"multiply": "*",
+	"divide":   "/",
+}
+
+// aggregateFuncs lists the measure functions and whether they need a numeric
+// column.
+var aggregateFuncs = map[string]bool{
+	"count":          false,
+	"count_distinct": false,
+	"min":            false,
+	"max":            false,
+	"any_value":      false,
+	"sum":            true,
+	"avg":            true,
+	"median":         true,
+}
+
+// Compile validates the pipeline against the schema of source and returns the
+// SQL computing it.
+func (p Pipeline) Compile(source string, schema []Column) (string, error) {
+	query, _, err := p.compile(source, schema)
+	return query, err
+}
+
+// compile also returns the schema of the result.
+func (p Pipeline) compile(source string, schema []Column) (string, []Column, error) {
+	if len(p.Steps) == 0 {
+		return "", nil, fmt.Errorf("%w: no steps", ErrInvalidPipeline)
+	}
+
+	ctes := []string{fmt.Sprintf("step_0 AS (SELECT * FROM %s)", QuoteIdent(source))}
+	for i, step := range p.Steps {
+		prev := fmt.Sprintf("step_%d", i)
+
+		body, next, err := step.compile(prev, schema)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: step %d: %v", ErrInvalidPipeline, i+1, err)
+		}
+
+		ctes = append(ctes, fmt.Sprintf("step_%d AS (%s)", i+1, body))
+		schema = next
+	}
+
+	return fmt.Sprintf("WITH %s SELECT * FROM step_%d", strings.Join(ctes, ", "), len(p.Steps)), schema, nil
+}
+
+func (s Step) compile(from string, schema []Column) (string, []Column, error) {
+	var (
+		kinds int
+		body  string
+		next  []Column
+		err   error
+	)
+
+	if s.Select != nil {
+		kinds++
+		body, next, err = s.Select.compile(from, schema)
+	}
+	if s.Rename != nil {
+		kinds++
+		body, next, err = s.Rename.compile(from, schema)
+	}
+	if s.FillNulls != nil {
+		kinds++
+		body, next, err = s.FillNulls.compile(from, schema)
+	}
+	if s.Filter != nil {
+		kinds++
+		body, next, err = s.Filter.compile(from, schema)
+	}
+	if s.Derive != nil {
+		kinds++
+		body, next, err = s.Derive.compile(from, schema)
+	}
+	if s.Aggregate != nil {
+		kinds++
+		body, next, err = s.Aggregate.compile(from, schema)
+	}
+	if s.GroupingSets != nil {
+		kinds++
+		body, next, err = s.GroupingSets.compile(from, schema)
+	}
+	if s.Sort != nil {
+		kinds++
+		body, next, err = s.Sort.compile(from, schema)
+	}
+	if s.Limit != nil {
+		kinds++
+		body, next, err = s.Limit.compile(from, schema)
+	}
+	if s.Subtotal != nil {
+		kinds++
+		body, next, err = s.Subtotal.compile(from, schema)
+	}
+
+	if kinds != 1 {
+		return "", nil, fmt.Errorf("expected exactly one step kind, got %d", kinds)
+	}
+	return body, next, err
+}
+
+func (s SelectStep) compile(from string, schema []Column) (string, []Column, error) {
+	if len(s.Columns) == 0 {
+		return "", nil, errors.New("select: no columns")
+	}
+
+	next, err := groupColumns(schema, s.Columns)
+	if err != nil {
+		return "", nil, fmt.Errorf("select: %w", err)
+	}
+
+	return fmt.Sprintf("SELECT %s FROM %s", identList(columnNames(next)), from), next, nil
+}
+
+func (s RenameStep) compile(from string, schema []Column) (string, []Column, error) {
+	renames := make(map[string]string, len(s.Columns))
+	for _, r := range s.Columns {
+		col, err := lookup(schema, r.From)
+		if err != nil {
+			return "", nil, fmt.Errorf("rename: %w", err)
+		}
+		if r.To == "" {
+			return "", nil, fmt.Errorf("rename: empty name for column %q", r.From)
+		}
+		renames[col.Name] = r.To
+	}
+
+	exprs := make([]string, 0, len(schema))
+	next := make([]Column, 0, len(schema))
+	for _, col := range schema {
+		name := col.Name
+		if to, ok := renames[col.Name]; ok {
+			name = to
+		}
+		if _, err := lookup(next, name); err == nil {
+			return "", nil, fmt.Errorf("rename: duplicate column %q", name)
+		}
+
+		exprs = append(exprs, fmt.Sprintf("%s AS %s", QuoteIdent(col.Name), QuoteIdent(name)))
+		next = append(next, Column{Name: name, Type: col.Type})
+	}
+
+	return fmt.Sprintf("SELECT %s FROM %s", strings.Join(exprs, ", "), from), next, nil
+}
+
+func (s FillNullsStep) compile(from string, schema []Column) (string, []Column, error) {
+	fill := map[string]bool{}
+	for _, name := range s.Columns {
+		col, err := lookup(schema, name)
+		if err != nil {
+			return "", nil, fmt.Errorf("fill_nulls: %w", err)
+		}
+		fill[col.Name] = true
+	}
+
+	exprs := make([]string, 0, len(schema))
+	for _, col := range schema {
+		if fill[col.Name] || (len(s.Columns) == 0 && strings.EqualFold(col.Type, "VARCHAR")) {
+			value := QuoteLiteral(s.Value)
+			if col.Type != "" {
+				value = fmt.Sprintf("CAST(%s AS %s)", value, col.Type)
+			}
+			exprs = append(exprs, fmt.Sprintf("COALESCE(%[1]s, %[2]s) AS %[1]s", QuoteIdent(col.Name), value))
+			continue
+		}
+		exprs = append(exprs, QuoteIdent(col.Name))
+	}
+
+	return fmt.Sprintf("SELECT %s FROM %s", strings.Join(exprs, ", "), from), schema, nil
+}
+
+func (s FilterStep) compile(from string, schema []Column) (string, []Column, error) {
+	if len(s.Conditions) == 0 {
+		return "", nil, errors.New("filter: no conditions")
+	}
+
+	preds := make([]string, 0, len(s.Conditions))
+	for _, c := range s.Conditions {
+		column, err := lookup(schema, c.Column)
+		if err != nil {
+			return "", nil, fmt.Errorf("filter: %w", err)
+		}
+
+		op, ok := filterOps[c.Op]
+		if !ok {
+			return "", nil, fmt.Errorf("filter: unknown operator %q", c.Op)
+		}
+		if (op.arity == -1 && len(c.Values) == 0) || (op.arity >= 0 && len(c.Values) != op.arity) {
+			return "", nil, fmt.Errorf("filter: wrong number of values for %q on column %q", c.Op, c.Column)
+		}
+
+		col := QuoteIdent(column.Name)
+		switch {
+		case op.arity == 0:
+			preds = append(preds, fmt.Sprintf("%s %s", col, op.sql))
+		case op.arity == -1:
+			preds = append(preds, fmt.Sprintf("%s %s (%s)", col, op.sql, literalList(c.Values)))
+		case c.Op == "between":
+			preds = append(preds, fmt.Sprintf("%s BETWEEN %s AND %s", col, QuoteLiteral(c.Values[0]), QuoteLiteral(c.Values[1])))
+		default:
+			preds = append(preds, fmt.Sprintf("%s %s %s", col, op.sql, QuoteLiteral(c.Values[0])))
+		}
+	}
+
+	sep := " AND "
+	if s.Any {
+		sep = " OR "
+	}
+	return fmt.Sprintf("SELECT * FROM %s WHERE (%s)", from, strings.Join(preds, sep)), schema, nil
+}
+
+func (s DeriveStep) compile(from string, schema []Column) (string, []Column, error) {
+	if s.Name == "" {
+		return "", nil, errors.New("derive: empty column name")
+	}
+
+	arity, ok := deriveFuncs[s.Function]
+	if !ok {
+		return "", nil, fmt.Errorf("derive: unknown function %q", s.Function)
+	}
+	if len(s.Args) < arity[0] || (arity[1] >= 0 && len(s.Args) > arity[1]) {
+		return "", nil, fmt.Errorf("derive: wrong number of arguments for %q", s.Function)
+	}
+
+	args := make([]string, 0, len(s.Args))
+	argTypes := make([]string, 0, len(s.Args))
+	for _, arg := range s.Args {
+		switch {
+		case arg.Literal != nil && arg.Column == "":
+			args = append(args, QuoteLiteral(*arg.Literal))
+			argTypes = append(argTypes, "")
+		case arg.Literal == nil && arg.Column != "":
+			col, err := lookup(schema, arg.Column)
+			if err != nil {
+				return "", nil, fmt.Errorf("derive: %w", err)
+			}
+			args = append(args, QuoteIdent(col.Name))
+			argTypes = append(argTypes, col.Type)
+		default:
+			return "", nil, errors.New("derive: an argument must be either a column or a literal")
+		}
+	}
+
+	typ := deriveTypes[s.Function]
+	if s.Function == "abs" || s.Function == "coalesce" {
+		typ = argTypes[0]
+	}
+
+	var expr string
+	if op, ok := arithmeticOps[s.Function]; ok {
+		expr = fmt.Sprintf("(%s %s %s)", args[0], op, args[1])
+	} else {
+		expr = fmt.Sprintf("%s(%s)", s.Function, strings.Join(args, ", "))
+	}
+
+	// the derived column replaces an existing column with the same name
+	exprs := make([]string, 0, len(schema)+1)
+	next := make([]Column, 0, len(schema)+1)
+	for _, col := range schema {
+		if strings.EqualFold(col.Name, s.Name) {
+			continue
+		}
+		exprs = append(exprs, QuoteIdent(col.Name))
+		next = append(next, col)
+	}
+	exprs = append(exprs, fmt.Sprintf("%s AS %s", expr, QuoteIdent(s.Name)))
+	next = append(next, Column{Name: s.Name, Type: typ})
+
+	return fmt.Sprintf("SELECT %s FROM %s", strings.Join(exprs, ", "), from), next, nil
+}
+
+func (s AggregateStep) compile(from string, schema []Column) (string, []Column, error) {
+	next, err := groupColumns(schema, s.GroupBy)
+	if err != nil {
+		return "", nil, fmt.Errorf("aggregate: %w", err)
+	}
+
+	measures, measureCols, err := compileMeasures(schema, next, s.Measures)
+	if err != nil {
+		return "", nil, fmt.Errorf("aggregate: %w", err)
+	}
+
+	groupBy := columnNames(next)
+	exprs := append(quoteIdents(groupBy), measures...)
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(exprs, ", "), from)
+	if len(groupBy) > 0 {
+		query += fmt.Sprintf(" GROUP BY %s", identList(groupBy))
+	}
+
+	return query, append(next, measureCols...), nil
+}
+
+func (s GroupingSetsStep) compile(from string, schema []Column) (string, []Column, error) {
+	if len(s.Sets) == 0 {
+		return "", nil, errors.New("grouping_sets: no sets")
+	}
+
+	// the output has a column for every column used by any set, in order of
+	// first use
+	var next []Column
+	resolved := make([]GroupingSet, 0, len(s.Sets))
+	for _, set := range s.Sets {
+		cols, err := groupColumns(schema, set.Columns)
+		if err != nil {
+			return "", nil, fmt.Errorf("grouping_sets: %w", err)
+		}
+		for _, col := range cols {
+			if _, err := lookup(next, col.Name); err != nil {
+				next = append(next, col)
+			}
+		}
+		resolved = append(resolved, GroupingSet{Columns: columnNames(cols)})
+	}
+
+	measures, measureCols, err := compileMeasures(schema, next, s.Measures)
+	if err != nil {
+		return "", nil, fmt.Errorf("grouping_sets: %w", err)
+	}
+
+	sets := make([]string, 0, len(s.Sets))
+	for _, set := range DedupGroupingSets(resolved) {
+		sets = append(sets, fmt.Sprintf("(%s)", identList(set.Columns)))
+	}
+
+	exprs := append(quoteIdents(columnNames(next)), measures...)
+	query := fmt.Sprintf("SELECT %s FROM %s GROUP BY GROUPING SETS (%s)", strings.Join(exprs, ", "), from, strings.Join(sets, ", "))
+	return query, append(next, measureCols...), nil
+}
+
+func (s SortStep) compile(from string, schema []Column) (string, []Column, error) {
+	if len(s.Keys) == 0 {
+		return "", nil, errors.New("sort: no keys")
+	}
+
+	keys := make([]string, 0, len(s.Keys))
+	for _, k := range s.Keys {
+		col, err := lookup(schema, k.Column)
+		if err != nil {
+			return "", nil, fmt.Errorf("sort: %w", err)
+		}
+
+		key := QuoteIdent(col.Name)
+		if k.Descending {
+			key += " DESC"
+		} else {
+			key += " ASC"
+		}
+		if k.NullsFirst {
+			key += " NULLS FIRST"
+		} else {
+			key += " NULLS LAST"
+		}
+		keys = append(keys, key)
+	}
+
+	return fmt.Sprintf("SELECT * FROM %s ORDER BY %s", from, strings.Join(keys, ", ")), schema, nil
+}
+
+func (s LimitStep) compile(from string, schema []Column) (string, []Column, error) {
+	if s.Count < 0 || s.Offset < 0 {
+		return "", nil, errors.New("limit: count and offset must not be negative")
+	}
+
+	if s.Count == 0 {
+		return fmt.Sprintf("SELECT * FROM %s OFFSET %d", from, s.Offset), schema, nil
+	}
+	return fmt.Sprintf("SELECT * FROM %s LIMIT %d OFFSET %d", from, s.Count, s.Offset), schema, nil
+}
+
+// DedupGroupingSets drops the sets that group by the same columns as an
+// earlier set, the order of the columns within a set being irrelevant.
+func DedupGroupingSets(sets []GroupingSet) []GroupingSet {
+	seen := map[string]bool{}
+	out := make([]GroupingSet, 0, len(sets))
+	for _, set := range sets {
+		cols := slices.Clone(set.Columns)
+		sort.Strings(cols)
+		cols = slices.Compact(cols)
+
+		key := strings.Join(cols, "\x00")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, set)
+	}
+	return out
+}
+
+func groupColumns(schema []Column, names []string) ([]Column, error) {
+	cols := make([]Column, 0, len(names))
+	for _, name := range names {
+		col, err := lookup(schema, name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := lookup(cols, name); err == nil {
+			return nil, fmt.Errorf("duplicate column %q", name)
+		}
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
+
+func compileMeasures(schema, groups []Column, measures []Measure) ([]string, []Column, error) {
+	if len(measures) == 0 && len(groups) == 0 {
+		return nil, nil, errors.New("no group columns or measures")
+	}
+
+	exprs := make([]string, 0, len(measures))
+	cols := make([]Column, 0, len(measures))
+	for _, m := range measures {
+		numeric, ok := aggregateFuncs[m.Function]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown aggregate function %q", m.Function)
+		}
+
+		var arg, typ string
+		if m.Column == "*" && m.Function == "count" {
+			arg = "*"
+		} else {
+			col, err := lookup(schema, m.Column)
+			if err != nil {
+				return nil, nil, err
+			}
+			if numeric && col.Type != "" && !IsNumericType(col.Type) {
+				return nil, nil, fmt.Errorf("%s of non numeric column %q (%s)", m.Function, col.Name, col.Type)
+			}
+			arg = QuoteIdent(col.Name)
+			typ = col.Type
+		}
+
+		alias := m.Alias
+		if alias == "" {
+			alias = m.Column
+			if alias == "*" {
+				alias = m.Function
+			}
+		}
+		if _, err := lookup(groups, alias); err == nil {
+			return nil, nil, fmt.Errorf("measure %q clashes with a group column", alias)
+		}
+		if _, err := lookup(cols, alias); err == nil {
+			return nil, nil, fmt.Errorf("duplicate measure %q", alias)
+		}
+
+		if m.Function == "count_distinct" {
+			exprs = append(exprs, fmt.Sprintf("count(DISTINCT %s) AS %s", arg, QuoteIdent(alias)))
+		} else {
+			exprs = append(exprs, fmt.Sprintf("%s(%s) AS %s", m.Function, arg, QuoteIdent(alias)))
+		}
+		cols = append(cols, Column{Name: alias, Type: measureType(m.Function, typ)})
+	}
+
+	return exprs, cols, nil
+}
+
+// measureType returns the type of a measure over a column of type typ, or an
+// empty string when it depends on more than the function.
+func measureType(function, typ string) string {
+	switch function {
+	case "count", "count_distinct":
+		return "BIGINT"
+	case "min", "max", "any_value":
+		return typ
+	case "avg":
+		return "DOUBLE"
+	}
+	return ""
+}
+
+// IsNumericType reports whether the DuckDB type is an integer, floating point
+// or decimal type.
+func IsNumericType(typ string) bool {
+	switch strings.ToUpper(typ) {
+	case "TINYINT", "SMALLINT", "INTEGER", "BIGINT", "HUGEINT",
+		"UTINYINT", "USMALLINT", "UINTEGER", "UBIGINT", "UHUGEINT",
+		"FLOAT", "DOUBLE":
+		return true
+	}
+	return strings.HasPrefix(strings.ToUpper(typ), "DECIMAL")
+}
+
+// lookup finds a column by name, ignoring case like DuckDB does.
+func lookup(schema []Column, name string) (Column, error) {
+	for _, col := range schema {
+		if strings.EqualFold(col.Name, name) {
+			return col, nil
+		}
+	}
+	return Column{}, fmt.Errorf("unknown column %q", name)
+}
+
+func columnNames(cols []Column) []string {
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		names[i] = col.Name
+	}
+	return names
+}
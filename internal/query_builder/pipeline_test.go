@@ -0,0 +1,277 @@
+package querybuilder
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// peopleTable is the source relation of the pipeline tests.
+const peopleTable = `CREATE TABLE people AS SELECT * FROM (VALUES
+	(1, 'Ada', 'Paris', 10.50::DECIMAL(10,2), DATE '2024-01-15'),
+	(2, NULL, 'Paris', 4.25, DATE '2024-02-01'),
+	(3, 'Bob', NULL, NULL, NULL),
+	(4, 'Cy', 'Oslo', 1.00, DATE '2023-12-31')
+) t(id, name, city, amount, joined)`
+
+func openPeople(t *testing.T) (DuckDBQueryBuilder, []Column) {
+	t.Helper()
+
+	qb := DuckDBQueryBuilder{con: openTestDB(t)}
+	if err := qb.Exec(peopleTable); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := qb.Describe("people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return qb, schema
+}
+
+// queryRows returns the rows of a query as DuckDB prints structs.
+func queryRows(t *testing.T, db *sql.DB, query string) []string {
+	t.Helper()
+
+	rows, err := db.Query(fmt.Sprintf("SELECT CAST(r AS VARCHAR) FROM (%s) r", query))
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var row string
+		if err := rows.Scan(&row); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, row)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// checkSchema compares the schema tracked by the compiler with the one of the
+// result, the types the compiler doesn't know being skipped.
+func checkSchema(t *testing.T, qb DuckDBQueryBuilder, query string, tracked []Column) {
+	t.Helper()
+
+	if err := qb.Exec(fmt.Sprintf("CREATE OR REPLACE TEMP VIEW result AS %s", query)); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := qb.Describe("result")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(actual) != len(tracked) {
+		t.Fatalf("tracked columns %v, want %v", tracked, actual)
+	}
+	for i, col := range tracked {
+		if col.Name != actual[i].Name || (col.Type != "" && col.Type != actual[i].Type) {
+			t.Errorf("tracked column %d is %v, want %v", i, col, actual[i])
+		}
+	}
+}
+
+func TestPipelineCompile(t *testing.T) {
+	qb, schema := openPeople(t)
+	literal := func(s string) *string { return &s }
+	byID := Step{Sort: &SortStep{Keys: []SortKey{{Column: "id"}}}}
+
+	tests := []struct {
+		name  string
+		steps []Step
+		rows  []string
+	}{
+		{
+			name: "select ignores the case of names",
+			steps: []Step{
+				{Select: &SelectStep{Columns: []string{"NAME", "Id"}}},
+				byID,
+			},
+			rows: []string{"{'name': Ada, 'id': 1}", "{'name': NULL, 'id': 2}", "{'name': Bob, 'id': 3}", "{'name': Cy, 'id': 4}"},
+		},
+		{
+			name: "rename",
+			steps: []Step{
+				{Rename: &RenameStep{Columns: []Rename{{From: "ID", To: "key"}}}},
+				{Select: &SelectStep{Columns: []string{"key", "city"}}},
+				{Sort: &SortStep{Keys: []SortKey{{Column: "key", Descending: true}}}},
+				{Limit: &LimitStep{Count: 2}},
+			},
+			rows: []string{"{'key': 4, 'city': Oslo}", "{'key': 3, 'city': NULL}"},
+		},
+		{
+			name: "offset without limit",
+			steps: []Step{
+				{Select: &SelectStep{Columns: []string{"id"}}},
+				byID,
+				{Limit: &LimitStep{Offset: 2}},
+			},
+			rows: []string{"{'id': 3}", "{'id': 4}"},
+		},
+		{
+			name: "fill nulls of typed columns",
+			steps: []Step{
+				{FillNulls: &FillNullsStep{Columns: []string{"Amount"}, Value: "0"}},
+				{FillNulls: &FillNullsStep{Columns: []string{"joined"}, Value: "2000-01-01"}},
+				{Select: &SelectStep{Columns: []string{"id", "amount", "joined"}}},
+				{Filter: &FilterStep{Conditions: []Condition{{Column: "id", Op: "eq", Values: []string{"3"}}}}},
+			},
+			rows: []string{"{'id': 3, 'amount': 0.00, 'joined': 2000-01-01}"},
+		},
+		{
+			name: "fill nulls of every VARCHAR column",
+			steps: []Step{
+				{FillNulls: &FillNullsStep{Value: "?"}},
+				{Filter: &FilterStep{Conditions: []Condition{{Column: "id", Op: "in", Values: []string{"2", "3"}}}}},
+				byID,
+				{Select: &SelectStep{Columns: []string{"name", "city", "amount"}}},
+			},
+			rows: []string{"{'name': ?, 'city': Paris, 'amount': 4.25}", "{'name': Bob, 'city': ?, 'amount': NULL}"},
+		},
+		{
+			name: "fill nulls of a derived column",
+			steps: []Step{
+				{Derive: &DeriveStep{Name: "shout", Function: "upper", Args: []Operand{{Column: "name"}}}},
+				{Derive: &DeriveStep{Name: "total", Function: "add", Args: []Operand{{Column: "amount"}, {Literal: literal("1")}}}},
+				{FillNulls: &FillNullsStep{Columns: []string{"total"}, Value: "-1"}},
+				{FillNulls: &FillNullsStep{Value: "-"}},
+				{Select: &SelectStep{Columns: []string{"id", "shout", "total"}}},
+				byID,
+			},
+			rows: []string{"{'id': 1, 'shout': ADA, 'total': 11.5}", "{'id': 2, 'shout': -, 'total': 5.25}", "{'id': 3, 'shout': BOB, 'total': -1.0}", "{'id': 4, 'shout': CY, 'total': 2.0}"},
+		},
+		{
+			name: "derive replaces a column",
+			steps: []Step{
+				{Derive: &DeriveStep{Name: "City", Function: "coalesce", Args: []Operand{{Column: "city"}, {Literal: literal("nowhere")}}}},
+				{Select: &SelectStep{Columns: []string{"id", "city"}}},
+				{Filter: &FilterStep{Conditions: []Condition{{Column: "id", Op: "between", Values: []string{"3", "4"}}}}},
+				byID,
+			},
+			rows: []string{"{'id': 3, 'City': nowhere}", "{'id': 4, 'City': Oslo}"},
+		},
+		{
+			name: "fill nulls of a measure",
+			steps: []Step{
+				{Aggregate: &AggregateStep{GroupBy: []string{"CITY"}, Measures: []Measure{
+					{Column: "name", Function: "max", Alias: "last"},
+					{Column: "*", Function: "count"},
+					{Column: "amount", Function: "avg"},
+				}}},
+				{FillNulls: &FillNullsStep{Columns: []string{"city", "last"}, Value: "none"}},
+				{Sort: &SortStep{Keys: []SortKey{{Column: "city"}}}},
+			},
+			rows: []string{"{'city': Oslo, 'last': Cy, 'count': 1, 'amount': 1.0}", "{'city': Paris, 'last': Ada, 'count': 2, 'amount': 7.375}", "{'city': none, 'last': Bob, 'count': 1, 'amount': NULL}"},
+		},
+		{
+			name: "grouping sets",
+			steps: []Step{
+				{Derive: &DeriveStep{Name: "year", Function: "year", Args: []Operand{{Column: "joined"}}}},
+				{GroupingSets: &GroupingSetsStep{
+					Sets:     []GroupingSet{{Columns: []string{"City"}}, {Columns: []string{"city"}}, {Columns: []string{"YEAR"}}},
+					Measures: []Measure{{Column: "id", Function: "count_distinct", Alias: "people"}},
+				}},
+				{Filter: &FilterStep{Conditions: []Condition{{Column: "city", Op: "is_not_null"}, {Column: "year", Op: "is_not_null"}}, Any: true}},
+				{Sort: &SortStep{Keys: []SortKey{{Column: "city", NullsFirst: true}, {Column: "year"}}}},
+			},
+			rows: []string{"{'city': NULL, 'year': 2023, 'people': 1}", "{'city': NULL, 'year': 2024, 'people': 2}", "{'city': Oslo, 'year': NULL, 'people': 1}", "{'city': Paris, 'year': NULL, 'people': 2}"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, tracked, err := Pipeline{Steps: tt.steps}.compile("people", schema)
+			if err != nil {
+				t.Fatalf("compile: %v", err)
+			}
+
+			checkSchema(t, qb, query, tracked)
+			if rows := queryRows(t, qb.con, query); !slices.Equal(rows, tt.rows) {
+				t.Errorf("%s\nrows %q\nwant %q", query, rows, tt.rows)
+			}
+		})
+	}
+}
+
+func TestPipelineCompileErrors(t *testing.T) {
+	schema := []Column{{Name: "id", Type: "INTEGER"}, {Name: "name", Type: "VARCHAR"}}
+
+	tests := []struct {
+		name  string
+		steps []Step
+		err   string
+	}{
+		{"no steps", nil, "no steps"},
+		{"no kind", []Step{{}}, "exactly one step kind"},
+		{"two kinds", []Step{{Select: &SelectStep{Columns: []string{"id"}}, Limit: &LimitStep{Count: 1}}}, "exactly one step kind"},
+		{"unknown column", []Step{{Select: &SelectStep{Columns: []string{"missing"}}}}, `unknown column "missing"`},
+		{"duplicate column", []Step{{Select: &SelectStep{Columns: []string{"id", "ID"}}}}, `duplicate column "ID"`},
+		{"rename clash", []Step{{Rename: &RenameStep{Columns: []Rename{{From: "id", To: "Name"}}}}}, `duplicate column "name"`},
+		{"rename to nothing", []Step{{Rename: &RenameStep{Columns: []Rename{{From: "id"}}}}}, "empty name"},
+		{"unknown operator", []Step{{Filter: &FilterStep{Conditions: []Condition{{Column: "id", Op: "regexp", Values: []string{"1"}}}}}}, `unknown operator "regexp"`},
+		{"filter arity", []Step{{Filter: &FilterStep{Conditions: []Condition{{Column: "id", Op: "between", Values: []string{"1"}}}}}}, "wrong number of values"},
+		{"empty in", []Step{{Filter: &FilterStep{Conditions: []Condition{{Column: "id", Op: "in"}}}}}, "wrong number of values"},
+		{"unknown function", []Step{{Derive: &DeriveStep{Name: "x", Function: "system", Args: []Operand{{Column: "id"}}}}}, `unknown function "system"`},
+		{"derive arity", []Step{{Derive: &DeriveStep{Name: "x", Function: "upper"}}}, "wrong number of arguments"},
+		{"ambiguous operand", []Step{{Derive: &DeriveStep{Name: "x", Function: "upper", Args: []Operand{{}}}}}, "either a column or a literal"},
+		{"sum of text", []Step{{Aggregate: &AggregateStep{Measures: []Measure{{Column: "name", Function: "sum"}}}}}, "non numeric column"},
+		{"measure clash", []Step{{Aggregate: &AggregateStep{GroupBy: []string{"name"}, Measures: []Measure{{Column: "id", Function: "max", Alias: "NAME"}}}}}, "clashes with a group column"},
+		{"nothing to aggregate", []Step{{Aggregate: &AggregateStep{}}}, "no group columns or measures"},
+		{"negative limit", []Step{{Limit: &LimitStep{Count: -1}}}, "must not be negative"},
+		{"error in a later step", []Step{{Select: &SelectStep{Columns: []string{"id"}}}, {Sort: &SortStep{Keys: []SortKey{{Column: "name"}}}}}, `step 2: sort: unknown column "name"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Pipeline{Steps: tt.steps}.Compile("people", schema)
+			if !errors.Is(err, ErrInvalidPipeline) || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("Compile = %v, want an invalid pipeline error containing %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDedupGroupingSets(t *testing.T) {
+	sets := []GroupingSet{
+		{Columns: []string{"a", "b"}},
+		{Columns: []string{"b", "a"}},
+		{Columns: []string{"a"}},
+		{Columns: []string{"a", "a"}},
+		{},
+		{Columns: []string{}},
+	}
+	got := DedupGroupingSets(sets)
+	want := []GroupingSet{sets[0], sets[2], sets[4]}
+	if len(got) != len(want) {
+		t.Fatalf("DedupGroupingSets = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !slices.Equal(got[i].Columns, want[i].Columns) {
+			t.Errorf("DedupGroupingSets = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestIsNumericType(t *testing.T) {
+	for typ, want := range map[string]bool{
+		"INTEGER":        true,
+		"hugeint":        true,
+		"DOUBLE":         true,
+		"DECIMAL(10,2)":  true,
+		"VARCHAR":        false,
+		"DATE":           false,
+		"INTEGER[]":      false,
+		"STRUCT(a INT)":  false,
+		"":               false,
+		"TIMESTAMP WITH": false,
+	} {
+		if got := IsNumericType(typ); got != want {
+			t.Errorf("IsNumericType(%q) = %t, want %t", typ, got, want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package querybuilder
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"slices"
+	"unsafe"
+
+	"github.com/marcboeker/go-duckdb/mapping"
+)
+
+// ErrProgressUnsupported is returned by Progress when the driver doesn't
+// expose its connections.
+var ErrProgressUnsupported = errors.New("query progress is not supported by the driver")
+
+// progressDrivers are the go-duckdb versions whose unexported connection
+// field Progress reads, which has to be checked again on every upgrade.
+var progressDrivers = []string{"v2.1.0"}
+
+// QueryProgress is the progress of the query running on a connection.
+type QueryProgress struct {
+	// Percentage is -1 when DuckDB can't estimate it, like before the query
+	// starts or for operators that don't report their progress.
+	Percentage    float64
+	RowsProcessed uint64
+	TotalRows     uint64
+}
+
+// trackProgress makes DuckDB track the progress of the queries of a new
+// connection, without printing it. The settings are per connection and
+// PRAGMA still works once the configuration is locked, unlike SET.
+func trackProgress(execer driver.ExecerContext) error {
+	for _, pragma := range []string{"PRAGMA enable_progress_bar", "PRAGMA disable_print_progress_bar"} {
+		if _, err := execer.ExecContext(context.Background(), pragma, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkProgress returns why Progress can't read the connections of the
+// driver, nil when it can: go-duckdb must be one of progressDrivers, DuckDB
+// 0.10 or later for duckdb_query_progress, and the connection field a
+// duckdb_connection.
+func checkProgress(ctx context.Context, db *sql.DB) error {
+	version := "unknown"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path != "github.com/marcboeker/go-duckdb/v2" {
+				continue
+			}
+			version = dep.Version
+			if dep.Replace != nil {
+				version = dep.Replace.Version
+			}
+		}
+	}
+	if !slices.Contains(progressDrivers, version) {
+		return fmt.Errorf("%w: go-duckdb %s", ErrProgressUnsupported, version)
+	}
+
+	var library string
+	if err := db.QueryRowContext(ctx, "SELECT library_version FROM pragma_version()").Scan(&library); err != nil {
+		return err
+	}
+	var major, minor int
+	if _, err := fmt.Sscanf(library, "v%d.%d", &major, &minor); err != nil || major == 0 && minor < 10 {
+		return fmt.Errorf("%w: DuckDB %s", ErrProgressUnsupported, library)
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.Raw(func(dc any) error {
+		c, ok := dc.(driver.Conn)
+		if !ok {
+			return ErrProgressUnsupported
+		}
+		_, err := connectionHandle(c)
+		return err
+	})
+}
+
+// Progress returns the progress of the query running on the connection. It
+// can be called while the query runs.
+func (qb DuckDBArrowQueryBuilder) Progress() (QueryProgress, error) {
+	if qb.progress != nil {
+		return QueryProgress{}, qb.progress
+	}
+	handle, err := connectionHandle(qb.conn)
+	if err != nil {
+		return QueryProgress{}, err
+	}
+
+	p := mapping.QueryProgress(handle)
+	percentage, rows, total := mapping.QueryProgressTypeMembers(&p)
+	return QueryProgress{Percentage: percentage, RowsProcessed: rows, TotalRows: total}, nil
+}
+
+// connectionHandle returns the duckdb_connection of a go-duckdb connection,
+// which keeps it unexported.
+func connectionHandle(conn driver.Conn) (mapping.Connection, error) {
+	v := reflect.ValueOf(conn)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return mapping.Connection{}, ErrProgressUnsupported
+	}
+
+	f := v.Elem().FieldByName("conn")
+	if !f.IsValid() || f.Type() != reflect.TypeOf(mapping.Connection{}) {
+		return mapping.Connection{}, ErrProgressUnsupported
+	}
+	return *(*mapping.Connection)(unsafe.Pointer(f.UnsafeAddr())), nil
+}
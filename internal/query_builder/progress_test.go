@@ -0,0 +1,50 @@
+package querybuilder
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// fakeConn is a driver connection without a DuckDB connection.
+type fakeConn struct {
+	driver.Conn
+	duckdbCon *int
+}
+
+func TestProgress(t *testing.T) {
+	qb, err := NewDuckDBQueryBuilder(filepath.Join(t.TempDir(), "data.duckdb"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer qb.Close()
+	if qb.progress != nil {
+		t.Fatalf("progress disabled: %v", qb.progress)
+	}
+
+	arrowQB, err := qb.GetArrow(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer arrowQB.Close()
+	if p, err := arrowQB.Progress(); err != nil || p.Percentage != -1 {
+		t.Errorf("Progress = %+v, %v, want -1 while idle", p, err)
+	}
+}
+
+func TestCheckProgress(t *testing.T) {
+	db := openTestDB(t)
+
+	drivers := progressDrivers
+	t.Cleanup(func() { progressDrivers = drivers })
+	progressDrivers = []string{"v0.0.1"}
+	if err := checkProgress(context.Background(), db); !errors.Is(err, ErrProgressUnsupported) {
+		t.Errorf("checkProgress = %v with another driver version, want ErrProgressUnsupported", err)
+	}
+
+	if _, err := connectionHandle(&fakeConn{}); !errors.Is(err, ErrProgressUnsupported) {
+		t.Errorf("connectionHandle = %v for another connection type, want ErrProgressUnsupported", err)
+	}
+}
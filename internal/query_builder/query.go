@@ -3,46 +3,177 @@ package querybuilder
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 
-	"github.com/marcboeker/go-duckdb"
+	"github.com/marcboeker/go-duckdb/v2"
 )
 
 const (
-	DEFAULT_PATH = "./data.duckdb"
+	DEFAULT_PATH           = "./data.duckdb"
+	DEFAULT_TEMP_DIRECTORY = "/tmp/duckdb_tmp"
+	// PARQUET_KEY_NAME is the key the footer of the Parquet exports is
+	// encrypted with.
+	PARQUET_KEY_NAME = "key256"
 )
 
+// ErrSandboxUnsupported is returned by NewDuckDBQueryBuilder when the allowed
+// directories are set but DuckDB, before 1.1, can't restrict them.
+var ErrSandboxUnsupported = errors.New("allowed_directories requires DuckDB 1.1 or later")
+
+type Options struct {
+	// AllowedDirectories restricts the files queries can access, on top of the
+	// database and temp directory. Every file is accessible when empty.
+	AllowedDirectories []string
+	// LockConfiguration stops queries from changing the settings once the
+	// database is configured.
+	LockConfiguration bool
+	// MemoryLimit, like 2GB, and Threads are the resources of the database,
+	// the defaults of DuckDB being kept when empty.
+	MemoryLimit string
+	Threads     int
+}
+
 type DuckDBQueryBuilder struct {
 	con       *sql.DB
 	connector *duckdb.Connector
+	// progress is why query progress is disabled, nil when it isn't
+	progress error
 }
 
-func NewDuckDBQueryBuilder(path string) (*DuckDBQueryBuilder, error) {
+func NewDuckDBQueryBuilder(path string, opts Options) (*DuckDBQueryBuilder, error) {
 	if len(path) == 0 {
 		path = DEFAULT_PATH
 	}
 
-	con, err := duckdb.NewConnector(path, nil)
+	con, err := duckdb.NewConnector(path, trackProgress)
 	if err != nil {
 		return nil, err
 	}
 	db := sql.OpenDB(con)
 
-	db.Exec("PRAGMA memory_limit='2GB'")
-	db.Exec("SET temp_directory='/tmp/duckdb_tmp'")
-	db.Exec("SET threads TO 4")
+	if err := setResources(db, opts.MemoryLimit, opts.Threads); err != nil {
+		db.Close()
+		return nil, err
+	}
+	db.Exec(fmt.Sprintf("SET temp_directory=%s", QuoteLiteral(DEFAULT_TEMP_DIRECTORY)))
 	// db.Exec("SET max_temp_directory_size='8GB'")
 	// db.Exec("SET default_block_size=2621440")
-	// db.Exec("SET enable_progress_bar = true")
-	db.Exec("PRAGMA add_parquet_key('key256', '01234567891123450123456789112345');")
-	log.Println("added memory_limit and temp_directory")
+	db.Exec(fmt.Sprintf("PRAGMA add_parquet_key(%s, '01234567891123450123456789112345');", QuoteLiteral(PARQUET_KEY_NAME)))
+	slog.Debug("added memory_limit and temp_directory")
+
+	if err := restrictAccess(db, opts); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	progress := checkProgress(context.Background(), db)
+	if progress != nil {
+		slog.Warn("query progress disabled", "err", progress)
+	}
 
-	return &DuckDBQueryBuilder{con: db, connector: con}, nil
+	return &DuckDBQueryBuilder{con: db, connector: con, progress: progress}, nil
 }
 
-func (qb DuckDBQueryBuilder) CSVToTable(tableName, filePath string) error {
-	return qb.Exec(fmt.Sprintf(`CREATE OR REPLACE TABLE %s AS SELECT * FROM read_csv('%s');`, tableName, filePath))
+// SetResources changes the memory limit and the number of threads of the
+// database, which fails once its configuration is locked. The memory limit is
+// set back to previousMemoryLimit, DuckDB's default when empty, when the
+// threads can't be changed.
+func (qb DuckDBQueryBuilder) SetResources(memoryLimit string, threads int, previousMemoryLimit string) error {
+	if err := setResources(qb.con, memoryLimit, 0); err != nil {
+		return err
+	}
+	err := setResources(qb.con, "", threads)
+	if err == nil || memoryLimit == "" {
+		return err
+	}
+
+	restore := "RESET memory_limit"
+	if previousMemoryLimit != "" {
+		restore = fmt.Sprintf("SET memory_limit=%s", QuoteLiteral(previousMemoryLimit))
+	}
+	if _, restoreErr := qb.con.Exec(restore); restoreErr != nil {
+		return errors.Join(err, fmt.Errorf("%s: %w", restore, restoreErr))
+	}
+	return err
+}
+
+func setResources(db *sql.DB, memoryLimit string, threads int) error {
+	var settings []string
+	if memoryLimit != "" {
+		settings = append(settings, fmt.Sprintf("SET memory_limit=%s", QuoteLiteral(memoryLimit)))
+	}
+	if threads > 0 {
+		settings = append(settings, fmt.Sprintf("SET threads=%d", threads))
+	}
+
+	for _, setting := range settings {
+		if _, err := db.Exec(setting); err != nil {
+			return fmt.Errorf("%s: %w", setting, err)
+		}
+	}
+	return nil
+}
+
+// restrictAccess stops queries from loading extensions and from reading or
+// writing files outside the allowed directories. The configuration is locked
+// last, as no setting can be changed afterwards.
+func restrictAccess(db *sql.DB, opts Options) error {
+	settings := []string{
+		"SET autoinstall_known_extensions=false",
+		"SET autoload_known_extensions=false",
+	}
+
+	if len(opts.AllowedDirectories) > 0 {
+		var supported bool
+		err := db.QueryRow("SELECT count(*) > 0 FROM duckdb_settings() WHERE name = 'allowed_directories'").Scan(&supported)
+		if err != nil {
+			return err
+		}
+
+		if !supported {
+			// external access can't be turned off without it, the server
+			// itself reads the sources through DuckDB
+			return ErrSandboxUnsupported
+		}
+
+		dirs := append([]string{DEFAULT_TEMP_DIRECTORY}, opts.AllowedDirectories...)
+		settings = append(settings,
+			fmt.Sprintf("SET allowed_directories=[%s]", literalList(dirs)),
+			"SET enable_external_access=false",
+		)
+	}
+
+	if opts.LockConfiguration {
+		settings = append(settings, "SET lock_configuration=true")
+	}
+
+	for _, setting := range settings {
+		if _, err := db.Exec(setting); err != nil {
+			return fmt.Errorf("%s: %w", setting, err)
+		}
+	}
+	return nil
+}
+
+// Describe returns the columns of a table or view.
+func (qb DuckDBQueryBuilder) Describe(relation string) ([]Column, error) {
+	rows, err := qb.con.Query(fmt.Sprintf("SELECT column_name, column_type FROM (DESCRIBE %s)", QuoteIdent(relation)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cols []Column
+	for rows.Next() {
+		var col Column
+		if err := rows.Scan(&col.Name, &col.Type); err != nil {
+			return nil, err
+		}
+		cols = append(cols, col)
+	}
+	return cols, rows.Err()
 }
 
 func (qb DuckDBQueryBuilder) Exec(query string) error {
@@ -54,10 +185,21 @@ func (qb DuckDBQueryBuilder) Query(query string) (*sql.Rows, error) {
 	return qb.con.Query(query)
 }
 
+// Ping checks that the database answers queries.
+func (qb DuckDBQueryBuilder) Ping(ctx context.Context) error {
+	var one int
+	return qb.con.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+}
+
 func (qb DuckDBQueryBuilder) Close() error {
 	return qb.con.Close()
 }
 
 func (qb DuckDBQueryBuilder) GetArrow(ctx context.Context) (*DuckDBArrowQueryBuilder, error) {
-	return NewDuckDBArrowQueryBuilder(ctx, qb.connector)
+	arrowQB, err := NewDuckDBArrowQueryBuilder(ctx, qb.connector)
+	if err != nil {
+		return nil, err
+	}
+	arrowQB.progress = qb.progress
+	return arrowQB, nil
 }
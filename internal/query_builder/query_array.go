@@ -4,13 +4,15 @@ import (
 	"context"
 	"database/sql/driver"
 
-	"github.com/apache/arrow/go/v14/arrow/array"
-	"github.com/marcboeker/go-duckdb"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/marcboeker/go-duckdb/v2"
 )
 
 type DuckDBArrowQueryBuilder struct {
 	arrow *duckdb.Arrow
 	conn  driver.Conn
+	// progress is why Progress is disabled, nil when it isn't
+	progress error
 }
 
 func NewDuckDBArrowQueryBuilder(ctx context.Context, sql *duckdb.Connector) (*DuckDBArrowQueryBuilder, error) {
@@ -24,7 +26,9 @@ func NewDuckDBArrowQueryBuilder(ctx context.Context, sql *duckdb.Connector) (*Du
 		return nil, err
 	}
 
-	return &DuckDBArrowQueryBuilder{arrow: arrow, conn: conn}, nil
+	arrowConnections.Add(1)
+	// the driver is checked by NewDuckDBQueryBuilder
+	return &DuckDBArrowQueryBuilder{arrow: arrow, conn: conn, progress: ErrProgressUnsupported}, nil
 }
 
 func (qb DuckDBArrowQueryBuilder) Exec(ctx context.Context, query string) (array.RecordReader, error) {
@@ -35,6 +39,23 @@ func (qb DuckDBArrowQueryBuilder) Query(ctx context.Context, query string) (arra
 	return qb.Exec(ctx, query)
 }
 
+// CopyToParquet writes the rows of a table or view to a Parquet file, on the
+// connection of the builder so that its progress can be followed.
+func (qb DuckDBArrowQueryBuilder) CopyToParquet(ctx context.Context, relation, filePath string, opts ParquetOptions) error {
+	query, err := copyToParquet(relation, filePath, opts)
+	if err != nil {
+		return err
+	}
+
+	rows, err := qb.Exec(ctx, query)
+	if err != nil {
+		return err
+	}
+	rows.Release()
+	return nil
+}
+
 func (qb DuckDBArrowQueryBuilder) Close() error {
+	arrowConnections.Add(-1)
 	return qb.conn.Close()
 }
@@ -0,0 +1,98 @@
+package querybuilder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRestrictAccess(t *testing.T) {
+	allowed, other := t.TempDir(), t.TempDir()
+	for _, dir := range []string{allowed, other} {
+		if err := os.WriteFile(filepath.Join(dir, "a.csv"), []byte("n\n1\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db := openTestDB(t)
+	var supported bool
+	if err := db.QueryRow("SELECT count(*) > 0 FROM duckdb_settings() WHERE name = 'allowed_directories'").Scan(&supported); err != nil {
+		t.Fatal(err)
+	}
+
+	if !supported {
+		t.Fatal("the DuckDB bundled with go-duckdb has no allowed_directories setting")
+	}
+	if err := restrictAccess(db, Options{AllowedDirectories: []string{allowed}, LockConfiguration: true}); err != nil {
+		t.Fatalf("restrictAccess: %v", err)
+	}
+
+	var n int
+	if err := db.QueryRow("SELECT n FROM read_csv(" + QuoteLiteral(filepath.Join(allowed, "a.csv")) + ")").Scan(&n); err != nil || n != 1 {
+		t.Errorf("reading an allowed file = %d, %v", n, err)
+	}
+	for _, path := range []string{
+		filepath.Join(other, "a.csv"),
+		filepath.Join(allowed, "..", filepath.Base(other), "a.csv"),
+		filepath.Join(other, "*.csv"),
+	} {
+		if err := db.QueryRow("SELECT n FROM read_csv(" + QuoteLiteral(path) + ")").Scan(&n); err == nil {
+			t.Errorf("read %s outside the allowed directories", path)
+		}
+	}
+	if _, err := db.Exec("COPY (SELECT 1) TO " + QuoteLiteral(filepath.Join(other, "b.csv"))); err == nil {
+		t.Error("wrote a file outside the allowed directories")
+	}
+	if _, err := db.Exec("SET enable_external_access=true"); err == nil {
+		t.Error("changed a setting of a locked configuration")
+	}
+}
+
+func TestRestrictAccessWithoutSandbox(t *testing.T) {
+	db := openTestDB(t)
+	if err := restrictAccess(db, Options{}); err != nil {
+		t.Fatalf("restrictAccess: %v", err)
+	}
+	if _, err := db.Exec("SET threads=2"); err != nil {
+		t.Errorf("configuration locked: %v", err)
+	}
+}
+
+func TestSetResources(t *testing.T) {
+	qb := DuckDBQueryBuilder{con: openTestDB(t)}
+	qb.con.SetMaxOpenConns(1)
+	setting := func(name string) string {
+		t.Helper()
+		var value string
+		if err := qb.con.QueryRow("SELECT current_setting(" + QuoteLiteral(name) + ")").Scan(&value); err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+
+	if err := qb.SetResources("512MB", 2, ""); err != nil {
+		t.Fatalf("SetResources: %v", err)
+	}
+	before := setting("memory_limit")
+	if threads := setting("threads"); threads != "2" {
+		t.Errorf("threads = %s, want 2", threads)
+	}
+
+	// the threads can't be fewer than the external threads
+	if _, err := qb.con.Exec("SET external_threads=2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := qb.SetResources("1GB", 1, "512MB"); err == nil {
+		t.Fatal("SetResources succeeded with fewer threads than external threads")
+	}
+	if after := setting("memory_limit"); after != before {
+		t.Errorf("memory_limit = %s after a failed change, want %s", after, before)
+	}
+
+	if _, err := qb.con.Exec("SET lock_configuration=true"); err != nil {
+		t.Fatal(err)
+	}
+	if err := qb.SetResources("1GB", 2, "512MB"); err == nil {
+		t.Error("SetResources succeeded on a locked configuration")
+	}
+}
@@ -0,0 +1,149 @@
+package querybuilder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidText is returned for names and values that can't be written in a
+// statement. DuckDB reads statements as C strings, so a NUL byte would end
+// the statement early.
+var ErrInvalidText = errors.New("text contains a NUL byte")
+
+// QuoteIdent quotes a table, view or column name.
+func QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// QuoteLiteral quotes a string literal. Backslashes have no special meaning in
+// DuckDB string literals, only quotes need escaping.
+func QuoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func quoteIdents(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = QuoteIdent(name)
+	}
+	return quoted
+}
+
+func identList(names []string) string {
+	return strings.Join(quoteIdents(names), ", ")
+}
+
+func literalList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = QuoteLiteral(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func checkText(values ...string) error {
+	for _, v := range values {
+		if strings.IndexByte(v, 0) >= 0 {
+			return fmt.Errorf("%w: %q", ErrInvalidText, v)
+		}
+	}
+	return nil
+}
+
+// SelectAll returns the query reading every row of a table or view.
+func SelectAll(relation string) string {
+	return fmt.Sprintf("SELECT * FROM %s", QuoteIdent(relation))
+}
+
+// BindTable returns the query reading table whenever it reads the relation
+// name, which doesn't have to exist. The query must be a single statement
+// without a terminating semicolon.
+func BindTable(query, name, table string) string {
+	// the newline ends a trailing comment of the query
+	return fmt.Sprintf("WITH %s AS (SELECT * FROM %s) SELECT * FROM (%s\n)", QuoteIdent(name), QuoteIdent(table), query)
+}
+
+// ParquetOptions are the options of the Parquet exports.
+type ParquetOptions struct {
+	// Compression is one of parquetCompressions, snappy by default.
+	Compression string
+	// FooterKey is the name of the key encrypting the footer, registered with
+	// PRAGMA add_parquet_key. The footer is not encrypted when empty.
+	FooterKey string
+}
+
+var parquetCompressions = map[string]bool{
+	"uncompressed": true, "snappy": true, "gzip": true, "zstd": true, "brotli": true, "lz4": true,
+}
+
+// CSVToTable loads a CSV file in a table, replacing it. The path is passed as
+// a parameter.
+func (qb DuckDBQueryBuilder) CSVToTable(tableName, filePath string) error {
+	if err := checkText(tableName, filePath); err != nil {
+		return err
+	}
+
+	_, err := qb.con.Exec(fmt.Sprintf("CREATE OR REPLACE TABLE %s AS SELECT * FROM read_csv(?)", QuoteIdent(tableName)), filePath)
+	return err
+}
+
+// CreateView creates or replaces a view over the query.
+func (qb DuckDBQueryBuilder) CreateView(viewName, query string) error {
+	if err := checkText(viewName, query); err != nil {
+		return err
+	}
+
+	return qb.Exec(fmt.Sprintf("CREATE OR REPLACE VIEW %s AS %s", QuoteIdent(viewName), query))
+}
+
+// DropView drops a view, if it exists.
+func (qb DuckDBQueryBuilder) DropView(viewName string) error {
+	if err := checkText(viewName); err != nil {
+		return err
+	}
+
+	return qb.Exec(fmt.Sprintf("DROP VIEW IF EXISTS %s", QuoteIdent(viewName)))
+}
+
+// DropTable drops a table, if it exists.
+func (qb DuckDBQueryBuilder) DropTable(tableName string) error {
+	if err := checkText(tableName); err != nil {
+		return err
+	}
+
+	return qb.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", QuoteIdent(tableName)))
+}
+
+// CopyToParquet writes the rows of a table or view to a Parquet file.
+func (qb DuckDBQueryBuilder) CopyToParquet(relation, filePath string, opts ParquetOptions) error {
+	query, err := copyToParquet(relation, filePath, opts)
+	if err != nil {
+		return err
+	}
+	return qb.Exec(query)
+}
+
+// copyToParquet returns the COPY statement writing the rows of a table or
+// view to a Parquet file. COPY doesn't take parameters, the path and the
+// options are quoted instead.
+func copyToParquet(relation, filePath string, opts ParquetOptions) (string, error) {
+	if err := checkText(relation, filePath, opts.FooterKey); err != nil {
+		return "", err
+	}
+
+	compression := strings.ToLower(opts.Compression)
+	if compression == "" {
+		compression = "snappy"
+	}
+	if !parquetCompressions[compression] {
+		return "", fmt.Errorf("unsupported parquet compression %q", opts.Compression)
+	}
+
+	options := []string{"FORMAT PARQUET", "COMPRESSION " + QuoteLiteral(compression)}
+	if opts.FooterKey != "" {
+		options = append([]string{fmt.Sprintf("ENCRYPTION_CONFIG {footer_key: %s}", QuoteLiteral(opts.FooterKey))}, options...)
+	}
+
+	return fmt.Sprintf("COPY %s TO %s (%s)", QuoteIdent(relation), QuoteLiteral(filePath), strings.Join(options, ", ")), nil
+}
@@ -0,0 +1,174 @@
+package querybuilder
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"unicode/utf8"
+
+	_ "github.com/marcboeker/go-duckdb/v2"
+)
+
+// quotingSeeds are names and values that would break out of naive quoting.
+var quotingSeeds = []string{
+	"plain",
+	"with space",
+	"'",
+	`"`,
+	`\`,
+	`\'`,
+	`''`,
+	`""`,
+	"'; DROP TABLE loadtest; --",
+	`"; DROP TABLE loadtest; --`,
+	"a\nb\r\tc",
+	"/* comment",
+	"-- comment",
+	"$$dollar$$",
+	"ünïcödé 数据 🦆",
+	"E'escape'",
+}
+
+func openTestDB(t testing.TB) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// quotable tells whether a text can be written in a statement, DuckDB
+// rejects invalid UTF-8 on its own.
+func quotable(text string) bool {
+	return checkText(text) == nil && utf8.ValidString(text)
+}
+
+func FuzzQuoteLiteral(f *testing.F) {
+	for _, seed := range quotingSeeds {
+		f.Add(seed)
+	}
+	db := openTestDB(f)
+
+	f.Fuzz(func(t *testing.T, value string) {
+		if !quotable(value) {
+			t.Skip()
+		}
+
+		var got string
+		if err := db.QueryRow("SELECT " + QuoteLiteral(value)).Scan(&got); err != nil {
+			t.Fatalf("SELECT %s: %v", QuoteLiteral(value), err)
+		}
+		if got != value {
+			t.Fatalf("SELECT %s = %q, want %q", QuoteLiteral(value), got, value)
+		}
+	})
+}
+
+func FuzzQuoteIdent(f *testing.F) {
+	for _, seed := range quotingSeeds {
+		f.Add(seed)
+	}
+	db := openTestDB(f)
+
+	f.Fuzz(func(t *testing.T, name string) {
+		if !quotable(name) || name == "" {
+			t.Skip()
+		}
+
+		query := "SELECT 42 AS " + QuoteIdent(name)
+		rows, err := db.Query(query)
+		if err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		defer rows.Close()
+
+		columns, err := rows.Columns()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(columns) != 1 || columns[0] != name {
+			t.Fatalf("%s has the columns %q, want [%q]", query, columns, name)
+		}
+
+		var n int
+		if !rows.Next() {
+			t.Fatalf("%s returned no row", query)
+		}
+		if err := rows.Scan(&n); err != nil || n != 42 {
+			t.Fatalf("%s = %d, %v", query, n, err)
+		}
+	})
+}
+
+func TestCheckText(t *testing.T) {
+	tests := []struct {
+		values []string
+		valid  bool
+	}{
+		{nil, true},
+		{[]string{"", "a", "'\"\\"}, true},
+		{[]string{"a", "b\x00c"}, false},
+		{[]string{"\x00"}, false},
+	}
+	for _, tt := range tests {
+		err := checkText(tt.values...)
+		if tt.valid && err != nil {
+			t.Errorf("checkText(%q) = %v", tt.values, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidText) {
+			t.Errorf("checkText(%q) = %v, want ErrInvalidText", tt.values, err)
+		}
+	}
+}
+
+func TestLists(t *testing.T) {
+	if got, want := identList([]string{"a", `b"c`}), `"a", "b""c"`; got != want {
+		t.Errorf("identList = %s, want %s", got, want)
+	}
+	if got, want := literalList([]string{"a", "b'c"}), `'a', 'b''c'`; got != want {
+		t.Errorf("literalList = %s, want %s", got, want)
+	}
+}
+
+func TestBindTable(t *testing.T) {
+	db := openTestDB(t)
+	for _, stmt := range []string{
+		"CREATE TABLE t1 AS SELECT * FROM range(3) r(n)",
+		"CREATE TABLE t2 AS SELECT * FROM range(10, 12) r(n)",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query  string
+		t1, t2 int64
+	}{
+		{"SELECT sum(n) FROM src", 3, 21},
+		{"SELECT sum(n) FROM (SELECT n FROM src WHERE n > 0)", 3, 21},
+		{"WITH x AS (SELECT n FROM src) SELECT sum(n) FROM x", 3, 21},
+		{"SELECT count(*) FROM src a JOIN src b ON a.n < b.n", 3, 1},
+		{"SELECT sum(n) FROM \"SRC\" -- a comment", 3, 21},
+	}
+	for _, tt := range tests {
+		// every view reads its own table
+		for table, want := range map[string]int64{"t1": tt.t1, "t2": tt.t2} {
+			view := "v_" + table
+			if _, err := db.Exec(fmt.Sprintf("CREATE OR REPLACE VIEW %s AS %s", view, BindTable(tt.query, "src", table))); err != nil {
+				t.Fatalf("%s: %v", tt.query, err)
+			}
+			var got int64
+			if err := db.QueryRow(SelectAll(view)).Scan(&got); err != nil {
+				t.Fatalf("%s: %v", tt.query, err)
+			}
+			if got != want {
+				t.Errorf("%s on %s = %d, want %d", tt.query, table, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package querybuilder
+
+import (
+	"database/sql"
+	"sync/atomic"
+)
+
+// arrowConnections counts the open arrow connections, which are not part of
+// the pool of the database.
+var arrowConnections atomic.Int64
+
+// Stats is the usage of the database.
+type Stats struct {
+	// Connections are the open connections, arrow ones included.
+	Connections int64
+	// MemoryBytes is the memory used by DuckDB, buffers and temporary data
+	// included.
+	MemoryBytes int64
+}
+
+func (qb DuckDBQueryBuilder) Stats() (Stats, error) {
+	stats := Stats{Connections: int64(qb.con.Stats().OpenConnections) + arrowConnections.Load()}
+
+	var memory sql.NullInt64
+	if err := qb.con.QueryRow("SELECT sum(memory_usage_bytes) FROM duckdb_memory()").Scan(&memory); err != nil {
+		return stats, err
+	}
+	stats.MemoryBytes = memory.Int64
+	return stats, nil
+}
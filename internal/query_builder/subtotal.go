@@ -0,0 +1,166 @@
+package querybuilder
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Names of the columns added by a subtotal step.
+const (
+	GroupingIDColumn = "grouping_id"
+	LevelColumn      = "level"
+	NodeKeyColumn    = "node_key"
+	ParentKeyColumn  = "parent_key"
+)
+
+// SubtotalStep computes Measures for every level of the Dimensions hierarchy,
+// from the grand total (level 0) down to the detail rows (level
+// len(Dimensions)). Besides the dimensions and the measures every row has:
+//   - grouping_id, the GROUPING() bitmask of the dimensions, a set bit meaning
+//     the dimension is rolled up
+//   - level, the number of dimensions the row is grouped by
+//   - node_key and parent_key, with ParentKey set, the values of the
+//     dimensions identifying the row and its parent row
+//
+// The rows are ordered depth first so that a subtotal row directly precedes
+// the rows it sums up.
+type SubtotalStep struct {
+	Dimensions []string  `json:"dimensions"`
+	Measures   []Measure `json:"measures"`
+	// Levels restricts the output to the given levels, all of them when empty.
+	Levels    []int32 `json:"levels"`
+	ParentKey bool    `json:"parent_key"`
+}
+
+func (s SubtotalStep) compile(from string, schema []Column) (string, []Column, error) {
+	if len(s.Dimensions) == 0 {
+		return "", nil, errors.New("subtotal: no dimensions")
+	}
+
+	dims, err := groupColumns(schema, s.Dimensions)
+	if err != nil {
+		return "", nil, fmt.Errorf("subtotal: %w", err)
+	}
+	s.Dimensions = columnNames(dims)
+
+	measures, measureCols, err := compileMeasures(schema, dims, s.Measures)
+	if err != nil {
+		return "", nil, fmt.Errorf("subtotal: %w", err)
+	}
+
+	extra := []string{GroupingIDColumn, LevelColumn}
+	if s.ParentKey {
+		extra = append(extra, NodeKeyColumn, ParentKeyColumn)
+	}
+	for _, name := range extra {
+		if _, err := lookup(append(dims, measureCols...), name); err == nil {
+			return "", nil, fmt.Errorf("subtotal: column %q clashes with a generated column", name)
+		}
+	}
+
+	groupBy, err := s.groupBy()
+	if err != nil {
+		return "", nil, err
+	}
+
+	// the dimensions below the deepest requested level are in no grouping
+	// set, DuckDB can neither select them nor pass them to GROUPING, so they
+	// are always NULL and their bits of the grouping ID are always set
+	grouped := s.deepestLevel()
+	rolledUp := len(s.Dimensions) - grouped
+
+	grouping := fmt.Sprintf("CAST(%d AS BIGINT)", 1<<rolledUp-1)
+	level := "CAST(0 AS BIGINT)"
+	if grouped > 0 {
+		used := fmt.Sprintf("GROUPING(%s)", identList(s.Dimensions[:grouped]))
+		grouping = used
+		if rolledUp > 0 {
+			grouping = fmt.Sprintf("(%s << %d | %d)", used, rolledUp, 1<<rolledUp-1)
+		}
+		level = fmt.Sprintf("CAST(%d - bit_count(%s) AS BIGINT)", grouped, used)
+	}
+
+	exprs := make([]string, 0, len(dims)+len(measures)+4)
+	for _, dim := range dims[:grouped] {
+		exprs = append(exprs, QuoteIdent(dim.Name))
+	}
+	for _, dim := range dims[grouped:] {
+		if dim.Type == "" {
+			exprs = append(exprs, fmt.Sprintf("NULL AS %s", QuoteIdent(dim.Name)))
+		} else {
+			exprs = append(exprs, fmt.Sprintf("CAST(NULL AS %s) AS %s", dim.Type, QuoteIdent(dim.Name)))
+		}
+	}
+	exprs = append(exprs, measures...)
+	exprs = append(exprs,
+		fmt.Sprintf("%s AS %s", grouping, QuoteIdent(GroupingIDColumn)),
+		fmt.Sprintf("%s AS %s", level, QuoteIdent(LevelColumn)),
+	)
+
+	next := append(dims, measureCols...)
+	next = append(next, Column{Name: GroupingIDColumn, Type: "BIGINT"}, Column{Name: LevelColumn, Type: "BIGINT"})
+
+	if s.ParentKey {
+		path := make([]string, len(s.Dimensions))
+		for i, dim := range s.Dimensions {
+			if i < grouped {
+				path[i] = fmt.Sprintf("CAST(%s AS VARCHAR)", QuoteIdent(dim))
+			} else {
+				path[i] = "CAST(NULL AS VARCHAR)"
+			}
+		}
+
+		exprs = append(exprs,
+			fmt.Sprintf("list_slice([%s], 1, %s) AS %s", strings.Join(path, ", "), level, QuoteIdent(NodeKeyColumn)),
+			fmt.Sprintf("CASE WHEN %[2]s = 0 THEN NULL ELSE list_slice([%[1]s], 1, %[2]s - 1) END AS %[3]s", strings.Join(path, ", "), level, QuoteIdent(ParentKeyColumn)),
+		)
+		next = append(next, Column{Name: NodeKeyColumn, Type: "VARCHAR[]"}, Column{Name: ParentKeyColumn, Type: "VARCHAR[]"})
+	}
+
+	order := make([]string, 0, 2*grouped+1)
+	for _, dim := range s.Dimensions[:grouped] {
+		order = append(order, fmt.Sprintf("GROUPING(%s) DESC", QuoteIdent(dim)), fmt.Sprintf("%s ASC NULLS FIRST", QuoteIdent(dim)))
+	}
+	if len(order) == 0 {
+		order = append(order, QuoteIdent(LevelColumn))
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s GROUP BY %s ORDER BY %s", strings.Join(exprs, ", "), from, groupBy, strings.Join(order, ", "))
+	return query, next, nil
+}
+
+// deepestLevel returns the deepest level of the output.
+func (s SubtotalStep) deepestLevel() int {
+	if len(s.Levels) == 0 {
+		return len(s.Dimensions)
+	}
+	return int(slices.Max(s.Levels))
+}
+
+// groupBy returns ROLLUP over the dimensions, or the grouping sets of the
+// requested levels.
+func (s SubtotalStep) groupBy() (string, error) {
+	if len(s.Levels) == 0 {
+		return fmt.Sprintf("ROLLUP (%s)", identList(s.Dimensions)), nil
+	}
+
+	sets := make([]GroupingSet, 0, len(s.Levels))
+	for _, level := range s.Levels {
+		if level < 0 || int(level) > len(s.Dimensions) {
+			return "", fmt.Errorf("subtotal: level %d out of range [0, %d]", level, len(s.Dimensions))
+		}
+		sets = append(sets, GroupingSet{Columns: s.Dimensions[:level]})
+	}
+
+	// deepest level first, so that the grand total ends up last like in ROLLUP
+	sets = DedupGroupingSets(sets)
+	slices.SortFunc(sets, func(a, b GroupingSet) int { return len(b.Columns) - len(a.Columns) })
+
+	parts := make([]string, len(sets))
+	for i, set := range sets {
+		parts[i] = fmt.Sprintf("(%s)", identList(set.Columns))
+	}
+	return fmt.Sprintf("GROUPING SETS (%s)", strings.Join(parts, ", ")), nil
+}
@@ -0,0 +1,143 @@
+package querybuilder
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSubtotal(t *testing.T) {
+	qb, schema := openPeople(t)
+	fill := Step{FillNulls: &FillNullsStep{Columns: []string{"name", "city"}, Value: "(blank)"}}
+
+	tests := []struct {
+		name     string
+		subtotal SubtotalStep
+		rows     []string
+	}{
+		{
+			name: "every level",
+			subtotal: SubtotalStep{
+				Dimensions: []string{"City", "name"},
+				Measures:   []Measure{{Column: "amount", Function: "sum", Alias: "total"}, {Column: "*", Function: "count"}},
+			},
+			rows: []string{
+				"{'city': NULL, 'name': NULL, 'total': 15.75, 'count': 4, 'grouping_id': 3, 'level': 0}",
+				"{'city': (blank), 'name': NULL, 'total': NULL, 'count': 1, 'grouping_id': 1, 'level': 1}",
+				"{'city': (blank), 'name': Bob, 'total': NULL, 'count': 1, 'grouping_id': 0, 'level': 2}",
+				"{'city': Oslo, 'name': NULL, 'total': 1.00, 'count': 1, 'grouping_id': 1, 'level': 1}",
+				"{'city': Oslo, 'name': Cy, 'total': 1.00, 'count': 1, 'grouping_id': 0, 'level': 2}",
+				"{'city': Paris, 'name': NULL, 'total': 14.75, 'count': 2, 'grouping_id': 1, 'level': 1}",
+				"{'city': Paris, 'name': (blank), 'total': 4.25, 'count': 1, 'grouping_id': 0, 'level': 2}",
+				"{'city': Paris, 'name': Ada, 'total': 10.50, 'count': 1, 'grouping_id': 0, 'level': 2}",
+			},
+		},
+		{
+			name: "some levels with parent keys",
+			subtotal: SubtotalStep{
+				Dimensions: []string{"city", "name"},
+				Measures:   []Measure{{Column: "id", Function: "max"}},
+				Levels:     []int32{0, 1, 1},
+				ParentKey:  true,
+			},
+			rows: []string{
+				"{'city': NULL, 'name': NULL, 'id': 4, 'grouping_id': 3, 'level': 0, 'node_key': [], 'parent_key': NULL}",
+				"{'city': (blank), 'name': NULL, 'id': 3, 'grouping_id': 1, 'level': 1, 'node_key': [(blank)], 'parent_key': []}",
+				"{'city': Oslo, 'name': NULL, 'id': 4, 'grouping_id': 1, 'level': 1, 'node_key': [Oslo], 'parent_key': []}",
+				"{'city': Paris, 'name': NULL, 'id': 2, 'grouping_id': 1, 'level': 1, 'node_key': [Paris], 'parent_key': []}",
+			},
+		},
+		{
+			name: "grand total only",
+			subtotal: SubtotalStep{
+				Dimensions: []string{"city", "joined"},
+				Measures:   []Measure{{Column: "joined", Function: "min", Alias: "first"}},
+				Levels:     []int32{0},
+				ParentKey:  true,
+			},
+			rows: []string{
+				"{'city': NULL, 'joined': NULL, 'first': 2023-12-31, 'grouping_id': 3, 'level': 0, 'node_key': [], 'parent_key': NULL}",
+			},
+		},
+		{
+			name: "detail rows only",
+			subtotal: SubtotalStep{
+				Dimensions: []string{"city", "id"},
+				Levels:     []int32{2},
+				ParentKey:  true,
+			},
+			rows: []string{
+				"{'city': (blank), 'id': 3, 'grouping_id': 0, 'level': 2, 'node_key': [(blank), 3], 'parent_key': [(blank)]}",
+				"{'city': Oslo, 'id': 4, 'grouping_id': 0, 'level': 2, 'node_key': [Oslo, 4], 'parent_key': [Oslo]}",
+				"{'city': Paris, 'id': 1, 'grouping_id': 0, 'level': 2, 'node_key': [Paris, 1], 'parent_key': [Paris]}",
+				"{'city': Paris, 'id': 2, 'grouping_id': 0, 'level': 2, 'node_key': [Paris, 2], 'parent_key': [Paris]}",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subtotal := tt.subtotal
+			query, tracked, err := Pipeline{Steps: []Step{fill, {Subtotal: &subtotal}}}.compile("people", schema)
+			if err != nil {
+				t.Fatalf("compile: %v", err)
+			}
+
+			checkSchema(t, qb, query, tracked)
+			if rows := queryRows(t, qb.con, query); !slices.Equal(rows, tt.rows) {
+				t.Errorf("%s\nrows %q\nwant %q", query, rows, tt.rows)
+			}
+		})
+	}
+}
+
+func TestSubtotalErrors(t *testing.T) {
+	schema := []Column{{Name: "a", Type: "VARCHAR"}, {Name: "b", Type: "VARCHAR"}, {Name: "level", Type: "INTEGER"}}
+
+	tests := []struct {
+		name     string
+		subtotal SubtotalStep
+		err      string
+	}{
+		{"no dimensions", SubtotalStep{}, "no dimensions"},
+		{"unknown dimension", SubtotalStep{Dimensions: []string{"c"}}, `unknown column "c"`},
+		{"duplicate dimension", SubtotalStep{Dimensions: []string{"a", "A"}}, "duplicate column"},
+		{"dimension clash", SubtotalStep{Dimensions: []string{"Level"}}, `column "level" clashes with a generated column`},
+		{"measure clash", SubtotalStep{Dimensions: []string{"a"}, Measures: []Measure{{Column: "b", Function: "max", Alias: "grouping_id"}}}, "clashes with a generated column"},
+		{"parent key clash", SubtotalStep{Dimensions: []string{"a"}, Measures: []Measure{{Column: "b", Function: "max", Alias: "node_key"}}, ParentKey: true}, "clashes with a generated column"},
+		{"negative level", SubtotalStep{Dimensions: []string{"a"}, Levels: []int32{-1}}, "level -1 out of range [0, 1]"},
+		{"level too deep", SubtotalStep{Dimensions: []string{"a", "b"}, Levels: []int32{3}}, "level 3 out of range [0, 2]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subtotal := tt.subtotal
+			_, err := Pipeline{Steps: []Step{{Subtotal: &subtotal}}}.Compile("t", schema)
+			if !errors.Is(err, ErrInvalidPipeline) || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("Compile = %v, want an invalid pipeline error containing %q", err, tt.err)
+			}
+		})
+	}
+
+	// a node key column is only generated with parent keys
+	subtotal := SubtotalStep{Dimensions: []string{"a"}, Measures: []Measure{{Column: "b", Function: "max", Alias: "node_key"}}}
+	if _, err := (Pipeline{Steps: []Step{{Subtotal: &subtotal}}}).Compile("t", schema); err != nil {
+		t.Errorf("Compile = %v", err)
+	}
+}
+
+func TestSubtotalGroupBy(t *testing.T) {
+	tests := []struct {
+		levels []int32
+		want   string
+	}{
+		{nil, `ROLLUP ("a", "b", "c")`},
+		{[]int32{0, 3}, `GROUPING SETS (("a", "b", "c"), ())`},
+		{[]int32{1, 3, 1, 2}, `GROUPING SETS (("a", "b", "c"), ("a", "b"), ("a"))`},
+	}
+	for _, tt := range tests {
+		got, err := SubtotalStep{Dimensions: []string{"a", "b", "c"}, Levels: tt.levels}.groupBy()
+		if err != nil || got != tt.want {
+			t.Errorf("groupBy(%v) = %s, %v, want %s", tt.levels, got, err, tt.want)
+		}
+	}
+}
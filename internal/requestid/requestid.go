@@ -0,0 +1,53 @@
+// Package requestid carries the ID of a request, given by the client in the
+// x-request-id metadata or generated by the server.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header is the metadata key of the request ID, in the requests and the
+// response headers.
+const Header = "x-request-id"
+
+// maxLength bounds the IDs given by clients, as they end up in logs and file
+// names.
+const maxLength = 128
+
+type contextKey struct{}
+
+// New returns a random ID.
+func New() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Valid reports whether an ID given by a client can be used: letters, digits
+// and ".-_:" only.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '.', r == '-', r == '_', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the ID of the request, "" outside of a request.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
@@ -0,0 +1,109 @@
+// Package sandbox restricts the local files requests may read to a set of
+// root directories.
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type Sandbox struct {
+	// roots are absolute and free of symlinks
+	roots []string
+}
+
+// New returns a sandbox allowing the files below the given directories. A
+// sandbox without roots allows every path.
+func New(roots []string) (*Sandbox, error) {
+	s := &Sandbox{}
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+
+		resolved, err := resolve(root)
+		if err != nil {
+			return nil, fmt.Errorf("sandbox root %s: %w", root, err)
+		}
+		s.roots = append(s.roots, resolved)
+	}
+	return s, nil
+}
+
+func (s *Sandbox) Enabled() bool {
+	return len(s.roots) > 0
+}
+
+// Roots returns the resolved root directories.
+func (s *Sandbox) Roots() []string {
+	return s.roots
+}
+
+// Resolve returns the absolute path p points to once symlinks and ".." are
+// resolved, failing with PERMISSION_DENIED when it is outside every root.
+func (s *Sandbox) Resolve(p string) (string, error) {
+	resolved, err := resolve(p)
+	if err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "invalid path %q: %v", p, err)
+	}
+
+	if !s.Enabled() {
+		return resolved, nil
+	}
+
+	for _, root := range s.roots {
+		if Within(root, resolved) {
+			return resolved, nil
+		}
+	}
+	return "", status.Errorf(codes.PermissionDenied, "access to %q is not allowed", p)
+}
+
+// Within reports whether p is root or below it, both being clean absolute
+// paths.
+func Within(root, p string) bool {
+	if p == root {
+		return true
+	}
+	return strings.HasPrefix(p, strings.TrimSuffix(root, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// resolve makes p absolute and resolves the symlinks of its longest existing
+// prefix, so that paths of files that don't exist yet can be checked too.
+func resolve(p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+
+	var missing []string
+	dir := abs
+	for {
+		resolved, err := filepath.EvalSymlinks(dir)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", err
+		}
+		missing = append([]string{filepath.Base(dir)}, missing...)
+		dir = parent
+	}
+}
+
+// IsPermissionError reports whether a DuckDB error comes from accessing a file
+// or setting the external access configuration forbids.
+func IsPermissionError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "Permission Error")
+}
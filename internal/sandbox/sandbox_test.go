@@ -0,0 +1,114 @@
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestResolve(t *testing.T) {
+	base, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(base, "data")
+	outside := filepath.Join(base, "outside")
+	for _, dir := range []string{filepath.Join(root, "sub"), outside, filepath.Join(base, "data2")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{filepath.Join(root, "a.csv"), filepath.Join(outside, "secret.csv")} {
+		if err := os.WriteFile(file, []byte("n\n1\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for link, target := range map[string]string{
+		"escape":   outside,
+		"link.csv": filepath.Join(outside, "secret.csv"),
+		"inside":   filepath.Join(root, "sub"),
+	} {
+		if err := os.Symlink(target, filepath.Join(root, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := New([]string{root, ""})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if roots := s.Roots(); len(roots) != 1 || roots[0] != root {
+		t.Fatalf("roots %q, want [%s]", roots, root)
+	}
+	t.Chdir(root)
+
+	tests := []struct {
+		name string
+		path string
+		// want is the resolved path, empty when access is denied
+		want string
+	}{
+		{"file", filepath.Join(root, "a.csv"), filepath.Join(root, "a.csv")},
+		{"root", root, root},
+		{"missing file", filepath.Join(root, "sub", "new.csv"), filepath.Join(root, "sub", "new.csv")},
+		{"missing file below a symlink", filepath.Join(root, "inside", "new", "b.csv"), filepath.Join(root, "sub", "new", "b.csv")},
+		{"dot dot inside", filepath.Join(root, "sub", "..", "a.csv"), filepath.Join(root, "a.csv")},
+		{"dot dot escape", root + "/../outside/secret.csv", ""},
+		{"dot dot escape from a missing directory", root + "/missing/../../outside/secret.csv", ""},
+		{"symlinked file escape", filepath.Join(root, "link.csv"), ""},
+		{"symlinked directory escape", filepath.Join(root, "escape", "secret.csv"), ""},
+		{"missing file below an escaping symlink", filepath.Join(root, "escape", "new", "b.csv"), ""},
+		{"prefix of another directory", filepath.Join(base, "data2", "a.csv"), ""},
+		{"relative", "a.csv", filepath.Join(root, "a.csv")},
+		{"relative missing file", "sub/new.csv", filepath.Join(root, "sub", "new.csv")},
+		{"relative escape", "../outside/secret.csv", ""},
+		{"relative symlink escape", "escape/secret.csv", ""},
+	}
+	for _, tt := range tests {
+		got, err := s.Resolve(tt.path)
+		if tt.want == "" {
+			if status.Code(err) != codes.PermissionDenied {
+				t.Errorf("%s: Resolve(%q) = %q, %v, want PERMISSION_DENIED", tt.name, tt.path, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s: Resolve(%q) = %q, %v, want %q", tt.name, tt.path, got, err, tt.want)
+		}
+	}
+
+	// without roots every path is allowed, still resolved
+	open, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if got, err := open.Resolve(filepath.Join(root, "escape", "secret.csv")); err != nil || got != filepath.Join(outside, "secret.csv") {
+		t.Errorf("Resolve without roots = %q, %v", got, err)
+	}
+}
+
+func TestWithin(t *testing.T) {
+	tests := []struct {
+		root, path string
+		want       bool
+	}{
+		{"/data", "/data", true},
+		{"/data", "/data/x", true},
+		{"/data", "/data/x/y.csv", true},
+		{"/data/", "/data/x", true},
+		{"/data", "/data2", false},
+		{"/data", "/data2/x", false},
+		{"/data", "/dat", false},
+		{"/data", "/", false},
+		{"/data/x", "/data", false},
+		{"/", "/data", true},
+	}
+	for _, tt := range tests {
+		if got := Within(tt.root, tt.path); got != tt.want {
+			t.Errorf("Within(%q, %q) = %t, want %t", tt.root, tt.path, got, tt.want)
+		}
+	}
+}
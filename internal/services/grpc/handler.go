@@ -6,9 +6,7 @@ import (
 	pb "duckdb-server/internal/services/grpc/data_transform"
 	"fmt"
 	"log"
-	"os"
 	"path"
-	"runtime/pprof"
 	"time"
 
 	utilsQuery "duckdb-server/internal/utils/query"
@@ -25,20 +23,22 @@ const _ = grpc.SupportPackageIsVersion7
 // UnimplementedDataTransformServer must be embedded to have forward compatible implementations.
 type dataTransform struct {
 	pb.UnimplementedDataTransformServer
-	qb *querybuilder.DuckDBQueryBuilder
+	cfg *config.Config
+	qb  *querybuilder.DuckDBQueryBuilder
 }
 
-func NewDataTransformService() *dataTransform {
+func NewDataTransformService(cfg *config.Config) *dataTransform {
 	log.Printf("NewDataTransformService creating file qb\n")
 
-	p := path.Join(config.TEMP_DUCKDB_DIR, fmt.Sprintf("data-%s.duckdb", time.Now().String()))
-	qb, err := querybuilder.NewDuckDBQueryBuilder(p)
+	p := path.Join(cfg.Dirs.TempDuckDB, fmt.Sprintf("data-%s.duckdb", time.Now().String()))
+	qb, err := querybuilder.NewDuckDBQueryBuilder(p, querybuilder.Options{})
 	if err != nil {
 		log.Fatalf("Error creating query builder, err: %v\n", err)
 	}
 
 	return &dataTransform{
-		qb: qb,
+		cfg: cfg,
+		qb:  qb,
 	}
 }
 
@@ -47,31 +47,6 @@ func (t dataTransform) Transform(_ *emptypb.Empty, stream pb.DataTransform_Trans
 		log.Println("computed transform")
 	}()
 
-	// mem profiler
-	{
-		p := path.Join(config.GRPC.ProfDir, fmt.Sprintf("grpc-mem-%s.prof", time.Now().UTC().Format("2006-01-02 15:04:05")))
-		f, err := os.Create(p)
-		if err != nil {
-			fmt.Printf("error creating cpu profiler, err: %v\n", err)
-			return err
-
-		}
-		defer pprof.WriteHeapProfile(f)
-	}
-
-	// cpu profiler
-	{
-		p := path.Join(config.GRPC.ProfDir, fmt.Sprintf("grpc-cpu-%s.prof", time.Now().UTC().Format("2006-01-02 15:04:05")))
-		f, err := os.Create(p)
-		if err != nil {
-			fmt.Printf("error creating cpu profiler, err: %v\n", err)
-			return err
-
-		}
-		pprof.StartCPUProfile(f)
-		defer pprof.StopCPUProfile()
-	}
-
 	const (
 		tableName = "loadtest"
 		viewName  = "v_loadtest"
@@ -83,15 +58,21 @@ func (t dataTransform) Transform(_ *emptypb.Empty, stream pb.DataTransform_Trans
 		return err
 	}
 
-	if err := t.qb.Exec(utilsQuery.CreateView(viewName, tableName)); err != nil {
+	query, err := utilsQuery.CustomersQuery(tableName)
+	if err != nil {
+		log.Printf("error compiling transformation, err: %v\n", err)
+		return err
+	}
+
+	if err := t.qb.CreateView(viewName, query); err != nil {
 		log.Printf("error creating view, err: %v\n", err)
 		return err
 	}
 
-	limit, offset := config.CHUNK_SIZE, 0
+	limit, offset := t.cfg.Stream.ChunkSize, 0
 	sequencyNumber := 1
 	for {
-		q, err := utilsQuery.Transform(t.qb, fmt.Sprintf("SELECT * FROM %s LIMIT %d OFFSET %d", tableName, limit, offset))
+		q, err := utilsQuery.Transform(t.qb, fmt.Sprintf("%s LIMIT %d OFFSET %d", querybuilder.SelectAll(tableName), limit, offset))
 		if err != nil {
 			log.Printf("error getting data, err: %v\n", err)
 			return err
@@ -1,6 +1,7 @@
 package grpc
 
 import (
+	"duckdb-server/config"
 	pb "duckdb-server/internal/services/grpc/data_transform"
 	"fmt"
 	"log"
@@ -13,7 +14,7 @@ import (
 // func InitServer(host string, port int) {
 // 	var opts []grpc.ServerOption
 // 	grpcServer := grpc.NewServer(opts...)
-// 	pb.RegisterDataTransformServer(grpcServer, NewDataTransformService())
+// 	pb.RegisterDataTransformServer(grpcServer, NewDataTransformService(cfg))
 
 // 	r := gin.Default()
 // 	r.GET("/transform", func(ctx *gin.Context) {
@@ -24,7 +25,7 @@ import (
 // 	r.Run(fmt.Sprintf("%s:%d", host, port))
 // }
 
-func InitServer(host string, port int) {
+func InitServer(host string, port int, cfg *config.Config) {
 	lis, err := net.Listen("tcp", fmt.Sprintf("%s:%d", host, port))
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
@@ -32,7 +33,7 @@ func InitServer(host string, port int) {
 
 	var opts []grpc.ServerOption
 	grpcServer := grpc.NewServer(opts...)
-	pb.RegisterDataTransformServer(grpcServer, NewDataTransformService())
+	pb.RegisterDataTransformServer(grpcServer, NewDataTransformService(cfg))
 	reflection.Register(grpcServer) // for grpc-curl
 	log.Printf("strings grpc server on %s:%d", host, port)
 	grpcServer.Serve(lis)
@@ -0,0 +1,208 @@
+package grpc_arrow
+
+import (
+	"context"
+	"duckdb-server/internal/profiling"
+	querybuilder "duckdb-server/internal/query_builder"
+	"duckdb-server/internal/requestid"
+	pb "duckdb-server/internal/services/grpc_arrow/data_transform"
+	"errors"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// admin implements the administration RPCs over the data transform service.
+type admin struct {
+	pb.UnimplementedAdminServer
+	service *dataTransform
+	// profiler is nil when profiling is disabled
+	profiler *profiling.Profiler
+	// history is nil when the query history is disabled
+	history  *history
+	reloader *reloader
+}
+
+func (a *admin) CacheStats(ctx context.Context, in *pb.CacheStatsIn) (*pb.CacheStatsOut, error) {
+	if a.service.cache == nil {
+		return &pb.CacheStatsOut{}, nil
+	}
+
+	stats := a.service.cache.Stats()
+	return &pb.CacheStatsOut{
+		Enabled:       true,
+		Files:         int64(stats.Files),
+		Urls:          int64(stats.URLs),
+		Bytes:         stats.Bytes,
+		MaxBytes:      stats.MaxBytes,
+		Hits:          stats.Hits,
+		Revalidations: stats.Revalidations,
+		Misses:        stats.Misses,
+		Evictions:     stats.Evictions,
+	}, nil
+}
+
+const (
+	defaultProfileSeconds = 30
+	maxProfileSeconds     = 300
+	// defaultProfileWait is how long CaptureProfile waits for the request to
+	// profile by default
+	defaultProfileWait = 300
+	maxProfileWait     = 3600
+)
+
+var profileKinds = map[pb.ProfileKind]profiling.Kind{
+	pb.ProfileKind_PROFILE_CPU:       profiling.KindCPU,
+	pb.ProfileKind_PROFILE_HEAP:      profiling.KindHeap,
+	pb.ProfileKind_PROFILE_GOROUTINE: profiling.KindGoroutine,
+}
+
+func (a *admin) CaptureProfile(ctx context.Context, in *pb.ProfileIn) (*pb.ProfileOut, error) {
+	if a.profiler == nil {
+		return nil, status.Error(codes.FailedPrecondition, "profiling is disabled, set PROFILING_ENABLED")
+	}
+
+	kind, ok := profileKinds[in.Kind]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown profile kind %v", in.Kind)
+	}
+
+	var profile profiling.Profile
+	if in.RequestId != "" {
+		if !requestid.Valid(in.RequestId) {
+			return nil, status.Error(codes.InvalidArgument, "invalid request_id")
+		}
+		wait, err := profileSeconds(in.Seconds, defaultProfileWait, maxProfileWait)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, wait)
+		defer cancel()
+		if profile, err = a.profiler.CaptureRequest(ctx, kind, in.RequestId); err != nil {
+			return nil, profileError(ctx, err)
+		}
+	} else {
+		d, err := profileSeconds(in.Seconds, defaultProfileSeconds, maxProfileSeconds)
+		if err != nil {
+			return nil, err
+		}
+
+		if profile, err = a.profiler.Capture(ctx, kind, d); err != nil {
+			return nil, profileError(ctx, err)
+		}
+	}
+
+	slog.InfoContext(ctx, "captured profile", "kind", kind, "name", profile.Name)
+	return &pb.ProfileOut{Name: profile.Name, Data: profile.Data}, nil
+}
+
+func profileSeconds(seconds, def, max int32) (time.Duration, error) {
+	switch {
+	case seconds < 0 || seconds > max:
+		return 0, status.Errorf(codes.InvalidArgument, "seconds must be between 0 and %d", max)
+	case seconds == 0:
+		seconds = def
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+func profileError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, profiling.ErrBusy):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, profiling.ErrArmed):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "the request to profile did not run in time")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+	slog.ErrorContext(ctx, "error capturing profile", "err", err)
+	return status.Error(codes.Internal, "failed to capture the profile")
+}
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+func (a *admin) QueryHistory(ctx context.Context, in *pb.QueryHistoryIn) (*pb.QueryHistoryOut, error) {
+	if a.history == nil {
+		return nil, status.Error(codes.FailedPrecondition, "the query history is disabled, set QUERY_HISTORY_ENABLED")
+	}
+
+	switch {
+	case in.Limit < 0 || in.Limit > maxHistoryLimit:
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be between 0 and %d", maxHistoryLimit)
+	case in.MinDurationMs < 0 || in.SinceUnixMs < 0 || in.UntilUnixMs < 0:
+		return nil, status.Error(codes.InvalidArgument, "min_duration_ms, since_unix_ms and until_unix_ms can't be negative")
+	}
+
+	f := querybuilder.HistoryFilter{
+		RequestID:   in.RequestId,
+		Caller:      in.Caller,
+		Source:      in.Source,
+		QueryHash:   in.QueryHash,
+		Status:      in.Status,
+		MinDuration: time.Duration(in.MinDurationMs * float64(time.Millisecond)),
+		Limit:       int(in.Limit),
+	}
+	if f.Limit == 0 {
+		f.Limit = defaultHistoryLimit
+	}
+	if in.SinceUnixMs > 0 {
+		f.Since = time.UnixMilli(in.SinceUnixMs)
+	}
+	if in.UntilUnixMs > 0 {
+		f.Until = time.UnixMilli(in.UntilUnixMs)
+	}
+
+	entries, err := a.history.qb.History(ctx, f)
+	if err != nil {
+		slog.ErrorContext(ctx, "error reading query history", "err", err)
+		return nil, status.Error(codes.Internal, "failed to read the query history")
+	}
+
+	out := &pb.QueryHistoryOut{Entries: make([]*pb.QueryHistoryEntry, len(entries))}
+	for i, e := range entries {
+		out.Entries[i] = &pb.QueryHistoryEntry{
+			StartedAtUnixMs: e.StartedAt.UnixMilli(),
+			RequestId:       e.RequestID,
+			Method:          e.Method,
+			Caller:          e.Caller,
+			Source:          e.Source,
+			QueryHash:       e.QueryHash,
+			Sql:             e.SQL,
+			RowsOut:         e.RowsOut,
+			BytesOut:        e.BytesOut,
+			PhaseMs:         e.PhaseMillis,
+			DurationMs:      e.DurationMillis,
+			PeakMemoryBytes: e.PeakMemoryBytes,
+			Status:          e.Status,
+			Error:           e.Error,
+			Plan:            e.Plan,
+		}
+	}
+	return out, nil
+}
+
+func (a *admin) ReloadConfig(ctx context.Context, in *pb.ReloadConfigIn) (*pb.ReloadConfigOut, error) {
+	changes, err := a.reloader.reload(ctx)
+	switch {
+	case errors.Is(err, errInvalidConfig) || errors.Is(err, errRestartRequired):
+		slog.WarnContext(ctx, "configuration not reloaded", "err", err)
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		slog.ErrorContext(ctx, "error reloading configuration", "err", err)
+		return nil, status.Error(codes.Internal, "failed to apply the configuration")
+	}
+
+	out := &pb.ReloadConfigOut{Changes: make([]*pb.ConfigChange, len(changes))}
+	for i, c := range changes {
+		out.Changes[i] = &pb.ConfigChange{Setting: c.Path, Env: c.Env, OldValue: c.Old, NewValue: c.New}
+	}
+	return out, nil
+}
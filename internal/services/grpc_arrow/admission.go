@@ -0,0 +1,252 @@
+package grpc_arrow
+
+import (
+	"context"
+	"duckdb-server/internal/admission"
+	"duckdb-server/internal/logging"
+	pb "duckdb-server/internal/services/grpc_arrow/data_transform"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	querybuilder "duckdb-server/internal/query_builder"
+
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// priorityHeader is the metadata key clients use to set the priority of a
+	// request, higher values being admitted first.
+	priorityHeader = "x-priority"
+
+	// defaultSourceSize is assumed when the size of the source is unknown.
+	defaultSourceSize = 256 << 20
+	// maxShapeFactor caps the multiplier applied for the query shape.
+	maxShapeFactor = 16
+
+	// sizeTTL is how long the size of a remote source is trusted.
+	sizeTTL = 10 * time.Minute
+	// sizeTimeout bounds the lookup of the size of a remote source.
+	sizeTimeout = 5 * time.Second
+	// maxCachedSizes caps the number of remote sources whose size is kept.
+	maxCachedSizes = 4096
+)
+
+// sourceSizer returns the size of a source, local or remote.
+type sourceSizer func(ctx context.Context, p string) int64
+
+// sizeCache keeps the sizes of the remote sources. Looking a size up takes a
+// round trip that admission can't wait for, so a source seen for the first
+// time counts as defaultSourceSize while its size is looked up in the
+// background for the next requests. Expired sizes are used until refreshed.
+type sizeCache struct {
+	lookup func(ctx context.Context, p string) (int64, error)
+
+	mu      sync.Mutex
+	entries map[string]*sizeEntry
+}
+
+type sizeEntry struct {
+	// size is 0 when unknown
+	size    int64
+	expires time.Time
+	pending bool
+}
+
+func newSizeCache(lookup func(ctx context.Context, p string) (int64, error)) *sizeCache {
+	return &sizeCache{lookup: lookup, entries: map[string]*sizeEntry{}}
+}
+
+// get returns the size of the source, defaultSourceSize when unknown.
+func (c *sizeCache) get(p string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[p]
+	if !ok {
+		c.evict()
+		e = &sizeEntry{}
+		c.entries[p] = e
+	}
+	if !e.pending && !time.Now().Before(e.expires) {
+		e.pending = true
+		go c.refresh(p, e)
+	}
+
+	if e.size <= 0 {
+		return defaultSourceSize
+	}
+	return e.size
+}
+
+func (c *sizeCache) refresh(p string, e *sizeEntry) {
+	ctx, cancel := context.WithTimeout(context.Background(), sizeTimeout)
+	defer cancel()
+
+	size, err := c.lookup(ctx, p)
+	if err != nil {
+		// the source is looked up again once expired, not on every request
+		slog.Debug("error getting the size of a source", "source", logging.RedactURL(p), "err", err)
+		size = 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e.size = size
+	e.expires = time.Now().Add(sizeTTL)
+	e.pending = false
+}
+
+// evict makes room for a new entry, dropping the expired entries first. Must
+// be called with c.mu held.
+func (c *sizeCache) evict() {
+	if len(c.entries) < maxCachedSizes {
+		return
+	}
+
+	now := time.Now()
+	for p, e := range c.entries {
+		if !e.pending && !now.Before(e.expires) {
+			delete(c.entries, p)
+		}
+	}
+	for p, e := range c.entries {
+		if len(c.entries) < maxCachedSizes {
+			break
+		}
+		if !e.pending {
+			delete(c.entries, p)
+		}
+	}
+}
+
+// admissionUnaryInterceptor admits unary requests before they reach the
+// handler.
+func admissionUnaryInterceptor(ac *admission.Controller, sizeOf sourceSizer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		in, ok := queryIn(req)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		release, err := ac.Acquire(ctx, estimateWeight(ctx, sizeOf, in), requestPriority(ctx))
+		if err != nil {
+			slog.WarnContext(ctx, "request not admitted", "err", err)
+			return nil, err
+		}
+		defer release()
+
+		return handler(ctx, req)
+	}
+}
+
+// admissionStreamInterceptor admits streaming requests once their QueryIn is
+// received, as the weight depends on it.
+func admissionStreamInterceptor(ac *admission.Controller, sizeOf sourceSizer) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		stream := &admittedStream{ServerStream: ss, ac: ac, sizeOf: sizeOf, method: info.FullMethod}
+		defer func() {
+			if stream.release != nil {
+				stream.release()
+			}
+		}()
+
+		return handler(srv, stream)
+	}
+}
+
+type admittedStream struct {
+	grpc.ServerStream
+	ac      *admission.Controller
+	sizeOf  sourceSizer
+	method  string
+	release func()
+}
+
+func (s *admittedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	in, ok := queryIn(m)
+	if !ok || s.release != nil {
+		return nil
+	}
+
+	ctx := s.Context()
+	release, err := s.ac.Acquire(ctx, estimateWeight(ctx, s.sizeOf, in), requestPriority(ctx))
+	if err != nil {
+		slog.WarnContext(ctx, "request not admitted", "err", err)
+		return err
+	}
+
+	s.release = release
+	return nil
+}
+
+func requestPriority(ctx context.Context) int {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(priorityHeader)
+	if len(values) == 0 {
+		return 0
+	}
+
+	priority, err := strconv.Atoi(values[0])
+	if err != nil {
+		return 0
+	}
+	return priority
+}
+
+// estimateWeight estimates the memory a request needs from the size of its
+// source and the shape of its query. The source is loaded into a table and
+// then transformed, grouping sets multiplying the size of the result.
+func estimateWeight(ctx context.Context, sizeOf sourceSizer, in *pb.QueryIn) int64 {
+	size := sizeOf(ctx, in.Path)
+	return 2 * size * queryShapeFactor(in)
+}
+
+var groupingKeywords = regexp.MustCompile(`(?i)\b(grouping\s+sets|rollup|cube)\b`)
+
+func queryShapeFactor(in *pb.QueryIn) int64 {
+	var factor int64 = 1
+
+	switch {
+	case in.Subtotal != nil:
+		factor = subtotalFactor(in.Subtotal)
+	case in.Pipeline != nil:
+		for _, step := range in.Pipeline.Steps {
+			switch kind := step.Kind.(type) {
+			case *pb.Step_GroupingSets:
+				sets := make([]querybuilder.GroupingSet, len(kind.GroupingSets.Sets))
+				for i, set := range kind.GroupingSets.Sets {
+					sets[i] = querybuilder.GroupingSet{Columns: set.Columns}
+				}
+				factor += int64(len(querybuilder.DedupGroupingSets(sets)))
+			case *pb.Step_Subtotal:
+				factor += subtotalFactor(kind.Subtotal)
+			case *pb.Step_Sort:
+				factor += 1
+			}
+		}
+	case in.Query == "":
+		// the customers load test, a rollup over ten dimensions
+		factor = 11
+	default:
+		if groupingKeywords.MatchString(in.Query) {
+			factor = 4
+		}
+	}
+
+	return min(factor, maxShapeFactor)
+}
+
+func subtotalFactor(s *pb.SubtotalStep) int64 {
+	if len(s.Levels) > 0 {
+		return int64(len(s.Levels))
+	}
+	return int64(len(s.Dimensions) + 1)
+}
@@ -0,0 +1,112 @@
+package grpc_arrow
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "duckdb-server/internal/services/grpc_arrow/data_transform"
+)
+
+func TestSizeCache(t *testing.T) {
+	var lookups atomic.Int32
+	release := make(chan struct{})
+	c := newSizeCache(func(ctx context.Context, p string) (int64, error) {
+		lookups.Add(1)
+		<-release
+		if p == "https://example.com/missing.csv" {
+			return 0, errors.New("not found")
+		}
+		return 42, nil
+	})
+
+	const source = "https://example.com/data.csv"
+
+	// unknown sizes don't wait for the lookup
+	for range 3 {
+		if got := c.get(source); got != defaultSourceSize {
+			t.Fatalf("size %d while looked up, want %d", got, defaultSourceSize)
+		}
+	}
+	if got := c.get("https://example.com/missing.csv"); got != defaultSourceSize {
+		t.Fatalf("size %d while looked up, want %d", got, defaultSourceSize)
+	}
+	close(release)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for c.get(source) != 42 {
+		if time.Now().After(deadline) {
+			t.Fatal("size not cached")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := c.get("https://example.com/missing.csv"); got != defaultSourceSize {
+		t.Errorf("size %d of a failed lookup, want %d", got, defaultSourceSize)
+	}
+	if n := lookups.Load(); n != 2 {
+		t.Errorf("%d lookups, want one per source", n)
+	}
+
+	// expired sizes are used until refreshed
+	c.mu.Lock()
+	c.entries[source].expires = time.Now()
+	c.mu.Unlock()
+	if got := c.get(source); got != 42 {
+		t.Errorf("expired size %d, want 42", got)
+	}
+	for lookups.Load() != 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("expired size not refreshed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSizeCacheEvicts(t *testing.T) {
+	c := newSizeCache(func(ctx context.Context, p string) (int64, error) { return 1, nil })
+	c.mu.Lock()
+	for i := range maxCachedSizes {
+		c.entries[string(rune(i))] = &sizeEntry{size: 1, expires: time.Now().Add(time.Hour)}
+	}
+	c.mu.Unlock()
+
+	c.get("new")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) > maxCachedSizes {
+		t.Errorf("%d entries, want at most %d", len(c.entries), maxCachedSizes)
+	}
+	if _, ok := c.entries["new"]; !ok {
+		t.Error("new entry not added")
+	}
+}
+
+func TestQueryShapeFactor(t *testing.T) {
+	subtotal := &pb.SubtotalStep{Dimensions: []string{"a", "b", "c"}}
+
+	tests := []struct {
+		name string
+		in   *pb.QueryIn
+		want int64
+	}{
+		{"plain query", &pb.QueryIn{Query: "SELECT * FROM loadtest"}, 1},
+		{"rollup", &pb.QueryIn{Query: "SELECT a, count(*) FROM loadtest GROUP BY ROLLUP (a)"}, 4},
+		{"grouping sets", &pb.QueryIn{Query: "SELECT 1 FROM loadtest GROUP BY grouping  sets ((a), ())"}, 4},
+		{"load test", &pb.QueryIn{}, 11},
+		{"subtotal", &pb.QueryIn{Subtotal: subtotal}, 4},
+		{"subtotal levels", &pb.QueryIn{Subtotal: &pb.SubtotalStep{Dimensions: []string{"a", "b"}, Levels: []int32{0, 2}}}, 2},
+		{"pipeline", &pb.QueryIn{Pipeline: &pb.Pipeline{Steps: []*pb.Step{
+			{Kind: &pb.Step_GroupingSets{GroupingSets: &pb.GroupingSetsStep{Sets: []*pb.GroupingSet{{Columns: []string{"a"}}, {Columns: []string{"a"}}, {}}}}},
+			{Kind: &pb.Step_Sort{Sort: &pb.SortStep{}}},
+			{Kind: &pb.Step_Subtotal{Subtotal: subtotal}},
+		}}}, 1 + 2 + 1 + 4},
+		{"capped", &pb.QueryIn{Subtotal: &pb.SubtotalStep{Dimensions: make([]string, 40)}}, maxShapeFactor},
+	}
+	for _, tt := range tests {
+		if got := queryShapeFactor(tt.in); got != tt.want {
+			t.Errorf("%s: factor %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package grpc_arrow
+
+import (
+	"context"
+	"duckdb-server/internal/auth"
+	"duckdb-server/internal/logging"
+	"duckdb-server/internal/tlsconfig"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	grpc "google.golang.org/grpc"
+)
+
+// auditUnaryInterceptor logs who called which method on which source. The
+// caller is the principal of the bearer token and, with mutual TLS, the
+// verified client certificate. Handlers can get them through
+// auth.FromContext and tlsconfig.IdentityFromContext.
+func auditUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	logRequest(ctx, info.FullMethod, req)
+	return handler(ctx, req)
+}
+
+// auditStreamInterceptor logs the request once its QueryIn is received.
+func auditStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &auditedStream{ServerStream: ss, method: info.FullMethod})
+}
+
+type auditedStream struct {
+	grpc.ServerStream
+	method string
+	logged bool
+}
+
+func (s *auditedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	if !s.logged {
+		s.logged = true
+		logRequest(s.Context(), s.method, m)
+	}
+	return nil
+}
+
+func logRequest(ctx context.Context, method string, req any) {
+	attrs := []any{"method", method, "caller", caller(ctx)}
+	if in, ok := queryIn(req); ok {
+		attrs = append(attrs, logging.Source(in.Path))
+		if in.Query != "" {
+			attrs = append(attrs, logging.SQL(in.Query))
+		}
+		if in.Destination != "" {
+			attrs = append(attrs, "destination", in.Destination)
+		}
+	}
+	level := slog.LevelInfo
+	if quietMethods[method] {
+		level = slog.LevelDebug
+	}
+	slog.Log(ctx, level, "request started", attrs...)
+}
+
+func caller(ctx context.Context) string {
+	var parts []string
+	if p, ok := auth.FromContext(ctx); ok {
+		parts = append(parts, fmt.Sprintf("%s (%s)", p.Subject, p.Method))
+	}
+	if id, ok := tlsconfig.IdentityFromContext(ctx); ok {
+		parts = append(parts, fmt.Sprintf("certificate %s", id))
+	}
+
+	if len(parts) == 0 {
+		return "anonymous"
+	}
+	return strings.Join(parts, ", ")
+}
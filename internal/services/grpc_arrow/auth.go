@@ -0,0 +1,169 @@
+package grpc_arrow
+
+import (
+	"context"
+	"duckdb-server/internal/auth"
+	pb "duckdb-server/internal/services/grpc_arrow/data_transform"
+	"errors"
+	"log/slog"
+	"strings"
+
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// methodPermissions is the permission every RPC requires, on top of the ones
+// depending on the request (see requestPermissions). Methods missing from the
+// map are only available to authenticated callers when listed in
+// authenticatedMethods, to everyone when listed in publicMethods, and denied
+// otherwise.
+var methodPermissions = map[string]auth.Permission{
+	pb.DataTransform_TransformAndStreamArrow_FullMethodName:        auth.PermTransform,
+	pb.DataTransform_TransformAndStreamParquet_FullMethodName:      auth.PermTransform,
+	pb.DataTransform_TransformAndStreamJSON_FullMethodName:         auth.PermTransform,
+	pb.DataTransform_LocalTransformAndStreamArrow_FullMethodName:   auth.PermTransform,
+	pb.DataTransform_LocalTransformAndStreamParquet_FullMethodName: auth.PermTransform,
+	pb.DataTransform_LocalTransformAndStreamJSON_FullMethodName:    auth.PermTransform,
+	pb.DataTransform_CompilePipeline_FullMethodName:                auth.PermTransform,
+	pb.DataTransform_Explain_FullMethodName:                        auth.PermTransform,
+	pb.Admin_CacheStats_FullMethodName:                             auth.PermAdmin,
+	pb.Admin_CaptureProfile_FullMethodName:                         auth.PermAdmin,
+	pb.Admin_QueryHistory_FullMethodName:                           auth.PermAdmin,
+	pb.Admin_ReloadConfig_FullMethodName:                           auth.PermAdmin,
+}
+
+var authenticatedMethods = map[string]bool{
+	"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo":      true,
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo": true,
+}
+
+// requestPermissions returns the permissions needed by the content of the
+// request: reading the source, uploading the export and running raw SQL.
+func requestPermissions(in *pb.QueryIn) []auth.Permission {
+	var perms []auth.Permission
+
+	if isRemote(in.Path) {
+		perms = append(perms, auth.PermRemoteDownload)
+	} else {
+		perms = append(perms, auth.PermLocalPath)
+	}
+
+	if in.Destination != "" {
+		perms = append(perms, auth.PermExport)
+	}
+
+	if in.Query != "" && in.Pipeline == nil && in.Subtotal == nil {
+		perms = append(perms, auth.PermRawSQL)
+	}
+
+	return perms
+}
+
+func authUnaryInterceptor(a *auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		ctx, err := authorize(ctx, a, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		if in, ok := queryIn(req); ok {
+			if err := authorizeRequest(ctx, a, info.FullMethod, in); err != nil {
+				return nil, err
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func authStreamInterceptor(a *auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if publicMethods[info.FullMethod] {
+			return handler(srv, ss)
+		}
+
+		ctx, err := authorize(ss.Context(), a, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &authorizedStream{ServerStream: ss, ctx: ctx, auth: a, method: info.FullMethod})
+	}
+}
+
+// authorizedStream carries the principal in its context and checks the
+// permissions needed by the request once it is received.
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	auth   *auth.Authenticator
+	method string
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *authorizedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	if in, ok := queryIn(m); ok {
+		return authorizeRequest(s.ctx, s.auth, s.method, in)
+	}
+	return nil
+}
+
+// authorize authenticates the caller and checks it may call the method. The
+// returned context carries the principal.
+func authorize(ctx context.Context, a *auth.Authenticator, method string) (context.Context, error) {
+	principal, err := a.Authenticate(bearerToken(ctx))
+	if err != nil {
+		slog.WarnContext(ctx, "authentication failed", "method", method, "err", err)
+		if errors.Is(err, auth.ErrUnauthenticated) {
+			return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid bearer token")
+	}
+
+	perm, ok := methodPermissions[method]
+	switch {
+	case ok && !a.Allowed(principal, perm):
+		slog.WarnContext(ctx, "permission denied", "method", method, "subject", principal.Subject, "permission", perm)
+		return nil, status.Errorf(codes.PermissionDenied, "%s permission required", perm)
+	case !ok && !authenticatedMethods[method]:
+		slog.WarnContext(ctx, "method has no permission configured", "method", method, "subject", principal.Subject)
+		return nil, status.Error(codes.PermissionDenied, "method not allowed")
+	}
+
+	return auth.NewContext(ctx, principal), nil
+}
+
+func authorizeRequest(ctx context.Context, a *auth.Authenticator, method string, in *pb.QueryIn) error {
+	principal, _ := auth.FromContext(ctx)
+	for _, perm := range requestPermissions(in) {
+		if !a.Allowed(principal, perm) {
+			slog.WarnContext(ctx, "permission denied", "method", method, "subject", principal.Subject, "permission", perm)
+			return status.Errorf(codes.PermissionDenied, "%s permission required", perm)
+		}
+	}
+	return nil
+}
+
+func bearerToken(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		scheme, token, ok := strings.Cut(value, " ")
+		if ok && strings.EqualFold(scheme, "bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return ""
+}
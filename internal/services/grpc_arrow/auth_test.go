@@ -0,0 +1,167 @@
+package grpc_arrow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"duckdb-server/internal/auth"
+	pb "duckdb-server/internal/services/grpc_arrow/data_transform"
+
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// newTestAuthenticator returns an authenticator with an API key per role,
+// named after it.
+func newTestAuthenticator(t *testing.T) *auth.Authenticator {
+	t.Helper()
+	roles := map[string][]auth.Permission{
+		"transform": {auth.PermTransform},
+		"admin":     {auth.PermAdmin},
+		"none":      {},
+		"local_sql": {auth.PermTransform, auth.PermLocalPath, auth.PermRawSQL},
+	}
+	policy := auth.Policy{Roles: roles}
+	for role := range roles {
+		sum := sha256.Sum256([]byte(role + "-key"))
+		policy.APIKeys = append(policy.APIKeys, auth.APIKey{Name: role, SHA256: hex.EncodeToString(sum[:]), Roles: []string{role}})
+	}
+
+	data, err := json.Marshal(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	a, err := auth.NewAuthenticator(auth.Config{PolicyFile: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// withToken returns a context of an incoming request carrying the token.
+func withToken(token string) context.Context {
+	ctx := context.Background()
+	if token == "" {
+		return ctx
+	}
+	return metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+token))
+}
+
+// testStream is a server stream receiving msg.
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	msg proto.Message
+}
+
+func (s *testStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testStream) RecvMsg(m any) error {
+	proto.Merge(m.(proto.Message), s.msg)
+	return nil
+}
+
+// callBoth calls the method through the unary and the stream interceptors,
+// returning their errors and whether the handlers were called with the
+// principal.
+func callBoth(a *auth.Authenticator, method, token string, req proto.Message) (unaryErr, streamErr error, called int) {
+	_, unaryErr = authUnaryInterceptor(a)(withToken(token), req, &grpc.UnaryServerInfo{FullMethod: method},
+		func(ctx context.Context, req any) (any, error) {
+			if _, ok := auth.FromContext(ctx); ok || publicMethods[method] {
+				called++
+			}
+			return nil, nil
+		})
+
+	ss := &testStream{ctx: withToken(token), msg: req}
+	streamErr = authStreamInterceptor(a)(nil, ss, &grpc.StreamServerInfo{FullMethod: method},
+		func(srv any, stream grpc.ServerStream) error {
+			if _, ok := auth.FromContext(stream.Context()); !ok && !publicMethods[method] {
+				return nil
+			}
+			if req != nil {
+				if err := stream.RecvMsg(proto.Clone(req)); err != nil {
+					return err
+				}
+			}
+			called++
+			return nil
+		})
+	return unaryErr, streamErr, called
+}
+
+func TestAuthInterceptors(t *testing.T) {
+	a := newTestAuthenticator(t)
+
+	for method, perm := range methodPermissions {
+		tests := []struct {
+			token string
+			want  codes.Code
+		}{
+			{"", codes.Unauthenticated},
+			{"unknown-key", codes.Unauthenticated},
+			{"none-key", codes.PermissionDenied},
+			{string(perm) + "-key", codes.OK},
+		}
+		for _, tt := range tests {
+			unaryErr, streamErr, called := callBoth(a, method, tt.token, nil)
+			if status.Code(unaryErr) != tt.want || status.Code(streamErr) != tt.want {
+				t.Errorf("%s with %q: unary %v, stream %v, want %s", method, tt.token, unaryErr, streamErr, tt.want)
+			}
+			want := 0
+			if tt.want == codes.OK {
+				want = 2
+			}
+			if called != want {
+				t.Errorf("%s with %q: %d handlers called, want %d", method, tt.token, called, want)
+			}
+		}
+	}
+
+	// methods without permission
+	for method, want := range map[string]codes.Code{
+		"/duckdb.Other/Method": codes.PermissionDenied,
+		"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo": codes.OK,
+	} {
+		unaryErr, streamErr, _ := callBoth(a, method, "none-key", nil)
+		if status.Code(unaryErr) != want || status.Code(streamErr) != want {
+			t.Errorf("%s: unary %v, stream %v, want %s", method, unaryErr, streamErr, want)
+		}
+	}
+	unaryErr, streamErr, called := callBoth(a, healthpb.Health_Check_FullMethodName, "", nil)
+	if unaryErr != nil || streamErr != nil || called != 2 {
+		t.Errorf("health check without token: unary %v, stream %v, %d handlers called", unaryErr, streamErr, called)
+	}
+
+	// the request needs permissions of its own
+	method := pb.DataTransform_TransformAndStreamArrow_FullMethodName
+	rawSQL := &pb.QueryIn{Path: "/data/a.csv", Query: "SELECT 1"}
+	for token, want := range map[string]codes.Code{
+		"transform-key": codes.PermissionDenied,
+		"local_sql-key": codes.OK,
+	} {
+		unaryErr, streamErr, _ := callBoth(a, method, token, rawSQL)
+		if status.Code(unaryErr) != want || status.Code(streamErr) != want {
+			t.Errorf("raw SQL with %q: unary %v, stream %v, want %s", token, unaryErr, streamErr, want)
+		}
+	}
+	unaryErr, _, _ = callBoth(a, pb.DataTransform_Explain_FullMethodName, "transform-key", &pb.ExplainIn{Query: rawSQL})
+	if status.Code(unaryErr) != codes.PermissionDenied {
+		t.Errorf("explaining raw SQL: %v, want PermissionDenied", unaryErr)
+	}
+}
@@ -9,7 +9,6 @@ package data_transform_arrow
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
-	_ "google.golang.org/protobuf/types/known/emptypb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -21,6 +20,157 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Layout of the JSON stream. With ARRAY the concatenated chunks form a single
+// JSON array, with NDJSON, the default, every row is a JSON object on its own
+// line.
+type JSONFormat int32
+
+const (
+	JSONFormat_JSON_FORMAT_UNSPECIFIED JSONFormat = 0
+	JSONFormat_NDJSON                  JSONFormat = 1
+	JSONFormat_ARRAY                   JSONFormat = 2
+)
+
+// Enum value maps for JSONFormat.
+var (
+	JSONFormat_name = map[int32]string{
+		0: "JSON_FORMAT_UNSPECIFIED",
+		1: "NDJSON",
+		2: "ARRAY",
+	}
+	JSONFormat_value = map[string]int32{
+		"JSON_FORMAT_UNSPECIFIED": 0,
+		"NDJSON":                  1,
+		"ARRAY":                   2,
+	}
+)
+
+func (x JSONFormat) Enum() *JSONFormat {
+	p := new(JSONFormat)
+	*p = x
+	return p
+}
+
+func (x JSONFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JSONFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_enumTypes[0].Descriptor()
+}
+
+func (JSONFormat) Type() protoreflect.EnumType {
+	return &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_enumTypes[0]
+}
+
+func (x JSONFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JSONFormat.Descriptor instead.
+func (JSONFormat) EnumDescriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{0}
+}
+
+// How DECIMAL (and HUGEINT) columns are rendered, as strings by default.
+type DecimalEncoding int32
+
+const (
+	DecimalEncoding_DECIMAL_ENCODING_UNSPECIFIED DecimalEncoding = 0
+	DecimalEncoding_DECIMAL_AS_STRING            DecimalEncoding = 1
+	DecimalEncoding_DECIMAL_AS_NUMBER            DecimalEncoding = 2
+)
+
+// Enum value maps for DecimalEncoding.
+var (
+	DecimalEncoding_name = map[int32]string{
+		0: "DECIMAL_ENCODING_UNSPECIFIED",
+		1: "DECIMAL_AS_STRING",
+		2: "DECIMAL_AS_NUMBER",
+	}
+	DecimalEncoding_value = map[string]int32{
+		"DECIMAL_ENCODING_UNSPECIFIED": 0,
+		"DECIMAL_AS_STRING":            1,
+		"DECIMAL_AS_NUMBER":            2,
+	}
+)
+
+func (x DecimalEncoding) Enum() *DecimalEncoding {
+	p := new(DecimalEncoding)
+	*p = x
+	return p
+}
+
+func (x DecimalEncoding) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DecimalEncoding) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_enumTypes[1].Descriptor()
+}
+
+func (DecimalEncoding) Type() protoreflect.EnumType {
+	return &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_enumTypes[1]
+}
+
+func (x DecimalEncoding) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DecimalEncoding.Descriptor instead.
+func (DecimalEncoding) EnumDescriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{1}
+}
+
+type ProfileKind int32
+
+const (
+	ProfileKind_PROFILE_CPU       ProfileKind = 0
+	ProfileKind_PROFILE_HEAP      ProfileKind = 1
+	ProfileKind_PROFILE_GOROUTINE ProfileKind = 2
+)
+
+// Enum value maps for ProfileKind.
+var (
+	ProfileKind_name = map[int32]string{
+		0: "PROFILE_CPU",
+		1: "PROFILE_HEAP",
+		2: "PROFILE_GOROUTINE",
+	}
+	ProfileKind_value = map[string]int32{
+		"PROFILE_CPU":       0,
+		"PROFILE_HEAP":      1,
+		"PROFILE_GOROUTINE": 2,
+	}
+)
+
+func (x ProfileKind) Enum() *ProfileKind {
+	p := new(ProfileKind)
+	*p = x
+	return p
+}
+
+func (x ProfileKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ProfileKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_enumTypes[2].Descriptor()
+}
+
+func (ProfileKind) Type() protoreflect.EnumType {
+	return &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_enumTypes[2]
+}
+
+func (x ProfileKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ProfileKind.Descriptor instead.
+func (ProfileKind) EnumDescriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{2}
+}
+
 type QueryOut struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -29,6 +179,9 @@ type QueryOut struct {
 	SequencyNumber int32    `protobuf:"varint,1,opt,name=sequency_number,json=sequencyNumber,proto3" json:"sequency_number,omitempty"`
 	Count          int32    `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
 	Data           [][]byte `protobuf:"bytes,3,rep,name=data,proto3" json:"data,omitempty"`
+	// Only set on the progress messages, which carry no data, when asked for
+	// with QueryIn.progress_interval_ms.
+	Progress *Progress `protobuf:"bytes,4,opt,name=progress,proto3" json:"progress,omitempty"`
 }
 
 func (x *QueryOut) Reset() {
@@ -84,17 +237,37 @@ func (x *QueryOut) GetData() [][]byte {
 	return nil
 }
 
-type QueryIn struct {
+func (x *QueryOut) GetProgress() *Progress {
+	if x != nil {
+		return x.Progress
+	}
+	return nil
+}
+
+type Progress struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Path  string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Query string `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	// Current phase of the request: download, load, transform, view, query
+	// or encode.
+	Phase string `protobuf:"bytes,1,opt,name=phase,proto3" json:"phase,omitempty"`
+	// Bytes of the source downloaded so far and its size, -1 when unknown.
+	// Both are 0 when nothing is downloaded.
+	DownloadBytes      int64 `protobuf:"varint,2,opt,name=download_bytes,json=downloadBytes,proto3" json:"download_bytes,omitempty"`
+	DownloadTotalBytes int64 `protobuf:"varint,3,opt,name=download_total_bytes,json=downloadTotalBytes,proto3" json:"download_total_bytes,omitempty"`
+	// Progress of the running DuckDB query, in percent, -1 when unknown or
+	// when no query runs.
+	QueryPercent  float64 `protobuf:"fixed64,4,opt,name=query_percent,json=queryPercent,proto3" json:"query_percent,omitempty"`
+	RowsEmitted   int64   `protobuf:"varint,5,opt,name=rows_emitted,json=rowsEmitted,proto3" json:"rows_emitted,omitempty"`
+	ChunksEmitted int64   `protobuf:"varint,6,opt,name=chunks_emitted,json=chunksEmitted,proto3" json:"chunks_emitted,omitempty"`
+	ElapsedMs     int64   `protobuf:"varint,7,opt,name=elapsed_ms,json=elapsedMs,proto3" json:"elapsed_ms,omitempty"`
+	// Estimated time left in the current download or query, -1 when unknown.
+	EtaMs int64 `protobuf:"varint,8,opt,name=eta_ms,json=etaMs,proto3" json:"eta_ms,omitempty"`
 }
 
-func (x *QueryIn) Reset() {
-	*x = QueryIn{}
+func (x *Progress) Reset() {
+	*x = Progress{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[1]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -102,13 +275,13 @@ func (x *QueryIn) Reset() {
 	}
 }
 
-func (x *QueryIn) String() string {
+func (x *Progress) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*QueryIn) ProtoMessage() {}
+func (*Progress) ProtoMessage() {}
 
-func (x *QueryIn) ProtoReflect() protoreflect.Message {
+func (x *Progress) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[1]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -120,151 +293,3323 @@ func (x *QueryIn) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use QueryIn.ProtoReflect.Descriptor instead.
-func (*QueryIn) Descriptor() ([]byte, []int) {
+// Deprecated: Use Progress.ProtoReflect.Descriptor instead.
+func (*Progress) Descriptor() ([]byte, []int) {
 	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *QueryIn) GetPath() string {
+func (x *Progress) GetPhase() string {
 	if x != nil {
-		return x.Path
+		return x.Phase
 	}
 	return ""
 }
 
-func (x *QueryIn) GetQuery() string {
+func (x *Progress) GetDownloadBytes() int64 {
 	if x != nil {
-		return x.Query
+		return x.DownloadBytes
 	}
-	return ""
+	return 0
 }
 
-var File_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto protoreflect.FileDescriptor
+func (x *Progress) GetDownloadTotalBytes() int64 {
+	if x != nil {
+		return x.DownloadTotalBytes
+	}
+	return 0
+}
 
-var file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDesc = []byte{
-	0x0a, 0x45, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x69,
-	0x63, 0x65, 0x73, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2f, 0x64,
-	0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x2f, 0x64, 0x61,
-	0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f,
-	0x77, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x14, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72,
-	0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x1a, 0x1b, 0x67,
-	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65,
-	0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x5d, 0x0a, 0x08, 0x51, 0x75,
-	0x65, 0x72, 0x79, 0x4f, 0x75, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e,
-	0x63, 0x79, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
-	0x0e, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x79, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12,
-	0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05,
-	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20,
-	0x03, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x33, 0x0a, 0x07, 0x51, 0x75, 0x65,
-	0x72, 0x79, 0x49, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72,
-	0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x32, 0x95,
-	0x03, 0x0a, 0x0d, 0x44, 0x61, 0x74, 0x61, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d,
-	0x12, 0x5c, 0x0a, 0x17, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x41, 0x6e, 0x64,
-	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x41, 0x72, 0x72, 0x6f, 0x77, 0x12, 0x1d, 0x2e, 0x64, 0x61,
-	0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72,
-	0x6f, 0x77, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x49, 0x6e, 0x1a, 0x1e, 0x2e, 0x64, 0x61, 0x74,
-	0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f,
-	0x77, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x4f, 0x75, 0x74, 0x22, 0x00, 0x30, 0x01, 0x12, 0x5e,
-	0x0a, 0x19, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x41, 0x6e, 0x64, 0x53, 0x74,
-	0x72, 0x65, 0x61, 0x6d, 0x50, 0x61, 0x72, 0x71, 0x75, 0x65, 0x74, 0x12, 0x1d, 0x2e, 0x64, 0x61,
-	0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72,
-	0x6f, 0x77, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x49, 0x6e, 0x1a, 0x1e, 0x2e, 0x64, 0x61, 0x74,
-	0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f,
-	0x77, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x4f, 0x75, 0x74, 0x22, 0x00, 0x30, 0x01, 0x12, 0x61,
-	0x0a, 0x1c, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d,
-	0x41, 0x6e, 0x64, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x41, 0x72, 0x72, 0x6f, 0x77, 0x12, 0x1d,
-	0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f,
-	0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x49, 0x6e, 0x1a, 0x1e, 0x2e,
-	0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61,
-	0x72, 0x72, 0x6f, 0x77, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x4f, 0x75, 0x74, 0x22, 0x00, 0x30,
-	0x01, 0x12, 0x63, 0x0a, 0x1e, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66,
-	0x6f, 0x72, 0x6d, 0x41, 0x6e, 0x64, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x50, 0x61, 0x72, 0x71,
-	0x75, 0x65, 0x74, 0x12, 0x1d, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73,
-	0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79,
-	0x49, 0x6e, 0x1a, 0x1e, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66,
-	0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x4f,
-	0x75, 0x74, 0x22, 0x00, 0x30, 0x01, 0x42, 0x20, 0x5a, 0x1e, 0x67, 0x6f, 0x2d, 0x64, 0x75, 0x63,
-	0x6b, 0x64, 0x62, 0x2f, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f,
-	0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *Progress) GetQueryPercent() float64 {
+	if x != nil {
+		return x.QueryPercent
+	}
+	return 0
 }
 
-var (
-	file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescOnce sync.Once
-	file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescData = file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDesc
-)
+func (x *Progress) GetRowsEmitted() int64 {
+	if x != nil {
+		return x.RowsEmitted
+	}
+	return 0
+}
 
-func file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP() []byte {
-	file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescOnce.Do(func() {
-		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescData = protoimpl.X.CompressGZIP(file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescData)
-	})
-	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescData
+func (x *Progress) GetChunksEmitted() int64 {
+	if x != nil {
+		return x.ChunksEmitted
+	}
+	return 0
 }
 
-var file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
-var file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_goTypes = []any{
-	(*QueryOut)(nil), // 0: data_transform_arrow.QueryOut
-	(*QueryIn)(nil),  // 1: data_transform_arrow.QueryIn
+func (x *Progress) GetElapsedMs() int64 {
+	if x != nil {
+		return x.ElapsedMs
+	}
+	return 0
 }
-var file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_depIdxs = []int32{
-	1, // 0: data_transform_arrow.DataTransform.TransformAndStreamArrow:input_type -> data_transform_arrow.QueryIn
-	1, // 1: data_transform_arrow.DataTransform.TransformAndStreamParquet:input_type -> data_transform_arrow.QueryIn
-	1, // 2: data_transform_arrow.DataTransform.LocalTransformAndStreamArrow:input_type -> data_transform_arrow.QueryIn
-	1, // 3: data_transform_arrow.DataTransform.LocalTransformAndStreamParquet:input_type -> data_transform_arrow.QueryIn
-	0, // 4: data_transform_arrow.DataTransform.TransformAndStreamArrow:output_type -> data_transform_arrow.QueryOut
-	0, // 5: data_transform_arrow.DataTransform.TransformAndStreamParquet:output_type -> data_transform_arrow.QueryOut
-	0, // 6: data_transform_arrow.DataTransform.LocalTransformAndStreamArrow:output_type -> data_transform_arrow.QueryOut
-	0, // 7: data_transform_arrow.DataTransform.LocalTransformAndStreamParquet:output_type -> data_transform_arrow.QueryOut
-	4, // [4:8] is the sub-list for method output_type
-	0, // [0:4] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+
+func (x *Progress) GetEtaMs() int64 {
+	if x != nil {
+		return x.EtaMs
+	}
+	return 0
 }
 
-func init() { file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_init() }
-func file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_init() {
-	if File_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto != nil {
-		return
+type JSONOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Format   JSONFormat      `protobuf:"varint,1,opt,name=format,proto3,enum=data_transform_arrow.JSONFormat" json:"format,omitempty"`
+	Decimals DecimalEncoding `protobuf:"varint,2,opt,name=decimals,proto3,enum=data_transform_arrow.DecimalEncoding" json:"decimals,omitempty"`
+	// Upper bound for the size of a chunk in bytes, defaults to FILE_CHUNK_SIZE.
+	// Only a chunk made of a single larger row exceeds it.
+	ChunkBytes int32 `protobuf:"varint,3,opt,name=chunk_bytes,json=chunkBytes,proto3" json:"chunk_bytes,omitempty"`
+}
+
+func (x *JSONOptions) Reset() {
+	*x = JSONOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[0].Exporter = func(v any, i int) any {
-			switch v := v.(*QueryOut); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+}
+
+func (x *JSONOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JSONOptions) ProtoMessage() {}
+
+func (x *JSONOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[1].Exporter = func(v any, i int) any {
-			switch v := v.(*QueryIn); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JSONOptions.ProtoReflect.Descriptor instead.
+func (*JSONOptions) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *JSONOptions) GetFormat() JSONFormat {
+	if x != nil {
+		return x.Format
+	}
+	return JSONFormat_JSON_FORMAT_UNSPECIFIED
+}
+
+func (x *JSONOptions) GetDecimals() DecimalEncoding {
+	if x != nil {
+		return x.Decimals
+	}
+	return DecimalEncoding_DECIMAL_ENCODING_UNSPECIFIED
+}
+
+func (x *JSONOptions) GetChunkBytes() int32 {
+	if x != nil {
+		return x.ChunkBytes
+	}
+	return 0
+}
+
+// Declarative transformation, compiled to a chain of CTEs over the loaded
+// table. The JSON form (proto field names) is accepted by the query builder
+// as is.
+type Pipeline struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Steps []*Step `protobuf:"bytes,1,rep,name=steps,proto3" json:"steps,omitempty"`
+}
+
+func (x *Pipeline) Reset() {
+	*x = Pipeline{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Pipeline) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pipeline) ProtoMessage() {}
+
+func (x *Pipeline) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pipeline.ProtoReflect.Descriptor instead.
+func (*Pipeline) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Pipeline) GetSteps() []*Step {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+type Step struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Kind:
+	//	*Step_Select
+	//	*Step_Rename
+	//	*Step_FillNulls
+	//	*Step_Filter
+	//	*Step_Derive
+	//	*Step_Aggregate
+	//	*Step_GroupingSets
+	//	*Step_Sort
+	//	*Step_Limit
+	//	*Step_Subtotal
+	Kind isStep_Kind `protobuf_oneof:"kind"`
+}
+
+func (x *Step) Reset() {
+	*x = Step{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Step) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Step) ProtoMessage() {}
+
+func (x *Step) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Step.ProtoReflect.Descriptor instead.
+func (*Step) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{4}
+}
+
+func (m *Step) GetKind() isStep_Kind {
+	if m != nil {
+		return m.Kind
+	}
+	return nil
+}
+
+func (x *Step) GetSelect() *SelectStep {
+	if x, ok := x.GetKind().(*Step_Select); ok {
+		return x.Select
+	}
+	return nil
+}
+
+func (x *Step) GetRename() *RenameStep {
+	if x, ok := x.GetKind().(*Step_Rename); ok {
+		return x.Rename
+	}
+	return nil
+}
+
+func (x *Step) GetFillNulls() *FillNullsStep {
+	if x, ok := x.GetKind().(*Step_FillNulls); ok {
+		return x.FillNulls
+	}
+	return nil
+}
+
+func (x *Step) GetFilter() *FilterStep {
+	if x, ok := x.GetKind().(*Step_Filter); ok {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *Step) GetDerive() *DeriveStep {
+	if x, ok := x.GetKind().(*Step_Derive); ok {
+		return x.Derive
+	}
+	return nil
+}
+
+func (x *Step) GetAggregate() *AggregateStep {
+	if x, ok := x.GetKind().(*Step_Aggregate); ok {
+		return x.Aggregate
+	}
+	return nil
+}
+
+func (x *Step) GetGroupingSets() *GroupingSetsStep {
+	if x, ok := x.GetKind().(*Step_GroupingSets); ok {
+		return x.GroupingSets
+	}
+	return nil
+}
+
+func (x *Step) GetSort() *SortStep {
+	if x, ok := x.GetKind().(*Step_Sort); ok {
+		return x.Sort
+	}
+	return nil
+}
+
+func (x *Step) GetLimit() *LimitStep {
+	if x, ok := x.GetKind().(*Step_Limit); ok {
+		return x.Limit
+	}
+	return nil
+}
+
+func (x *Step) GetSubtotal() *SubtotalStep {
+	if x, ok := x.GetKind().(*Step_Subtotal); ok {
+		return x.Subtotal
+	}
+	return nil
+}
+
+type isStep_Kind interface {
+	isStep_Kind()
+}
+
+type Step_Select struct {
+	Select *SelectStep `protobuf:"bytes,1,opt,name=select,proto3,oneof"`
+}
+
+type Step_Rename struct {
+	Rename *RenameStep `protobuf:"bytes,2,opt,name=rename,proto3,oneof"`
+}
+
+type Step_FillNulls struct {
+	FillNulls *FillNullsStep `protobuf:"bytes,3,opt,name=fill_nulls,json=fillNulls,proto3,oneof"`
+}
+
+type Step_Filter struct {
+	Filter *FilterStep `protobuf:"bytes,4,opt,name=filter,proto3,oneof"`
+}
+
+type Step_Derive struct {
+	Derive *DeriveStep `protobuf:"bytes,5,opt,name=derive,proto3,oneof"`
+}
+
+type Step_Aggregate struct {
+	Aggregate *AggregateStep `protobuf:"bytes,6,opt,name=aggregate,proto3,oneof"`
+}
+
+type Step_GroupingSets struct {
+	GroupingSets *GroupingSetsStep `protobuf:"bytes,7,opt,name=grouping_sets,json=groupingSets,proto3,oneof"`
+}
+
+type Step_Sort struct {
+	Sort *SortStep `protobuf:"bytes,8,opt,name=sort,proto3,oneof"`
+}
+
+type Step_Limit struct {
+	Limit *LimitStep `protobuf:"bytes,9,opt,name=limit,proto3,oneof"`
+}
+
+type Step_Subtotal struct {
+	Subtotal *SubtotalStep `protobuf:"bytes,10,opt,name=subtotal,proto3,oneof"`
+}
+
+func (*Step_Select) isStep_Kind() {}
+
+func (*Step_Rename) isStep_Kind() {}
+
+func (*Step_FillNulls) isStep_Kind() {}
+
+func (*Step_Filter) isStep_Kind() {}
+
+func (*Step_Derive) isStep_Kind() {}
+
+func (*Step_Aggregate) isStep_Kind() {}
+
+func (*Step_GroupingSets) isStep_Kind() {}
+
+func (*Step_Sort) isStep_Kind() {}
+
+func (*Step_Limit) isStep_Kind() {}
+
+func (*Step_Subtotal) isStep_Kind() {}
+
+type SelectStep struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Columns []string `protobuf:"bytes,1,rep,name=columns,proto3" json:"columns,omitempty"`
+}
+
+func (x *SelectStep) Reset() {
+	*x = SelectStep{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SelectStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SelectStep) ProtoMessage() {}
+
+func (x *SelectStep) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SelectStep.ProtoReflect.Descriptor instead.
+func (*SelectStep) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SelectStep) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+type Rename struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	From string `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To   string `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+}
+
+func (x *Rename) Reset() {
+	*x = Rename{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Rename) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rename) ProtoMessage() {}
+
+func (x *Rename) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rename.ProtoReflect.Descriptor instead.
+func (*Rename) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Rename) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *Rename) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+type RenameStep struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Columns []*Rename `protobuf:"bytes,1,rep,name=columns,proto3" json:"columns,omitempty"`
+}
+
+func (x *RenameStep) Reset() {
+	*x = RenameStep{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RenameStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameStep) ProtoMessage() {}
+
+func (x *RenameStep) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameStep.ProtoReflect.Descriptor instead.
+func (*RenameStep) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RenameStep) GetColumns() []*Rename {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+type FillNullsStep struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Defaults to every VARCHAR column.
+	Columns []string `protobuf:"bytes,1,rep,name=columns,proto3" json:"columns,omitempty"`
+	Value   string   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *FillNullsStep) Reset() {
+	*x = FillNullsStep{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FillNullsStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FillNullsStep) ProtoMessage() {}
+
+func (x *FillNullsStep) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FillNullsStep.ProtoReflect.Descriptor instead.
+func (*FillNullsStep) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *FillNullsStep) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *FillNullsStep) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type Condition struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Column string `protobuf:"bytes,1,opt,name=column,proto3" json:"column,omitempty"`
+	// One of eq, ne, lt, le, gt, ge, like, not_like, ilike, in, not_in,
+	// between, is_null and is_not_null.
+	Op     string   `protobuf:"bytes,2,opt,name=op,proto3" json:"op,omitempty"`
+	Values []string `protobuf:"bytes,3,rep,name=values,proto3" json:"values,omitempty"`
+}
+
+func (x *Condition) Reset() {
+	*x = Condition{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Condition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Condition) ProtoMessage() {}
+
+func (x *Condition) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Condition.ProtoReflect.Descriptor instead.
+func (*Condition) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Condition) GetColumn() string {
+	if x != nil {
+		return x.Column
+	}
+	return ""
+}
+
+func (x *Condition) GetOp() string {
+	if x != nil {
+		return x.Op
+	}
+	return ""
+}
+
+func (x *Condition) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type FilterStep struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Conditions []*Condition `protobuf:"bytes,1,rep,name=conditions,proto3" json:"conditions,omitempty"`
+	// Keep rows matching any condition instead of all of them.
+	Any bool `protobuf:"varint,2,opt,name=any,proto3" json:"any,omitempty"`
+}
+
+func (x *FilterStep) Reset() {
+	*x = FilterStep{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FilterStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilterStep) ProtoMessage() {}
+
+func (x *FilterStep) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FilterStep.ProtoReflect.Descriptor instead.
+func (*FilterStep) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *FilterStep) GetConditions() []*Condition {
+	if x != nil {
+		return x.Conditions
+	}
+	return nil
+}
+
+func (x *FilterStep) GetAny() bool {
+	if x != nil {
+		return x.Any
+	}
+	return false
+}
+
+type Operand struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Value:
+	//	*Operand_Column
+	//	*Operand_Literal
+	Value isOperand_Value `protobuf_oneof:"value"`
+}
+
+func (x *Operand) Reset() {
+	*x = Operand{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Operand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Operand) ProtoMessage() {}
+
+func (x *Operand) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Operand.ProtoReflect.Descriptor instead.
+func (*Operand) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{11}
+}
+
+func (m *Operand) GetValue() isOperand_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (x *Operand) GetColumn() string {
+	if x, ok := x.GetValue().(*Operand_Column); ok {
+		return x.Column
+	}
+	return ""
+}
+
+func (x *Operand) GetLiteral() string {
+	if x, ok := x.GetValue().(*Operand_Literal); ok {
+		return x.Literal
+	}
+	return ""
+}
+
+type isOperand_Value interface {
+	isOperand_Value()
+}
+
+type Operand_Column struct {
+	Column string `protobuf:"bytes,1,opt,name=column,proto3,oneof"`
+}
+
+type Operand_Literal struct {
+	Literal string `protobuf:"bytes,2,opt,name=literal,proto3,oneof"`
+}
+
+func (*Operand_Column) isOperand_Value() {}
+
+func (*Operand_Literal) isOperand_Value() {}
+
+type DeriveStep struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name     string     `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Function string     `protobuf:"bytes,2,opt,name=function,proto3" json:"function,omitempty"`
+	Args     []*Operand `protobuf:"bytes,3,rep,name=args,proto3" json:"args,omitempty"`
+}
+
+func (x *DeriveStep) Reset() {
+	*x = DeriveStep{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeriveStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeriveStep) ProtoMessage() {}
+
+func (x *DeriveStep) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeriveStep.ProtoReflect.Descriptor instead.
+func (*DeriveStep) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DeriveStep) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DeriveStep) GetFunction() string {
+	if x != nil {
+		return x.Function
+	}
+	return ""
+}
+
+func (x *DeriveStep) GetArgs() []*Operand {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+type Measure struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Column string `protobuf:"bytes,1,opt,name=column,proto3" json:"column,omitempty"`
+	// One of count, count_distinct, min, max, any_value, sum, avg and median.
+	Function string `protobuf:"bytes,2,opt,name=function,proto3" json:"function,omitempty"`
+	Alias    string `protobuf:"bytes,3,opt,name=alias,proto3" json:"alias,omitempty"`
+}
+
+func (x *Measure) Reset() {
+	*x = Measure{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Measure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Measure) ProtoMessage() {}
+
+func (x *Measure) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Measure.ProtoReflect.Descriptor instead.
+func (*Measure) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Measure) GetColumn() string {
+	if x != nil {
+		return x.Column
+	}
+	return ""
+}
+
+func (x *Measure) GetFunction() string {
+	if x != nil {
+		return x.Function
+	}
+	return ""
+}
+
+func (x *Measure) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+type AggregateStep struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GroupBy  []string   `protobuf:"bytes,1,rep,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`
+	Measures []*Measure `protobuf:"bytes,2,rep,name=measures,proto3" json:"measures,omitempty"`
+}
+
+func (x *AggregateStep) Reset() {
+	*x = AggregateStep{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AggregateStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AggregateStep) ProtoMessage() {}
+
+func (x *AggregateStep) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AggregateStep.ProtoReflect.Descriptor instead.
+func (*AggregateStep) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *AggregateStep) GetGroupBy() []string {
+	if x != nil {
+		return x.GroupBy
+	}
+	return nil
+}
+
+func (x *AggregateStep) GetMeasures() []*Measure {
+	if x != nil {
+		return x.Measures
+	}
+	return nil
+}
+
+type GroupingSet struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Columns []string `protobuf:"bytes,1,rep,name=columns,proto3" json:"columns,omitempty"`
+}
+
+func (x *GroupingSet) Reset() {
+	*x = GroupingSet{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GroupingSet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupingSet) ProtoMessage() {}
+
+func (x *GroupingSet) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupingSet.ProtoReflect.Descriptor instead.
+func (*GroupingSet) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GroupingSet) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+type GroupingSetsStep struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sets     []*GroupingSet `protobuf:"bytes,1,rep,name=sets,proto3" json:"sets,omitempty"`
+	Measures []*Measure     `protobuf:"bytes,2,rep,name=measures,proto3" json:"measures,omitempty"`
+}
+
+func (x *GroupingSetsStep) Reset() {
+	*x = GroupingSetsStep{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GroupingSetsStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupingSetsStep) ProtoMessage() {}
+
+func (x *GroupingSetsStep) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupingSetsStep.ProtoReflect.Descriptor instead.
+func (*GroupingSetsStep) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GroupingSetsStep) GetSets() []*GroupingSet {
+	if x != nil {
+		return x.Sets
+	}
+	return nil
+}
+
+func (x *GroupingSetsStep) GetMeasures() []*Measure {
+	if x != nil {
+		return x.Measures
+	}
+	return nil
+}
+
+type SortKey struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Column     string `protobuf:"bytes,1,opt,name=column,proto3" json:"column,omitempty"`
+	Descending bool   `protobuf:"varint,2,opt,name=descending,proto3" json:"descending,omitempty"`
+	NullsFirst bool   `protobuf:"varint,3,opt,name=nulls_first,json=nullsFirst,proto3" json:"nulls_first,omitempty"`
+}
+
+func (x *SortKey) Reset() {
+	*x = SortKey{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SortKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SortKey) ProtoMessage() {}
+
+func (x *SortKey) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SortKey.ProtoReflect.Descriptor instead.
+func (*SortKey) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SortKey) GetColumn() string {
+	if x != nil {
+		return x.Column
+	}
+	return ""
+}
+
+func (x *SortKey) GetDescending() bool {
+	if x != nil {
+		return x.Descending
+	}
+	return false
+}
+
+func (x *SortKey) GetNullsFirst() bool {
+	if x != nil {
+		return x.NullsFirst
+	}
+	return false
+}
+
+type SortStep struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Keys []*SortKey `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+}
+
+func (x *SortStep) Reset() {
+	*x = SortStep{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SortStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SortStep) ProtoMessage() {}
+
+func (x *SortStep) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SortStep.ProtoReflect.Descriptor instead.
+func (*SortStep) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SortStep) GetKeys() []*SortKey {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+// Keeps count rows after skipping offset rows, every remaining row when count
+// is 0.
+type LimitStep struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Count  int64 `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	Offset int64 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+}
+
+func (x *LimitStep) Reset() {
+	*x = LimitStep{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LimitStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LimitStep) ProtoMessage() {}
+
+func (x *LimitStep) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LimitStep.ProtoReflect.Descriptor instead.
+func (*LimitStep) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *LimitStep) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *LimitStep) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+// Subtotals of the measures for every level of the dimension hierarchy. Adds
+// the grouping_id and level columns, and node_key and parent_key when
+// parent_key is set. Rows are ordered depth first for tree rendering.
+type SubtotalStep struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Dimensions []string   `protobuf:"bytes,1,rep,name=dimensions,proto3" json:"dimensions,omitempty"`
+	Measures   []*Measure `protobuf:"bytes,2,rep,name=measures,proto3" json:"measures,omitempty"`
+	// Levels to compute, 0 being the grand total, defaults to all of them.
+	Levels    []int32 `protobuf:"varint,3,rep,packed,name=levels,proto3" json:"levels,omitempty"`
+	ParentKey bool    `protobuf:"varint,4,opt,name=parent_key,json=parentKey,proto3" json:"parent_key,omitempty"`
+}
+
+func (x *SubtotalStep) Reset() {
+	*x = SubtotalStep{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubtotalStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubtotalStep) ProtoMessage() {}
+
+func (x *SubtotalStep) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubtotalStep.ProtoReflect.Descriptor instead.
+func (*SubtotalStep) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SubtotalStep) GetDimensions() []string {
+	if x != nil {
+		return x.Dimensions
+	}
+	return nil
+}
+
+func (x *SubtotalStep) GetMeasures() []*Measure {
+	if x != nil {
+		return x.Measures
+	}
+	return nil
+}
+
+func (x *SubtotalStep) GetLevels() []int32 {
+	if x != nil {
+		return x.Levels
+	}
+	return nil
+}
+
+func (x *SubtotalStep) GetParentKey() bool {
+	if x != nil {
+		return x.ParentKey
+	}
+	return false
+}
+
+type QueryIn struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Local path, http(s):// URL or s3://bucket/key URI of the CSV source.
+	Path        string       `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Query       string       `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	JsonOptions *JSONOptions `protobuf:"bytes,3,opt,name=json_options,json=jsonOptions,proto3" json:"json_options,omitempty"`
+	// Used instead of query when set.
+	Pipeline *Pipeline `protobuf:"bytes,4,opt,name=pipeline,proto3" json:"pipeline,omitempty"`
+	// Shorthand for a pipeline made of a single subtotal step.
+	Subtotal *SubtotalStep `protobuf:"bytes,5,opt,name=subtotal,proto3" json:"subtotal,omitempty"`
+	// Hex encoded SHA-256 of a remote source, checked once downloaded.
+	SourceSha256 string `protobuf:"bytes,6,opt,name=source_sha256,json=sourceSha256,proto3" json:"source_sha256,omitempty"`
+	// s3://bucket/key the Parquet export is uploaded to instead of being
+	// streamed back. Only used by the Parquet RPCs.
+	Destination string `protobuf:"bytes,7,opt,name=destination,proto3" json:"destination,omitempty"`
+	// Interval between the progress messages interleaved with the chunks, at
+	// least 100. No progress is sent when 0.
+	ProgressIntervalMs int32 `protobuf:"varint,8,opt,name=progress_interval_ms,json=progressIntervalMs,proto3" json:"progress_interval_ms,omitempty"`
+}
+
+func (x *QueryIn) Reset() {
+	*x = QueryIn{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueryIn) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryIn) ProtoMessage() {}
+
+func (x *QueryIn) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryIn.ProtoReflect.Descriptor instead.
+func (*QueryIn) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *QueryIn) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *QueryIn) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *QueryIn) GetJsonOptions() *JSONOptions {
+	if x != nil {
+		return x.JsonOptions
+	}
+	return nil
+}
+
+func (x *QueryIn) GetPipeline() *Pipeline {
+	if x != nil {
+		return x.Pipeline
+	}
+	return nil
+}
+
+func (x *QueryIn) GetSubtotal() *SubtotalStep {
+	if x != nil {
+		return x.Subtotal
+	}
+	return nil
+}
+
+func (x *QueryIn) GetSourceSha256() string {
+	if x != nil {
+		return x.SourceSha256
+	}
+	return ""
+}
+
+func (x *QueryIn) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *QueryIn) GetProgressIntervalMs() int32 {
+	if x != nil {
+		return x.ProgressIntervalMs
+	}
+	return 0
+}
+
+type CompiledQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sql string `protobuf:"bytes,1,opt,name=sql,proto3" json:"sql,omitempty"`
+}
+
+func (x *CompiledQuery) Reset() {
+	*x = CompiledQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CompiledQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompiledQuery) ProtoMessage() {}
+
+func (x *CompiledQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompiledQuery.ProtoReflect.Descriptor instead.
+func (*CompiledQuery) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CompiledQuery) GetSql() string {
+	if x != nil {
+		return x.Sql
+	}
+	return ""
+}
+
+type ExplainIn struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Transformation to explain, its source is loaded like for the
+	// transformation RPCs.
+	Query *QueryIn `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Runs the query to report the rows produced and the time spent by every
+	// operator.
+	Analyze bool `protobuf:"varint,2,opt,name=analyze,proto3" json:"analyze,omitempty"`
+}
+
+func (x *ExplainIn) Reset() {
+	*x = ExplainIn{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExplainIn) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainIn) ProtoMessage() {}
+
+func (x *ExplainIn) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainIn.ProtoReflect.Descriptor instead.
+func (*ExplainIn) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ExplainIn) GetQuery() *QueryIn {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+func (x *ExplainIn) GetAnalyze() bool {
+	if x != nil {
+		return x.Analyze
+	}
+	return false
+}
+
+type ExplainOut struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Plan as rendered by DuckDB: drawn as a tree, or the JSON profile of
+	// the query with analyze so that the query runs once.
+	Plan string `protobuf:"bytes,1,opt,name=plan,proto3" json:"plan,omitempty"`
+	// Plan as JSON: the root operator with its name, details, estimated
+	// cardinality and children, along with the cardinality and seconds of
+	// every operator and the total seconds with analyze. Empty when the plan
+	// can't be parsed.
+	PlanJson string `protobuf:"bytes,2,opt,name=plan_json,json=planJson,proto3" json:"plan_json,omitempty"`
+}
+
+func (x *ExplainOut) Reset() {
+	*x = ExplainOut{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExplainOut) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainOut) ProtoMessage() {}
+
+func (x *ExplainOut) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainOut.ProtoReflect.Descriptor instead.
+func (*ExplainOut) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ExplainOut) GetPlan() string {
+	if x != nil {
+		return x.Plan
+	}
+	return ""
+}
+
+func (x *ExplainOut) GetPlanJson() string {
+	if x != nil {
+		return x.PlanJson
+	}
+	return ""
+}
+
+type CacheStatsIn struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *CacheStatsIn) Reset() {
+	*x = CacheStatsIn{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CacheStatsIn) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheStatsIn) ProtoMessage() {}
+
+func (x *CacheStatsIn) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheStatsIn.ProtoReflect.Descriptor instead.
+func (*CacheStatsIn) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{25}
+}
+
+type CacheStatsOut struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// False when DOWNLOAD_CACHE_MAX_BYTES is 0, the other fields are then 0.
+	Enabled  bool  `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Files    int64 `protobuf:"varint,2,opt,name=files,proto3" json:"files,omitempty"`
+	Urls     int64 `protobuf:"varint,3,opt,name=urls,proto3" json:"urls,omitempty"`
+	Bytes    int64 `protobuf:"varint,4,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	MaxBytes int64 `protobuf:"varint,5,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	// Requests served without a request, their checksum matching a cached
+	// file.
+	Hits uint64 `protobuf:"varint,6,opt,name=hits,proto3" json:"hits,omitempty"`
+	// Requests served from the cache after a 304 answer.
+	Revalidations uint64 `protobuf:"varint,7,opt,name=revalidations,proto3" json:"revalidations,omitempty"`
+	// Requests that downloaded the source.
+	Misses    uint64 `protobuf:"varint,8,opt,name=misses,proto3" json:"misses,omitempty"`
+	Evictions uint64 `protobuf:"varint,9,opt,name=evictions,proto3" json:"evictions,omitempty"`
+}
+
+func (x *CacheStatsOut) Reset() {
+	*x = CacheStatsOut{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CacheStatsOut) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheStatsOut) ProtoMessage() {}
+
+func (x *CacheStatsOut) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheStatsOut.ProtoReflect.Descriptor instead.
+func (*CacheStatsOut) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *CacheStatsOut) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *CacheStatsOut) GetFiles() int64 {
+	if x != nil {
+		return x.Files
+	}
+	return 0
+}
+
+func (x *CacheStatsOut) GetUrls() int64 {
+	if x != nil {
+		return x.Urls
+	}
+	return 0
+}
+
+func (x *CacheStatsOut) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *CacheStatsOut) GetMaxBytes() int64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+func (x *CacheStatsOut) GetHits() uint64 {
+	if x != nil {
+		return x.Hits
+	}
+	return 0
+}
+
+func (x *CacheStatsOut) GetRevalidations() uint64 {
+	if x != nil {
+		return x.Revalidations
+	}
+	return 0
+}
+
+func (x *CacheStatsOut) GetMisses() uint64 {
+	if x != nil {
+		return x.Misses
+	}
+	return 0
+}
+
+func (x *CacheStatsOut) GetEvictions() uint64 {
+	if x != nil {
+		return x.Evictions
+	}
+	return 0
+}
+
+type ProfileIn struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Kind ProfileKind `protobuf:"varint,1,opt,name=kind,proto3,enum=data_transform_arrow.ProfileKind" json:"kind,omitempty"`
+	// Duration of a CPU profile, defaults to 30 seconds. With request_id,
+	// how long to wait for the request instead, defaults to 5 minutes.
+	Seconds int32 `protobuf:"varint,2,opt,name=seconds,proto3" json:"seconds,omitempty"`
+	// x-request-id of a request to profile. It must start after the call.
+	RequestId string `protobuf:"bytes,3,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+}
+
+func (x *ProfileIn) Reset() {
+	*x = ProfileIn{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProfileIn) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProfileIn) ProtoMessage() {}
+
+func (x *ProfileIn) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProfileIn.ProtoReflect.Descriptor instead.
+func (*ProfileIn) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ProfileIn) GetKind() ProfileKind {
+	if x != nil {
+		return x.Kind
+	}
+	return ProfileKind_PROFILE_CPU
+}
+
+func (x *ProfileIn) GetSeconds() int32 {
+	if x != nil {
+		return x.Seconds
+	}
+	return 0
+}
+
+func (x *ProfileIn) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type ProfileOut struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Name of the file the profile is stored in on the server.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Profile in the pprof format.
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *ProfileOut) Reset() {
+	*x = ProfileOut{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProfileOut) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProfileOut) ProtoMessage() {}
+
+func (x *ProfileOut) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProfileOut.ProtoReflect.Descriptor instead.
+func (*ProfileOut) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ProfileOut) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ProfileOut) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type QueryHistoryIn struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Filters, the empty ones match every entry.
+	RequestId string `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// caller and source match the entries containing them.
+	Caller    string `protobuf:"bytes,2,opt,name=caller,proto3" json:"caller,omitempty"`
+	Source    string `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	QueryHash string `protobuf:"bytes,4,opt,name=query_hash,json=queryHash,proto3" json:"query_hash,omitempty"`
+	// gRPC status code of the request, like OK or InvalidArgument.
+	Status        string  `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	MinDurationMs float64 `protobuf:"fixed64,6,opt,name=min_duration_ms,json=minDurationMs,proto3" json:"min_duration_ms,omitempty"`
+	SinceUnixMs   int64   `protobuf:"varint,7,opt,name=since_unix_ms,json=sinceUnixMs,proto3" json:"since_unix_ms,omitempty"`
+	UntilUnixMs   int64   `protobuf:"varint,8,opt,name=until_unix_ms,json=untilUnixMs,proto3" json:"until_unix_ms,omitempty"`
+	// Number of entries returned, the newest first. Defaults to 100, at
+	// most 1000.
+	Limit int32 `protobuf:"varint,9,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (x *QueryHistoryIn) Reset() {
+	*x = QueryHistoryIn{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueryHistoryIn) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryHistoryIn) ProtoMessage() {}
+
+func (x *QueryHistoryIn) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryHistoryIn.ProtoReflect.Descriptor instead.
+func (*QueryHistoryIn) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *QueryHistoryIn) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *QueryHistoryIn) GetCaller() string {
+	if x != nil {
+		return x.Caller
+	}
+	return ""
+}
+
+func (x *QueryHistoryIn) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *QueryHistoryIn) GetQueryHash() string {
+	if x != nil {
+		return x.QueryHash
+	}
+	return ""
+}
+
+func (x *QueryHistoryIn) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *QueryHistoryIn) GetMinDurationMs() float64 {
+	if x != nil {
+		return x.MinDurationMs
+	}
+	return 0
+}
+
+func (x *QueryHistoryIn) GetSinceUnixMs() int64 {
+	if x != nil {
+		return x.SinceUnixMs
+	}
+	return 0
+}
+
+func (x *QueryHistoryIn) GetUntilUnixMs() int64 {
+	if x != nil {
+		return x.UntilUnixMs
+	}
+	return 0
+}
+
+func (x *QueryHistoryIn) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type QueryHistoryEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StartedAtUnixMs int64  `protobuf:"varint,1,opt,name=started_at_unix_ms,json=startedAtUnixMs,proto3" json:"started_at_unix_ms,omitempty"`
+	RequestId       string `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Method          string `protobuf:"bytes,3,opt,name=method,proto3" json:"method,omitempty"`
+	Caller          string `protobuf:"bytes,4,opt,name=caller,proto3" json:"caller,omitempty"`
+	Source          string `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"`
+	// Hash of the query with its values left out, the same for queries
+	// differing only by their values.
+	QueryHash string `protobuf:"bytes,6,opt,name=query_hash,json=queryHash,proto3" json:"query_hash,omitempty"`
+	Sql       string `protobuf:"bytes,7,opt,name=sql,proto3" json:"sql,omitempty"`
+	RowsOut   int64  `protobuf:"varint,8,opt,name=rows_out,json=rowsOut,proto3" json:"rows_out,omitempty"`
+	BytesOut  int64  `protobuf:"varint,9,opt,name=bytes_out,json=bytesOut,proto3" json:"bytes_out,omitempty"`
+	// Time spent in every phase of the request.
+	PhaseMs    map[string]float64 `protobuf:"bytes,10,rep,name=phase_ms,json=phaseMs,proto3" json:"phase_ms,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+	DurationMs float64            `protobuf:"fixed64,11,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	// Highest memory use of DuckDB seen while the request ran, other
+	// requests included.
+	PeakMemoryBytes int64  `protobuf:"varint,12,opt,name=peak_memory_bytes,json=peakMemoryBytes,proto3" json:"peak_memory_bytes,omitempty"`
+	Status          string `protobuf:"bytes,13,opt,name=status,proto3" json:"status,omitempty"`
+	Error           string `protobuf:"bytes,14,opt,name=error,proto3" json:"error,omitempty"`
+	// EXPLAIN ANALYZE output of the slow queries.
+	Plan string `protobuf:"bytes,15,opt,name=plan,proto3" json:"plan,omitempty"`
+}
+
+func (x *QueryHistoryEntry) Reset() {
+	*x = QueryHistoryEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueryHistoryEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryHistoryEntry) ProtoMessage() {}
+
+func (x *QueryHistoryEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryHistoryEntry.ProtoReflect.Descriptor instead.
+func (*QueryHistoryEntry) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *QueryHistoryEntry) GetStartedAtUnixMs() int64 {
+	if x != nil {
+		return x.StartedAtUnixMs
+	}
+	return 0
+}
+
+func (x *QueryHistoryEntry) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *QueryHistoryEntry) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *QueryHistoryEntry) GetCaller() string {
+	if x != nil {
+		return x.Caller
+	}
+	return ""
+}
+
+func (x *QueryHistoryEntry) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *QueryHistoryEntry) GetQueryHash() string {
+	if x != nil {
+		return x.QueryHash
+	}
+	return ""
+}
+
+func (x *QueryHistoryEntry) GetSql() string {
+	if x != nil {
+		return x.Sql
+	}
+	return ""
+}
+
+func (x *QueryHistoryEntry) GetRowsOut() int64 {
+	if x != nil {
+		return x.RowsOut
+	}
+	return 0
+}
+
+func (x *QueryHistoryEntry) GetBytesOut() int64 {
+	if x != nil {
+		return x.BytesOut
+	}
+	return 0
+}
+
+func (x *QueryHistoryEntry) GetPhaseMs() map[string]float64 {
+	if x != nil {
+		return x.PhaseMs
+	}
+	return nil
+}
+
+func (x *QueryHistoryEntry) GetDurationMs() float64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *QueryHistoryEntry) GetPeakMemoryBytes() int64 {
+	if x != nil {
+		return x.PeakMemoryBytes
+	}
+	return 0
+}
+
+func (x *QueryHistoryEntry) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *QueryHistoryEntry) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *QueryHistoryEntry) GetPlan() string {
+	if x != nil {
+		return x.Plan
+	}
+	return ""
+}
+
+type QueryHistoryOut struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Entries []*QueryHistoryEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+}
+
+func (x *QueryHistoryOut) Reset() {
+	*x = QueryHistoryOut{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueryHistoryOut) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryHistoryOut) ProtoMessage() {}
+
+func (x *QueryHistoryOut) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryHistoryOut.ProtoReflect.Descriptor instead.
+func (*QueryHistoryOut) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *QueryHistoryOut) GetEntries() []*QueryHistoryEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type ReloadConfigIn struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ReloadConfigIn) Reset() {
+	*x = ReloadConfigIn{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReloadConfigIn) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadConfigIn) ProtoMessage() {}
+
+func (x *ReloadConfigIn) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadConfigIn.ProtoReflect.Descriptor instead.
+func (*ReloadConfigIn) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{32}
+}
+
+type ConfigChange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Key of the setting in the configuration file, like stream.chunk_size.
+	Setting string `protobuf:"bytes,1,opt,name=setting,proto3" json:"setting,omitempty"`
+	// Environment variable of the setting, like CHUNK_SIZE.
+	Env string `protobuf:"bytes,2,opt,name=env,proto3" json:"env,omitempty"`
+	// Values before and after the reload, the secrets redacted.
+	OldValue string `protobuf:"bytes,3,opt,name=old_value,json=oldValue,proto3" json:"old_value,omitempty"`
+	NewValue string `protobuf:"bytes,4,opt,name=new_value,json=newValue,proto3" json:"new_value,omitempty"`
+}
+
+func (x *ConfigChange) Reset() {
+	*x = ConfigChange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConfigChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigChange) ProtoMessage() {}
+
+func (x *ConfigChange) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigChange.ProtoReflect.Descriptor instead.
+func (*ConfigChange) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ConfigChange) GetSetting() string {
+	if x != nil {
+		return x.Setting
+	}
+	return ""
+}
+
+func (x *ConfigChange) GetEnv() string {
+	if x != nil {
+		return x.Env
+	}
+	return ""
+}
+
+func (x *ConfigChange) GetOldValue() string {
+	if x != nil {
+		return x.OldValue
+	}
+	return ""
+}
+
+func (x *ConfigChange) GetNewValue() string {
+	if x != nil {
+		return x.NewValue
+	}
+	return ""
+}
+
+type ReloadConfigOut struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Settings changed by the reload, empty when nothing changed.
+	Changes []*ConfigChange `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+}
+
+func (x *ReloadConfigOut) Reset() {
+	*x = ReloadConfigOut{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReloadConfigOut) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadConfigOut) ProtoMessage() {}
+
+func (x *ReloadConfigOut) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadConfigOut.ProtoReflect.Descriptor instead.
+func (*ReloadConfigOut) Descriptor() ([]byte, []int) {
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ReloadConfigOut) GetChanges() []*ConfigChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+var File_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto protoreflect.FileDescriptor
+
+var file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDesc = []byte{
+	0x0a, 0x45, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x73, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2f, 0x64,
+	0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x2f, 0x64, 0x61,
+	0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f,
+	0x77, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x14, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x22, 0x99, 0x01,
+	0x0a, 0x08, 0x51, 0x75, 0x65, 0x72, 0x79, 0x4f, 0x75, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x73, 0x65,
+	0x71, 0x75, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0e, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x79, 0x4e, 0x75, 0x6d,
+	0x62, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74,
+	0x61, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x3a, 0x0a,
+	0x08, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1e, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d,
+	0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x52,
+	0x08, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x22, 0x9e, 0x02, 0x0a, 0x08, 0x50, 0x72,
+	0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x68, 0x61, 0x73, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x68, 0x61, 0x73, 0x65, 0x12, 0x25, 0x0a, 0x0e,
+	0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x42, 0x79,
+	0x74, 0x65, 0x73, 0x12, 0x30, 0x0a, 0x14, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x5f,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x12, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x54, 0x6f, 0x74, 0x61, 0x6c,
+	0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x70,
+	0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x6f,
+	0x77, 0x73, 0x5f, 0x65, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0b, 0x72, 0x6f, 0x77, 0x73, 0x45, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x12, 0x25, 0x0a,
+	0x0e, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x5f, 0x65, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x45, 0x6d, 0x69,
+	0x74, 0x74, 0x65, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x6c, 0x61, 0x70, 0x73, 0x65, 0x64, 0x5f,
+	0x6d, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x65, 0x6c, 0x61, 0x70, 0x73, 0x65,
+	0x64, 0x4d, 0x73, 0x12, 0x15, 0x0a, 0x06, 0x65, 0x74, 0x61, 0x5f, 0x6d, 0x73, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x05, 0x65, 0x74, 0x61, 0x4d, 0x73, 0x22, 0xab, 0x01, 0x0a, 0x0b, 0x4a,
+	0x53, 0x4f, 0x4e, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x38, 0x0a, 0x06, 0x66, 0x6f,
+	0x72, 0x6d, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x20, 0x2e, 0x64, 0x61, 0x74,
+	0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f,
+	0x77, 0x2e, 0x4a, 0x53, 0x4f, 0x4e, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x52, 0x06, 0x66, 0x6f,
+	0x72, 0x6d, 0x61, 0x74, 0x12, 0x41, 0x0a, 0x08, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x25, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x44, 0x65,
+	0x63, 0x69, 0x6d, 0x61, 0x6c, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x64,
+	0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x68, 0x75, 0x6e, 0x6b,
+	0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x63, 0x68,
+	0x75, 0x6e, 0x6b, 0x42, 0x79, 0x74, 0x65, 0x73, 0x22, 0x3c, 0x0a, 0x08, 0x50, 0x69, 0x70, 0x65,
+	0x6c, 0x69, 0x6e, 0x65, 0x12, 0x30, 0x0a, 0x05, 0x73, 0x74, 0x65, 0x70, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73,
+	0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x53, 0x74, 0x65, 0x70, 0x52,
+	0x05, 0x73, 0x74, 0x65, 0x70, 0x73, 0x22, 0x89, 0x05, 0x0a, 0x04, 0x53, 0x74, 0x65, 0x70, 0x12,
+	0x3a, 0x0a, 0x06, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x20, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d,
+	0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x53, 0x74, 0x65,
+	0x70, 0x48, 0x00, 0x52, 0x06, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x12, 0x3a, 0x0a, 0x06, 0x72,
+	0x65, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x64, 0x61,
+	0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72,
+	0x6f, 0x77, 0x2e, 0x52, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x53, 0x74, 0x65, 0x70, 0x48, 0x00, 0x52,
+	0x06, 0x72, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x44, 0x0a, 0x0a, 0x66, 0x69, 0x6c, 0x6c, 0x5f,
+	0x6e, 0x75, 0x6c, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x64, 0x61,
+	0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72,
+	0x6f, 0x77, 0x2e, 0x46, 0x69, 0x6c, 0x6c, 0x4e, 0x75, 0x6c, 0x6c, 0x73, 0x53, 0x74, 0x65, 0x70,
+	0x48, 0x00, 0x52, 0x09, 0x66, 0x69, 0x6c, 0x6c, 0x4e, 0x75, 0x6c, 0x6c, 0x73, 0x12, 0x3a, 0x0a,
+	0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e,
+	0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61,
+	0x72, 0x72, 0x6f, 0x77, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x53, 0x74, 0x65, 0x70, 0x48,
+	0x00, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x3a, 0x0a, 0x06, 0x64, 0x65, 0x72,
+	0x69, 0x76, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x64, 0x61, 0x74, 0x61,
+	0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77,
+	0x2e, 0x44, 0x65, 0x72, 0x69, 0x76, 0x65, 0x53, 0x74, 0x65, 0x70, 0x48, 0x00, 0x52, 0x06, 0x64,
+	0x65, 0x72, 0x69, 0x76, 0x65, 0x12, 0x43, 0x0a, 0x09, 0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61,
+	0x74, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e,
+	0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x53, 0x74, 0x65, 0x70, 0x48, 0x00, 0x52,
+	0x09, 0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x12, 0x4d, 0x0a, 0x0d, 0x67, 0x72,
+	0x6f, 0x75, 0x70, 0x69, 0x6e, 0x67, 0x5f, 0x73, 0x65, 0x74, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x26, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f,
+	0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x69, 0x6e,
+	0x67, 0x53, 0x65, 0x74, 0x73, 0x53, 0x74, 0x65, 0x70, 0x48, 0x00, 0x52, 0x0c, 0x67, 0x72, 0x6f,
+	0x75, 0x70, 0x69, 0x6e, 0x67, 0x53, 0x65, 0x74, 0x73, 0x12, 0x34, 0x0a, 0x04, 0x73, 0x6f, 0x72,
+	0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x53,
+	0x6f, 0x72, 0x74, 0x53, 0x74, 0x65, 0x70, 0x48, 0x00, 0x52, 0x04, 0x73, 0x6f, 0x72, 0x74, 0x12,
+	0x37, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f,
+	0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f,
+	0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x53, 0x74, 0x65, 0x70, 0x48,
+	0x00, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x40, 0x0a, 0x08, 0x73, 0x75, 0x62, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x64, 0x61, 0x74,
+	0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f,
+	0x77, 0x2e, 0x53, 0x75, 0x62, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x53, 0x74, 0x65, 0x70, 0x48, 0x00,
+	0x52, 0x08, 0x73, 0x75, 0x62, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x42, 0x06, 0x0a, 0x04, 0x6b, 0x69,
+	0x6e, 0x64, 0x22, 0x26, 0x0a, 0x0a, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x53, 0x74, 0x65, 0x70,
+	0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x22, 0x2c, 0x0a, 0x06, 0x52, 0x65,
+	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x74, 0x6f, 0x22, 0x44, 0x0a, 0x0a, 0x52, 0x65, 0x6e, 0x61,
+	0x6d, 0x65, 0x53, 0x74, 0x65, 0x70, 0x12, 0x36, 0x0a, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x52,
+	0x65, 0x6e, 0x61, 0x6d, 0x65, 0x52, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x22, 0x3f,
+	0x0a, 0x0d, 0x46, 0x69, 0x6c, 0x6c, 0x4e, 0x75, 0x6c, 0x6c, 0x73, 0x53, 0x74, 0x65, 0x70, 0x12,
+	0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22,
+	0x4b, 0x0a, 0x09, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06,
+	0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x6f,
+	0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x6f, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x6f, 0x70, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0x5f, 0x0a, 0x0a,
+	0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x53, 0x74, 0x65, 0x70, 0x12, 0x3f, 0x0a, 0x0a, 0x63, 0x6f,
+	0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f,
+	0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f,
+	0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x0a, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x61,
+	0x6e, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x61, 0x6e, 0x79, 0x22, 0x48, 0x0a,
+	0x07, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x6e, 0x64, 0x12, 0x18, 0x0a, 0x06, 0x63, 0x6f, 0x6c, 0x75,
+	0x6d, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x06, 0x63, 0x6f, 0x6c, 0x75,
+	0x6d, 0x6e, 0x12, 0x1a, 0x0a, 0x07, 0x6c, 0x69, 0x74, 0x65, 0x72, 0x61, 0x6c, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x07, 0x6c, 0x69, 0x74, 0x65, 0x72, 0x61, 0x6c, 0x42, 0x07,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x6f, 0x0a, 0x0a, 0x44, 0x65, 0x72, 0x69, 0x76,
+	0x65, 0x53, 0x74, 0x65, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x75, 0x6e,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x75, 0x6e,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x31, 0x0a, 0x04, 0x61, 0x72, 0x67, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73,
+	0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x4f, 0x70, 0x65, 0x72, 0x61,
+	0x6e, 0x64, 0x52, 0x04, 0x61, 0x72, 0x67, 0x73, 0x22, 0x53, 0x0a, 0x07, 0x4d, 0x65, 0x61, 0x73,
+	0x75, 0x72, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x66,
+	0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66,
+	0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x22, 0x65, 0x0a,
+	0x0d, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x53, 0x74, 0x65, 0x70, 0x12, 0x19,
+	0x0a, 0x08, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f, 0x62, 0x79, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x07, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x42, 0x79, 0x12, 0x39, 0x0a, 0x08, 0x6d, 0x65, 0x61,
+	0x73, 0x75, 0x72, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x64, 0x61,
+	0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72,
+	0x6f, 0x77, 0x2e, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x52, 0x08, 0x6d, 0x65, 0x61, 0x73,
+	0x75, 0x72, 0x65, 0x73, 0x22, 0x27, 0x0a, 0x0b, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x69, 0x6e, 0x67,
+	0x53, 0x65, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x22, 0x84, 0x01,
+	0x0a, 0x10, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x69, 0x6e, 0x67, 0x53, 0x65, 0x74, 0x73, 0x53, 0x74,
+	0x65, 0x70, 0x12, 0x35, 0x0a, 0x04, 0x73, 0x65, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x21, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72,
+	0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x69, 0x6e, 0x67,
+	0x53, 0x65, 0x74, 0x52, 0x04, 0x73, 0x65, 0x74, 0x73, 0x12, 0x39, 0x0a, 0x08, 0x6d, 0x65, 0x61,
+	0x73, 0x75, 0x72, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x64, 0x61,
+	0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72,
+	0x6f, 0x77, 0x2e, 0x4d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x52, 0x08, 0x6d, 0x65, 0x61, 0x73,
+	0x75, 0x72, 0x65, 0x73, 0x22, 0x62, 0x0a, 0x07, 0x53, 0x6f, 0x72, 0x74, 0x4b, 0x65, 0x79, 0x12,
+	0x16, 0x0a, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x65, 0x73, 0x63, 0x65,
+	0x6e, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x64, 0x65, 0x73,
+	0x63, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x75, 0x6c, 0x6c, 0x73,
+	0x5f, 0x66, 0x69, 0x72, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x6e, 0x75,
+	0x6c, 0x6c, 0x73, 0x46, 0x69, 0x72, 0x73, 0x74, 0x22, 0x3d, 0x0a, 0x08, 0x53, 0x6f, 0x72, 0x74,
+	0x53, 0x74, 0x65, 0x70, 0x12, 0x31, 0x0a, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66,
+	0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x53, 0x6f, 0x72, 0x74, 0x4b, 0x65,
+	0x79, 0x52, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x22, 0x39, 0x0a, 0x09, 0x4c, 0x69, 0x6d, 0x69, 0x74,
+	0x53, 0x74, 0x65, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66,
+	0x66, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73,
+	0x65, 0x74, 0x22, 0xa0, 0x01, 0x0a, 0x0c, 0x53, 0x75, 0x62, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x53,
+	0x74, 0x65, 0x70, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x39, 0x0a, 0x08, 0x6d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x4d, 0x65, 0x61,
+	0x73, 0x75, 0x72, 0x65, 0x52, 0x08, 0x6d, 0x65, 0x61, 0x73, 0x75, 0x72, 0x65, 0x73, 0x12, 0x16,
+	0x0a, 0x06, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x05, 0x52, 0x06,
+	0x6c, 0x65, 0x76, 0x65, 0x6c, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74,
+	0x5f, 0x6b, 0x65, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x70, 0x61, 0x72, 0x65,
+	0x6e, 0x74, 0x4b, 0x65, 0x79, 0x22, 0xee, 0x02, 0x0a, 0x07, 0x51, 0x75, 0x65, 0x72, 0x79, 0x49,
+	0x6e, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x44, 0x0a, 0x0c, 0x6a,
+	0x73, 0x6f, 0x6e, 0x5f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x21, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f,
+	0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x4a, 0x53, 0x4f, 0x4e, 0x4f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x52, 0x0b, 0x6a, 0x73, 0x6f, 0x6e, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x12, 0x3a, 0x0a, 0x08, 0x70, 0x69, 0x70, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73,
+	0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x50, 0x69, 0x70, 0x65, 0x6c,
+	0x69, 0x6e, 0x65, 0x52, 0x08, 0x70, 0x69, 0x70, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x3e, 0x0a,
+	0x08, 0x73, 0x75, 0x62, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x22, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d,
+	0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x53, 0x75, 0x62, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x53,
+	0x74, 0x65, 0x70, 0x52, 0x08, 0x73, 0x75, 0x62, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x23, 0x0a,
+	0x0d, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x73, 0x68, 0x61, 0x32, 0x35, 0x36, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x53, 0x68, 0x61, 0x32,
+	0x35, 0x36, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x30, 0x0a, 0x14, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73,
+	0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x5f, 0x6d, 0x73, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x12, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x49, 0x6e, 0x74, 0x65,
+	0x72, 0x76, 0x61, 0x6c, 0x4d, 0x73, 0x22, 0x21, 0x0a, 0x0d, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c,
+	0x65, 0x64, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x71, 0x6c, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x71, 0x6c, 0x22, 0x5a, 0x0a, 0x09, 0x45, 0x78, 0x70,
+	0x6c, 0x61, 0x69, 0x6e, 0x49, 0x6e, 0x12, 0x33, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x49, 0x6e, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x61,
+	0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x61, 0x6e,
+	0x61, 0x6c, 0x79, 0x7a, 0x65, 0x22, 0x3d, 0x0a, 0x0a, 0x45, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e,
+	0x4f, 0x75, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6c, 0x61, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x70, 0x6c, 0x61, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x6c, 0x61, 0x6e, 0x5f,
+	0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x6e,
+	0x4a, 0x73, 0x6f, 0x6e, 0x22, 0x0e, 0x0a, 0x0c, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x74, 0x61,
+	0x74, 0x73, 0x49, 0x6e, 0x22, 0xf6, 0x01, 0x0a, 0x0d, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x74,
+	0x61, 0x74, 0x73, 0x4f, 0x75, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64,
+	0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x79,
+	0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x62, 0x79, 0x74, 0x65, 0x73,
+	0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x12, 0x0a,
+	0x04, 0x68, 0x69, 0x74, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x68, 0x69, 0x74,
+	0x73, 0x12, 0x24, 0x0a, 0x0d, 0x72, 0x65, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x72, 0x65, 0x76, 0x61, 0x6c, 0x69,
+	0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x69, 0x73, 0x73, 0x65,
+	0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x73, 0x12,
+	0x1c, 0x0a, 0x09, 0x65, 0x76, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x09, 0x65, 0x76, 0x69, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x7b, 0x0a,
+	0x09, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x12, 0x35, 0x0a, 0x04, 0x6b, 0x69,
+	0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x21, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e,
+	0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x4b, 0x69, 0x6e, 0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e,
+	0x64, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x07, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x22, 0x34, 0x0a, 0x0a, 0x50, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x4f, 0x75, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61,
+	0x22, 0x9c, 0x02, 0x0a, 0x0e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72,
+	0x79, 0x49, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x63, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x68, 0x61, 0x73, 0x68,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x71, 0x75, 0x65, 0x72, 0x79, 0x48, 0x61, 0x73,
+	0x68, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6d, 0x69, 0x6e,
+	0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x0d, 0x6d, 0x69, 0x6e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d,
+	0x73, 0x12, 0x22, 0x0a, 0x0d, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x5f, 0x75, 0x6e, 0x69, 0x78, 0x5f,
+	0x6d, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x55,
+	0x6e, 0x69, 0x78, 0x4d, 0x73, 0x12, 0x22, 0x0a, 0x0d, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x5f, 0x75,
+	0x6e, 0x69, 0x78, 0x5f, 0x6d, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x75, 0x6e,
+	0x74, 0x69, 0x6c, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d,
+	0x69, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22,
+	0xac, 0x04, 0x0a, 0x11, 0x51, 0x75, 0x65, 0x72, 0x79, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x2b, 0x0a, 0x12, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64,
+	0x5f, 0x61, 0x74, 0x5f, 0x75, 0x6e, 0x69, 0x78, 0x5f, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x41, 0x74, 0x55, 0x6e, 0x69, 0x78,
+	0x4d, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49,
+	0x64, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x61, 0x6c,
+	0x6c, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x61, 0x6c, 0x6c, 0x65,
+	0x72, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x71, 0x75, 0x65,
+	0x72, 0x79, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x71,
+	0x75, 0x65, 0x72, 0x79, 0x48, 0x61, 0x73, 0x68, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x71, 0x6c, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73, 0x71, 0x6c, 0x12, 0x19, 0x0a, 0x08, 0x72, 0x6f,
+	0x77, 0x73, 0x5f, 0x6f, 0x75, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x72, 0x6f,
+	0x77, 0x73, 0x4f, 0x75, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x62, 0x79, 0x74, 0x65, 0x73, 0x5f, 0x6f,
+	0x75, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x62, 0x79, 0x74, 0x65, 0x73, 0x4f,
+	0x75, 0x74, 0x12, 0x4f, 0x0a, 0x08, 0x70, 0x68, 0x61, 0x73, 0x65, 0x5f, 0x6d, 0x73, 0x18, 0x0a,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x34, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e,
+	0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x51, 0x75, 0x65, 0x72,
+	0x79, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x2e, 0x50, 0x68,
+	0x61, 0x73, 0x65, 0x4d, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x70, 0x68, 0x61, 0x73,
+	0x65, 0x4d, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f,
+	0x6d, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x4d, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x70, 0x65, 0x61, 0x6b, 0x5f, 0x6d, 0x65, 0x6d,
+	0x6f, 0x72, 0x79, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0f, 0x70, 0x65, 0x61, 0x6b, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42, 0x79, 0x74, 0x65, 0x73,
+	0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x12,
+	0x0a, 0x04, 0x70, 0x6c, 0x61, 0x6e, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x6c,
+	0x61, 0x6e, 0x1a, 0x3a, 0x0a, 0x0c, 0x50, 0x68, 0x61, 0x73, 0x65, 0x4d, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x54,
+	0x0a, 0x0f, 0x51, 0x75, 0x65, 0x72, 0x79, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x4f, 0x75,
+	0x74, 0x12, 0x41, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x27, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66,
+	0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x48,
+	0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x74,
+	0x72, 0x69, 0x65, 0x73, 0x22, 0x10, 0x0a, 0x0e, 0x52, 0x65, 0x6c, 0x6f, 0x61, 0x64, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x49, 0x6e, 0x22, 0x74, 0x0a, 0x0c, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x74, 0x74, 0x69, 0x6e,
+	0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67,
+	0x12, 0x10, 0x0a, 0x03, 0x65, 0x6e, 0x76, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x65,
+	0x6e, 0x76, 0x12, 0x1b, 0x0a, 0x09, 0x6f, 0x6c, 0x64, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6f, 0x6c, 0x64, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12,
+	0x1b, 0x0a, 0x09, 0x6e, 0x65, 0x77, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x6e, 0x65, 0x77, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x4f, 0x0a, 0x0f,
+	0x52, 0x65, 0x6c, 0x6f, 0x61, 0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4f, 0x75, 0x74, 0x12,
+	0x3c, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x22, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72,
+	0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x2a, 0x40, 0x0a,
+	0x0a, 0x4a, 0x53, 0x4f, 0x4e, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x1b, 0x0a, 0x17, 0x4a,
+	0x53, 0x4f, 0x4e, 0x5f, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45,
+	0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x4e, 0x44, 0x4a, 0x53,
+	0x4f, 0x4e, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05, 0x41, 0x52, 0x52, 0x41, 0x59, 0x10, 0x02, 0x2a,
+	0x61, 0x0a, 0x0f, 0x44, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x69,
+	0x6e, 0x67, 0x12, 0x20, 0x0a, 0x1c, 0x44, 0x45, 0x43, 0x49, 0x4d, 0x41, 0x4c, 0x5f, 0x45, 0x4e,
+	0x43, 0x4f, 0x44, 0x49, 0x4e, 0x47, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49,
+	0x45, 0x44, 0x10, 0x00, 0x12, 0x15, 0x0a, 0x11, 0x44, 0x45, 0x43, 0x49, 0x4d, 0x41, 0x4c, 0x5f,
+	0x41, 0x53, 0x5f, 0x53, 0x54, 0x52, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x15, 0x0a, 0x11, 0x44,
+	0x45, 0x43, 0x49, 0x4d, 0x41, 0x4c, 0x5f, 0x41, 0x53, 0x5f, 0x4e, 0x55, 0x4d, 0x42, 0x45, 0x52,
+	0x10, 0x02, 0x2a, 0x47, 0x0a, 0x0b, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x4b, 0x69, 0x6e,
+	0x64, 0x12, 0x0f, 0x0a, 0x0b, 0x50, 0x52, 0x4f, 0x46, 0x49, 0x4c, 0x45, 0x5f, 0x43, 0x50, 0x55,
+	0x10, 0x00, 0x12, 0x10, 0x0a, 0x0c, 0x50, 0x52, 0x4f, 0x46, 0x49, 0x4c, 0x45, 0x5f, 0x48, 0x45,
+	0x41, 0x50, 0x10, 0x01, 0x12, 0x15, 0x0a, 0x11, 0x50, 0x52, 0x4f, 0x46, 0x49, 0x4c, 0x45, 0x5f,
+	0x47, 0x4f, 0x52, 0x4f, 0x55, 0x54, 0x49, 0x4e, 0x45, 0x10, 0x02, 0x32, 0xfd, 0x05, 0x0a, 0x0d,
+	0x44, 0x61, 0x74, 0x61, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x12, 0x5c, 0x0a,
+	0x17, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x41, 0x6e, 0x64, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x41, 0x72, 0x72, 0x6f, 0x77, 0x12, 0x1d, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e,
+	0x51, 0x75, 0x65, 0x72, 0x79, 0x49, 0x6e, 0x1a, 0x1e, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x51,
+	0x75, 0x65, 0x72, 0x79, 0x4f, 0x75, 0x74, 0x22, 0x00, 0x30, 0x01, 0x12, 0x5e, 0x0a, 0x19, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x41, 0x6e, 0x64, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x50, 0x61, 0x72, 0x71, 0x75, 0x65, 0x74, 0x12, 0x1d, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e,
+	0x51, 0x75, 0x65, 0x72, 0x79, 0x49, 0x6e, 0x1a, 0x1e, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x51,
+	0x75, 0x65, 0x72, 0x79, 0x4f, 0x75, 0x74, 0x22, 0x00, 0x30, 0x01, 0x12, 0x5b, 0x0a, 0x16, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x41, 0x6e, 0x64, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x4a, 0x53, 0x4f, 0x4e, 0x12, 0x1d, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x49, 0x6e, 0x1a, 0x1e, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e,
+	0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x51, 0x75, 0x65, 0x72,
+	0x79, 0x4f, 0x75, 0x74, 0x22, 0x00, 0x30, 0x01, 0x12, 0x61, 0x0a, 0x1c, 0x4c, 0x6f, 0x63, 0x61,
+	0x6c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x41, 0x6e, 0x64, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x41, 0x72, 0x72, 0x6f, 0x77, 0x12, 0x1d, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e,
+	0x51, 0x75, 0x65, 0x72, 0x79, 0x49, 0x6e, 0x1a, 0x1e, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x51,
+	0x75, 0x65, 0x72, 0x79, 0x4f, 0x75, 0x74, 0x22, 0x00, 0x30, 0x01, 0x12, 0x63, 0x0a, 0x1e, 0x4c,
+	0x6f, 0x63, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x41, 0x6e, 0x64,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x50, 0x61, 0x72, 0x71, 0x75, 0x65, 0x74, 0x12, 0x1d, 0x2e,
+	0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61,
+	0x72, 0x72, 0x6f, 0x77, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x49, 0x6e, 0x1a, 0x1e, 0x2e, 0x64,
+	0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72,
+	0x72, 0x6f, 0x77, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x4f, 0x75, 0x74, 0x22, 0x00, 0x30, 0x01,
+	0x12, 0x60, 0x0a, 0x1b, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f,
+	0x72, 0x6d, 0x41, 0x6e, 0x64, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4a, 0x53, 0x4f, 0x4e, 0x12,
+	0x1d, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d,
+	0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x49, 0x6e, 0x1a, 0x1e,
+	0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f,
+	0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x4f, 0x75, 0x74, 0x22, 0x00,
+	0x30, 0x01, 0x12, 0x57, 0x0a, 0x0f, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x50, 0x69, 0x70,
+	0x65, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x1d, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x49, 0x6e, 0x1a, 0x23, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e,
+	0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x43, 0x6f, 0x6d, 0x70,
+	0x69, 0x6c, 0x65, 0x64, 0x51, 0x75, 0x65, 0x72, 0x79, 0x22, 0x00, 0x12, 0x4e, 0x0a, 0x07, 0x45,
+	0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x12, 0x1f, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72,
+	0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x45, 0x78,
+	0x70, 0x6c, 0x61, 0x69, 0x6e, 0x49, 0x6e, 0x1a, 0x20, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x45,
+	0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x4f, 0x75, 0x74, 0x22, 0x00, 0x32, 0xf5, 0x02, 0x0a, 0x05,
+	0x41, 0x64, 0x6d, 0x69, 0x6e, 0x12, 0x57, 0x0a, 0x0a, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x74,
+	0x61, 0x74, 0x73, 0x12, 0x22, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73,
+	0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x43, 0x61, 0x63, 0x68, 0x65,
+	0x53, 0x74, 0x61, 0x74, 0x73, 0x49, 0x6e, 0x1a, 0x23, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x43,
+	0x61, 0x63, 0x68, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x4f, 0x75, 0x74, 0x22, 0x00, 0x12, 0x55,
+	0x0a, 0x0e, 0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65,
+	0x12, 0x1f, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72,
+	0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x49,
+	0x6e, 0x1a, 0x20, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f,
+	0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65,
+	0x4f, 0x75, 0x74, 0x22, 0x00, 0x12, 0x5d, 0x0a, 0x0c, 0x51, 0x75, 0x65, 0x72, 0x79, 0x48, 0x69,
+	0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x24, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x49, 0x6e, 0x1a, 0x25, 0x2e, 0x64, 0x61,
+	0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72,
+	0x6f, 0x77, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x4f,
+	0x75, 0x74, 0x22, 0x00, 0x12, 0x5d, 0x0a, 0x0c, 0x52, 0x65, 0x6c, 0x6f, 0x61, 0x64, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x12, 0x24, 0x2e, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e,
+	0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f, 0x77, 0x2e, 0x52, 0x65, 0x6c, 0x6f,
+	0x61, 0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x49, 0x6e, 0x1a, 0x25, 0x2e, 0x64, 0x61, 0x74,
+	0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x61, 0x72, 0x72, 0x6f,
+	0x77, 0x2e, 0x52, 0x65, 0x6c, 0x6f, 0x61, 0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4f, 0x75,
+	0x74, 0x22, 0x00, 0x42, 0x20, 0x5a, 0x1e, 0x67, 0x6f, 0x2d, 0x64, 0x75, 0x63, 0x6b, 0x64, 0x62,
+	0x2f, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x5f,
+	0x61, 0x72, 0x72, 0x6f, 0x77, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescOnce sync.Once
+	file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescData = file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDesc
+)
+
+func file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescGZIP() []byte {
+	file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescOnce.Do(func() {
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescData = protoimpl.X.CompressGZIP(file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescData)
+	})
+	return file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDescData
+}
+
+var file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes = make([]protoimpl.MessageInfo, 36)
+var file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_goTypes = []any{
+	(JSONFormat)(0),           // 0: data_transform_arrow.JSONFormat
+	(DecimalEncoding)(0),      // 1: data_transform_arrow.DecimalEncoding
+	(ProfileKind)(0),          // 2: data_transform_arrow.ProfileKind
+	(*QueryOut)(nil),          // 3: data_transform_arrow.QueryOut
+	(*Progress)(nil),          // 4: data_transform_arrow.Progress
+	(*JSONOptions)(nil),       // 5: data_transform_arrow.JSONOptions
+	(*Pipeline)(nil),          // 6: data_transform_arrow.Pipeline
+	(*Step)(nil),              // 7: data_transform_arrow.Step
+	(*SelectStep)(nil),        // 8: data_transform_arrow.SelectStep
+	(*Rename)(nil),            // 9: data_transform_arrow.Rename
+	(*RenameStep)(nil),        // 10: data_transform_arrow.RenameStep
+	(*FillNullsStep)(nil),     // 11: data_transform_arrow.FillNullsStep
+	(*Condition)(nil),         // 12: data_transform_arrow.Condition
+	(*FilterStep)(nil),        // 13: data_transform_arrow.FilterStep
+	(*Operand)(nil),           // 14: data_transform_arrow.Operand
+	(*DeriveStep)(nil),        // 15: data_transform_arrow.DeriveStep
+	(*Measure)(nil),           // 16: data_transform_arrow.Measure
+	(*AggregateStep)(nil),     // 17: data_transform_arrow.AggregateStep
+	(*GroupingSet)(nil),       // 18: data_transform_arrow.GroupingSet
+	(*GroupingSetsStep)(nil),  // 19: data_transform_arrow.GroupingSetsStep
+	(*SortKey)(nil),           // 20: data_transform_arrow.SortKey
+	(*SortStep)(nil),          // 21: data_transform_arrow.SortStep
+	(*LimitStep)(nil),         // 22: data_transform_arrow.LimitStep
+	(*SubtotalStep)(nil),      // 23: data_transform_arrow.SubtotalStep
+	(*QueryIn)(nil),           // 24: data_transform_arrow.QueryIn
+	(*CompiledQuery)(nil),     // 25: data_transform_arrow.CompiledQuery
+	(*ExplainIn)(nil),         // 26: data_transform_arrow.ExplainIn
+	(*ExplainOut)(nil),        // 27: data_transform_arrow.ExplainOut
+	(*CacheStatsIn)(nil),      // 28: data_transform_arrow.CacheStatsIn
+	(*CacheStatsOut)(nil),     // 29: data_transform_arrow.CacheStatsOut
+	(*ProfileIn)(nil),         // 30: data_transform_arrow.ProfileIn
+	(*ProfileOut)(nil),        // 31: data_transform_arrow.ProfileOut
+	(*QueryHistoryIn)(nil),    // 32: data_transform_arrow.QueryHistoryIn
+	(*QueryHistoryEntry)(nil), // 33: data_transform_arrow.QueryHistoryEntry
+	(*QueryHistoryOut)(nil),   // 34: data_transform_arrow.QueryHistoryOut
+	(*ReloadConfigIn)(nil),    // 35: data_transform_arrow.ReloadConfigIn
+	(*ConfigChange)(nil),      // 36: data_transform_arrow.ConfigChange
+	(*ReloadConfigOut)(nil),   // 37: data_transform_arrow.ReloadConfigOut
+	nil,                       // 38: data_transform_arrow.QueryHistoryEntry.PhaseMsEntry
+}
+var file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_depIdxs = []int32{
+	4,  // 0: data_transform_arrow.QueryOut.progress:type_name -> data_transform_arrow.Progress
+	0,  // 1: data_transform_arrow.JSONOptions.format:type_name -> data_transform_arrow.JSONFormat
+	1,  // 2: data_transform_arrow.JSONOptions.decimals:type_name -> data_transform_arrow.DecimalEncoding
+	7,  // 3: data_transform_arrow.Pipeline.steps:type_name -> data_transform_arrow.Step
+	8,  // 4: data_transform_arrow.Step.select:type_name -> data_transform_arrow.SelectStep
+	10, // 5: data_transform_arrow.Step.rename:type_name -> data_transform_arrow.RenameStep
+	11, // 6: data_transform_arrow.Step.fill_nulls:type_name -> data_transform_arrow.FillNullsStep
+	13, // 7: data_transform_arrow.Step.filter:type_name -> data_transform_arrow.FilterStep
+	15, // 8: data_transform_arrow.Step.derive:type_name -> data_transform_arrow.DeriveStep
+	17, // 9: data_transform_arrow.Step.aggregate:type_name -> data_transform_arrow.AggregateStep
+	19, // 10: data_transform_arrow.Step.grouping_sets:type_name -> data_transform_arrow.GroupingSetsStep
+	21, // 11: data_transform_arrow.Step.sort:type_name -> data_transform_arrow.SortStep
+	22, // 12: data_transform_arrow.Step.limit:type_name -> data_transform_arrow.LimitStep
+	23, // 13: data_transform_arrow.Step.subtotal:type_name -> data_transform_arrow.SubtotalStep
+	9,  // 14: data_transform_arrow.RenameStep.columns:type_name -> data_transform_arrow.Rename
+	12, // 15: data_transform_arrow.FilterStep.conditions:type_name -> data_transform_arrow.Condition
+	14, // 16: data_transform_arrow.DeriveStep.args:type_name -> data_transform_arrow.Operand
+	16, // 17: data_transform_arrow.AggregateStep.measures:type_name -> data_transform_arrow.Measure
+	18, // 18: data_transform_arrow.GroupingSetsStep.sets:type_name -> data_transform_arrow.GroupingSet
+	16, // 19: data_transform_arrow.GroupingSetsStep.measures:type_name -> data_transform_arrow.Measure
+	20, // 20: data_transform_arrow.SortStep.keys:type_name -> data_transform_arrow.SortKey
+	16, // 21: data_transform_arrow.SubtotalStep.measures:type_name -> data_transform_arrow.Measure
+	5,  // 22: data_transform_arrow.QueryIn.json_options:type_name -> data_transform_arrow.JSONOptions
+	6,  // 23: data_transform_arrow.QueryIn.pipeline:type_name -> data_transform_arrow.Pipeline
+	23, // 24: data_transform_arrow.QueryIn.subtotal:type_name -> data_transform_arrow.SubtotalStep
+	24, // 25: data_transform_arrow.ExplainIn.query:type_name -> data_transform_arrow.QueryIn
+	2,  // 26: data_transform_arrow.ProfileIn.kind:type_name -> data_transform_arrow.ProfileKind
+	38, // 27: data_transform_arrow.QueryHistoryEntry.phase_ms:type_name -> data_transform_arrow.QueryHistoryEntry.PhaseMsEntry
+	33, // 28: data_transform_arrow.QueryHistoryOut.entries:type_name -> data_transform_arrow.QueryHistoryEntry
+	36, // 29: data_transform_arrow.ReloadConfigOut.changes:type_name -> data_transform_arrow.ConfigChange
+	24, // 30: data_transform_arrow.DataTransform.TransformAndStreamArrow:input_type -> data_transform_arrow.QueryIn
+	24, // 31: data_transform_arrow.DataTransform.TransformAndStreamParquet:input_type -> data_transform_arrow.QueryIn
+	24, // 32: data_transform_arrow.DataTransform.TransformAndStreamJSON:input_type -> data_transform_arrow.QueryIn
+	24, // 33: data_transform_arrow.DataTransform.LocalTransformAndStreamArrow:input_type -> data_transform_arrow.QueryIn
+	24, // 34: data_transform_arrow.DataTransform.LocalTransformAndStreamParquet:input_type -> data_transform_arrow.QueryIn
+	24, // 35: data_transform_arrow.DataTransform.LocalTransformAndStreamJSON:input_type -> data_transform_arrow.QueryIn
+	24, // 36: data_transform_arrow.DataTransform.CompilePipeline:input_type -> data_transform_arrow.QueryIn
+	26, // 37: data_transform_arrow.DataTransform.Explain:input_type -> data_transform_arrow.ExplainIn
+	28, // 38: data_transform_arrow.Admin.CacheStats:input_type -> data_transform_arrow.CacheStatsIn
+	30, // 39: data_transform_arrow.Admin.CaptureProfile:input_type -> data_transform_arrow.ProfileIn
+	32, // 40: data_transform_arrow.Admin.QueryHistory:input_type -> data_transform_arrow.QueryHistoryIn
+	35, // 41: data_transform_arrow.Admin.ReloadConfig:input_type -> data_transform_arrow.ReloadConfigIn
+	3,  // 42: data_transform_arrow.DataTransform.TransformAndStreamArrow:output_type -> data_transform_arrow.QueryOut
+	3,  // 43: data_transform_arrow.DataTransform.TransformAndStreamParquet:output_type -> data_transform_arrow.QueryOut
+	3,  // 44: data_transform_arrow.DataTransform.TransformAndStreamJSON:output_type -> data_transform_arrow.QueryOut
+	3,  // 45: data_transform_arrow.DataTransform.LocalTransformAndStreamArrow:output_type -> data_transform_arrow.QueryOut
+	3,  // 46: data_transform_arrow.DataTransform.LocalTransformAndStreamParquet:output_type -> data_transform_arrow.QueryOut
+	3,  // 47: data_transform_arrow.DataTransform.LocalTransformAndStreamJSON:output_type -> data_transform_arrow.QueryOut
+	25, // 48: data_transform_arrow.DataTransform.CompilePipeline:output_type -> data_transform_arrow.CompiledQuery
+	27, // 49: data_transform_arrow.DataTransform.Explain:output_type -> data_transform_arrow.ExplainOut
+	29, // 50: data_transform_arrow.Admin.CacheStats:output_type -> data_transform_arrow.CacheStatsOut
+	31, // 51: data_transform_arrow.Admin.CaptureProfile:output_type -> data_transform_arrow.ProfileOut
+	34, // 52: data_transform_arrow.Admin.QueryHistory:output_type -> data_transform_arrow.QueryHistoryOut
+	37, // 53: data_transform_arrow.Admin.ReloadConfig:output_type -> data_transform_arrow.ReloadConfigOut
+	42, // [42:54] is the sub-list for method output_type
+	30, // [30:42] is the sub-list for method input_type
+	30, // [30:30] is the sub-list for extension type_name
+	30, // [30:30] is the sub-list for extension extendee
+	0,  // [0:30] is the sub-list for field type_name
+}
+
+func init() { file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_init() }
+func file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_init() {
+	if File_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*QueryOut); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*Progress); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*JSONOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*Pipeline); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*Step); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*SelectStep); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*Rename); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*RenameStep); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*FillNullsStep); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*Condition); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*FilterStep); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*Operand); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[12].Exporter = func(v any, i int) any {
+			switch v := v.(*DeriveStep); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[13].Exporter = func(v any, i int) any {
+			switch v := v.(*Measure); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[14].Exporter = func(v any, i int) any {
+			switch v := v.(*AggregateStep); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[15].Exporter = func(v any, i int) any {
+			switch v := v.(*GroupingSet); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[16].Exporter = func(v any, i int) any {
+			switch v := v.(*GroupingSetsStep); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[17].Exporter = func(v any, i int) any {
+			switch v := v.(*SortKey); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[18].Exporter = func(v any, i int) any {
+			switch v := v.(*SortStep); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[19].Exporter = func(v any, i int) any {
+			switch v := v.(*LimitStep); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[20].Exporter = func(v any, i int) any {
+			switch v := v.(*SubtotalStep); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[21].Exporter = func(v any, i int) any {
+			switch v := v.(*QueryIn); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[22].Exporter = func(v any, i int) any {
+			switch v := v.(*CompiledQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[23].Exporter = func(v any, i int) any {
+			switch v := v.(*ExplainIn); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[24].Exporter = func(v any, i int) any {
+			switch v := v.(*ExplainOut); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[25].Exporter = func(v any, i int) any {
+			switch v := v.(*CacheStatsIn); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[26].Exporter = func(v any, i int) any {
+			switch v := v.(*CacheStatsOut); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[27].Exporter = func(v any, i int) any {
+			switch v := v.(*ProfileIn); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[28].Exporter = func(v any, i int) any {
+			switch v := v.(*ProfileOut); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[29].Exporter = func(v any, i int) any {
+			switch v := v.(*QueryHistoryIn); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[30].Exporter = func(v any, i int) any {
+			switch v := v.(*QueryHistoryEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[31].Exporter = func(v any, i int) any {
+			switch v := v.(*QueryHistoryOut); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[32].Exporter = func(v any, i int) any {
+			switch v := v.(*ReloadConfigIn); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[33].Exporter = func(v any, i int) any {
+			switch v := v.(*ConfigChange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[34].Exporter = func(v any, i int) any {
+			switch v := v.(*ReloadConfigOut); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[4].OneofWrappers = []any{
+		(*Step_Select)(nil),
+		(*Step_Rename)(nil),
+		(*Step_FillNulls)(nil),
+		(*Step_Filter)(nil),
+		(*Step_Derive)(nil),
+		(*Step_Aggregate)(nil),
+		(*Step_GroupingSets)(nil),
+		(*Step_Sort)(nil),
+		(*Step_Limit)(nil),
+		(*Step_Subtotal)(nil),
+	}
+	file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes[11].OneofWrappers = []any{
+		(*Operand_Column)(nil),
+		(*Operand_Literal)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   2,
+			NumEnums:      3,
+			NumMessages:   36,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_goTypes,
 		DependencyIndexes: file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_depIdxs,
+		EnumInfos:         file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_enumTypes,
 		MessageInfos:      file_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto_msgTypes,
 	}.Build()
 	File_internal_services_grpc_arrow_data_transform_data_tranform_arrow_proto = out.File
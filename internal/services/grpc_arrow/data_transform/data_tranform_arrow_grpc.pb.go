@@ -21,8 +21,12 @@ const _ = grpc.SupportPackageIsVersion8
 const (
 	DataTransform_TransformAndStreamArrow_FullMethodName        = "/data_transform_arrow.DataTransform/TransformAndStreamArrow"
 	DataTransform_TransformAndStreamParquet_FullMethodName      = "/data_transform_arrow.DataTransform/TransformAndStreamParquet"
+	DataTransform_TransformAndStreamJSON_FullMethodName         = "/data_transform_arrow.DataTransform/TransformAndStreamJSON"
 	DataTransform_LocalTransformAndStreamArrow_FullMethodName   = "/data_transform_arrow.DataTransform/LocalTransformAndStreamArrow"
 	DataTransform_LocalTransformAndStreamParquet_FullMethodName = "/data_transform_arrow.DataTransform/LocalTransformAndStreamParquet"
+	DataTransform_LocalTransformAndStreamJSON_FullMethodName    = "/data_transform_arrow.DataTransform/LocalTransformAndStreamJSON"
+	DataTransform_CompilePipeline_FullMethodName                = "/data_transform_arrow.DataTransform/CompilePipeline"
+	DataTransform_Explain_FullMethodName                        = "/data_transform_arrow.DataTransform/Explain"
 )
 
 // DataTransformClient is the client API for DataTransform service.
@@ -34,8 +38,14 @@ type DataTransformClient interface {
 	// A server-to-client streaming RPC.
 	TransformAndStreamArrow(ctx context.Context, in *QueryIn, opts ...grpc.CallOption) (DataTransform_TransformAndStreamArrowClient, error)
 	TransformAndStreamParquet(ctx context.Context, in *QueryIn, opts ...grpc.CallOption) (DataTransform_TransformAndStreamParquetClient, error)
+	TransformAndStreamJSON(ctx context.Context, in *QueryIn, opts ...grpc.CallOption) (DataTransform_TransformAndStreamJSONClient, error)
 	LocalTransformAndStreamArrow(ctx context.Context, in *QueryIn, opts ...grpc.CallOption) (DataTransform_LocalTransformAndStreamArrowClient, error)
 	LocalTransformAndStreamParquet(ctx context.Context, in *QueryIn, opts ...grpc.CallOption) (DataTransform_LocalTransformAndStreamParquetClient, error)
+	LocalTransformAndStreamJSON(ctx context.Context, in *QueryIn, opts ...grpc.CallOption) (DataTransform_LocalTransformAndStreamJSONClient, error)
+	// Loads the source and returns the SQL generated for the pipeline.
+	CompilePipeline(ctx context.Context, in *QueryIn, opts ...grpc.CallOption) (*CompiledQuery, error)
+	// Loads the source and returns the plan of the transformation.
+	Explain(ctx context.Context, in *ExplainIn, opts ...grpc.CallOption) (*ExplainOut, error)
 }
 
 type dataTransformClient struct {
@@ -112,9 +122,42 @@ func (x *dataTransformTransformAndStreamParquetClient) Recv() (*QueryOut, error)
 	return m, nil
 }
 
+func (c *dataTransformClient) TransformAndStreamJSON(ctx context.Context, in *QueryIn, opts ...grpc.CallOption) (DataTransform_TransformAndStreamJSONClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DataTransform_ServiceDesc.Streams[2], DataTransform_TransformAndStreamJSON_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dataTransformTransformAndStreamJSONClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DataTransform_TransformAndStreamJSONClient interface {
+	Recv() (*QueryOut, error)
+	grpc.ClientStream
+}
+
+type dataTransformTransformAndStreamJSONClient struct {
+	grpc.ClientStream
+}
+
+func (x *dataTransformTransformAndStreamJSONClient) Recv() (*QueryOut, error) {
+	m := new(QueryOut)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *dataTransformClient) LocalTransformAndStreamArrow(ctx context.Context, in *QueryIn, opts ...grpc.CallOption) (DataTransform_LocalTransformAndStreamArrowClient, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &DataTransform_ServiceDesc.Streams[2], DataTransform_LocalTransformAndStreamArrow_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &DataTransform_ServiceDesc.Streams[3], DataTransform_LocalTransformAndStreamArrow_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -147,7 +190,7 @@ func (x *dataTransformLocalTransformAndStreamArrowClient) Recv() (*QueryOut, err
 
 func (c *dataTransformClient) LocalTransformAndStreamParquet(ctx context.Context, in *QueryIn, opts ...grpc.CallOption) (DataTransform_LocalTransformAndStreamParquetClient, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &DataTransform_ServiceDesc.Streams[3], DataTransform_LocalTransformAndStreamParquet_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &DataTransform_ServiceDesc.Streams[4], DataTransform_LocalTransformAndStreamParquet_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -178,6 +221,59 @@ func (x *dataTransformLocalTransformAndStreamParquetClient) Recv() (*QueryOut, e
 	return m, nil
 }
 
+func (c *dataTransformClient) LocalTransformAndStreamJSON(ctx context.Context, in *QueryIn, opts ...grpc.CallOption) (DataTransform_LocalTransformAndStreamJSONClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DataTransform_ServiceDesc.Streams[5], DataTransform_LocalTransformAndStreamJSON_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dataTransformLocalTransformAndStreamJSONClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DataTransform_LocalTransformAndStreamJSONClient interface {
+	Recv() (*QueryOut, error)
+	grpc.ClientStream
+}
+
+type dataTransformLocalTransformAndStreamJSONClient struct {
+	grpc.ClientStream
+}
+
+func (x *dataTransformLocalTransformAndStreamJSONClient) Recv() (*QueryOut, error) {
+	m := new(QueryOut)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dataTransformClient) CompilePipeline(ctx context.Context, in *QueryIn, opts ...grpc.CallOption) (*CompiledQuery, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompiledQuery)
+	err := c.cc.Invoke(ctx, DataTransform_CompilePipeline_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataTransformClient) Explain(ctx context.Context, in *ExplainIn, opts ...grpc.CallOption) (*ExplainOut, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExplainOut)
+	err := c.cc.Invoke(ctx, DataTransform_Explain_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DataTransformServer is the server API for DataTransform service.
 // All implementations must embed UnimplementedDataTransformServer
 // for forward compatibility
@@ -187,8 +283,14 @@ type DataTransformServer interface {
 	// A server-to-client streaming RPC.
 	TransformAndStreamArrow(*QueryIn, DataTransform_TransformAndStreamArrowServer) error
 	TransformAndStreamParquet(*QueryIn, DataTransform_TransformAndStreamParquetServer) error
+	TransformAndStreamJSON(*QueryIn, DataTransform_TransformAndStreamJSONServer) error
 	LocalTransformAndStreamArrow(*QueryIn, DataTransform_LocalTransformAndStreamArrowServer) error
 	LocalTransformAndStreamParquet(*QueryIn, DataTransform_LocalTransformAndStreamParquetServer) error
+	LocalTransformAndStreamJSON(*QueryIn, DataTransform_LocalTransformAndStreamJSONServer) error
+	// Loads the source and returns the SQL generated for the pipeline.
+	CompilePipeline(context.Context, *QueryIn) (*CompiledQuery, error)
+	// Loads the source and returns the plan of the transformation.
+	Explain(context.Context, *ExplainIn) (*ExplainOut, error)
 	mustEmbedUnimplementedDataTransformServer()
 }
 
@@ -202,12 +304,24 @@ func (UnimplementedDataTransformServer) TransformAndStreamArrow(*QueryIn, DataTr
 func (UnimplementedDataTransformServer) TransformAndStreamParquet(*QueryIn, DataTransform_TransformAndStreamParquetServer) error {
 	return status.Errorf(codes.Unimplemented, "method TransformAndStreamParquet not implemented")
 }
+func (UnimplementedDataTransformServer) TransformAndStreamJSON(*QueryIn, DataTransform_TransformAndStreamJSONServer) error {
+	return status.Errorf(codes.Unimplemented, "method TransformAndStreamJSON not implemented")
+}
 func (UnimplementedDataTransformServer) LocalTransformAndStreamArrow(*QueryIn, DataTransform_LocalTransformAndStreamArrowServer) error {
 	return status.Errorf(codes.Unimplemented, "method LocalTransformAndStreamArrow not implemented")
 }
 func (UnimplementedDataTransformServer) LocalTransformAndStreamParquet(*QueryIn, DataTransform_LocalTransformAndStreamParquetServer) error {
 	return status.Errorf(codes.Unimplemented, "method LocalTransformAndStreamParquet not implemented")
 }
+func (UnimplementedDataTransformServer) LocalTransformAndStreamJSON(*QueryIn, DataTransform_LocalTransformAndStreamJSONServer) error {
+	return status.Errorf(codes.Unimplemented, "method LocalTransformAndStreamJSON not implemented")
+}
+func (UnimplementedDataTransformServer) CompilePipeline(context.Context, *QueryIn) (*CompiledQuery, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompilePipeline not implemented")
+}
+func (UnimplementedDataTransformServer) Explain(context.Context, *ExplainIn) (*ExplainOut, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Explain not implemented")
+}
 func (UnimplementedDataTransformServer) mustEmbedUnimplementedDataTransformServer() {}
 
 // UnsafeDataTransformServer may be embedded to opt out of forward compatibility for this service.
@@ -263,6 +377,27 @@ func (x *dataTransformTransformAndStreamParquetServer) Send(m *QueryOut) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _DataTransform_TransformAndStreamJSON_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(QueryIn)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DataTransformServer).TransformAndStreamJSON(m, &dataTransformTransformAndStreamJSONServer{ServerStream: stream})
+}
+
+type DataTransform_TransformAndStreamJSONServer interface {
+	Send(*QueryOut) error
+	grpc.ServerStream
+}
+
+type dataTransformTransformAndStreamJSONServer struct {
+	grpc.ServerStream
+}
+
+func (x *dataTransformTransformAndStreamJSONServer) Send(m *QueryOut) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _DataTransform_LocalTransformAndStreamArrow_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(QueryIn)
 	if err := stream.RecvMsg(m); err != nil {
@@ -305,13 +440,79 @@ func (x *dataTransformLocalTransformAndStreamParquetServer) Send(m *QueryOut) er
 	return x.ServerStream.SendMsg(m)
 }
 
+func _DataTransform_LocalTransformAndStreamJSON_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(QueryIn)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DataTransformServer).LocalTransformAndStreamJSON(m, &dataTransformLocalTransformAndStreamJSONServer{ServerStream: stream})
+}
+
+type DataTransform_LocalTransformAndStreamJSONServer interface {
+	Send(*QueryOut) error
+	grpc.ServerStream
+}
+
+type dataTransformLocalTransformAndStreamJSONServer struct {
+	grpc.ServerStream
+}
+
+func (x *dataTransformLocalTransformAndStreamJSONServer) Send(m *QueryOut) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _DataTransform_CompilePipeline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryIn)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataTransformServer).CompilePipeline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataTransform_CompilePipeline_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataTransformServer).CompilePipeline(ctx, req.(*QueryIn))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataTransform_Explain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExplainIn)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataTransformServer).Explain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataTransform_Explain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataTransformServer).Explain(ctx, req.(*ExplainIn))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DataTransform_ServiceDesc is the grpc.ServiceDesc for DataTransform service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var DataTransform_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "data_transform_arrow.DataTransform",
 	HandlerType: (*DataTransformServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CompilePipeline",
+			Handler:    _DataTransform_CompilePipeline_Handler,
+		},
+		{
+			MethodName: "Explain",
+			Handler:    _DataTransform_Explain_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "TransformAndStreamArrow",
@@ -323,6 +524,11 @@ var DataTransform_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _DataTransform_TransformAndStreamParquet_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "TransformAndStreamJSON",
+			Handler:       _DataTransform_TransformAndStreamJSON_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "LocalTransformAndStreamArrow",
 			Handler:       _DataTransform_LocalTransformAndStreamArrow_Handler,
@@ -333,6 +539,238 @@ var DataTransform_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _DataTransform_LocalTransformAndStreamParquet_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "LocalTransformAndStreamJSON",
+			Handler:       _DataTransform_LocalTransformAndStreamJSON_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "internal/services/grpc_arrow/data_transform/data_tranform_arrow.proto",
+}
+
+const (
+	Admin_CacheStats_FullMethodName     = "/data_transform_arrow.Admin/CacheStats"
+	Admin_CaptureProfile_FullMethodName = "/data_transform_arrow.Admin/CaptureProfile"
+	Admin_QueryHistory_FullMethodName   = "/data_transform_arrow.Admin/QueryHistory"
+	Admin_ReloadConfig_FullMethodName   = "/data_transform_arrow.Admin/ReloadConfig"
+)
+
+// AdminClient is the client API for Admin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Administration of the server, restricted to the admin permission.
+type AdminClient interface {
+	// Returns the usage of the download cache.
+	CacheStats(ctx context.Context, in *CacheStatsIn, opts ...grpc.CallOption) (*CacheStatsOut, error)
+	// Captures a profile of the server or of one request, PROFILING_ENABLED
+	// has to be set.
+	CaptureProfile(ctx context.Context, in *ProfileIn, opts ...grpc.CallOption) (*ProfileOut, error)
+	// Returns the transformations recorded in the query history,
+	// QUERY_HISTORY_ENABLED has to be set.
+	QueryHistory(ctx context.Context, in *QueryHistoryIn, opts ...grpc.CallOption) (*QueryHistoryOut, error)
+	// Reads the configuration again and applies the settings that can change
+	// without a restart to the requests starting afterwards, like SIGHUP.
+	// Fails without changing anything when the configuration is invalid or
+	// changes other settings.
+	ReloadConfig(ctx context.Context, in *ReloadConfigIn, opts ...grpc.CallOption) (*ReloadConfigOut, error)
+}
+
+type adminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminClient(cc grpc.ClientConnInterface) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) CacheStats(ctx context.Context, in *CacheStatsIn, opts ...grpc.CallOption) (*CacheStatsOut, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CacheStatsOut)
+	err := c.cc.Invoke(ctx, Admin_CacheStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) CaptureProfile(ctx context.Context, in *ProfileIn, opts ...grpc.CallOption) (*ProfileOut, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProfileOut)
+	err := c.cc.Invoke(ctx, Admin_CaptureProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) QueryHistory(ctx context.Context, in *QueryHistoryIn, opts ...grpc.CallOption) (*QueryHistoryOut, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryHistoryOut)
+	err := c.cc.Invoke(ctx, Admin_QueryHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ReloadConfig(ctx context.Context, in *ReloadConfigIn, opts ...grpc.CallOption) (*ReloadConfigOut, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReloadConfigOut)
+	err := c.cc.Invoke(ctx, Admin_ReloadConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServer is the server API for Admin service.
+// All implementations must embed UnimplementedAdminServer
+// for forward compatibility
+//
+// Administration of the server, restricted to the admin permission.
+type AdminServer interface {
+	// Returns the usage of the download cache.
+	CacheStats(context.Context, *CacheStatsIn) (*CacheStatsOut, error)
+	// Captures a profile of the server or of one request, PROFILING_ENABLED
+	// has to be set.
+	CaptureProfile(context.Context, *ProfileIn) (*ProfileOut, error)
+	// Returns the transformations recorded in the query history,
+	// QUERY_HISTORY_ENABLED has to be set.
+	QueryHistory(context.Context, *QueryHistoryIn) (*QueryHistoryOut, error)
+	// Reads the configuration again and applies the settings that can change
+	// without a restart to the requests starting afterwards, like SIGHUP.
+	// Fails without changing anything when the configuration is invalid or
+	// changes other settings.
+	ReloadConfig(context.Context, *ReloadConfigIn) (*ReloadConfigOut, error)
+	mustEmbedUnimplementedAdminServer()
+}
+
+// UnimplementedAdminServer must be embedded to have forward compatible implementations.
+type UnimplementedAdminServer struct {
+}
+
+func (UnimplementedAdminServer) CacheStats(context.Context, *CacheStatsIn) (*CacheStatsOut, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CacheStats not implemented")
+}
+func (UnimplementedAdminServer) CaptureProfile(context.Context, *ProfileIn) (*ProfileOut, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CaptureProfile not implemented")
+}
+func (UnimplementedAdminServer) QueryHistory(context.Context, *QueryHistoryIn) (*QueryHistoryOut, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryHistory not implemented")
+}
+func (UnimplementedAdminServer) ReloadConfig(context.Context, *ReloadConfigIn) (*ReloadConfigOut, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadConfig not implemented")
+}
+func (UnimplementedAdminServer) mustEmbedUnimplementedAdminServer() {}
+
+// UnsafeAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminServer will
+// result in compilation errors.
+type UnsafeAdminServer interface {
+	mustEmbedUnimplementedAdminServer()
+}
+
+func RegisterAdminServer(s grpc.ServiceRegistrar, srv AdminServer) {
+	s.RegisterService(&Admin_ServiceDesc, srv)
+}
+
+func _Admin_CacheStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CacheStatsIn)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).CacheStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_CacheStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).CacheStats(ctx, req.(*CacheStatsIn))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_CaptureProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProfileIn)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).CaptureProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_CaptureProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).CaptureProfile(ctx, req.(*ProfileIn))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_QueryHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryHistoryIn)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).QueryHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_QueryHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).QueryHistory(ctx, req.(*QueryHistoryIn))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ReloadConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadConfigIn)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ReloadConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_ReloadConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ReloadConfig(ctx, req.(*ReloadConfigIn))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Admin_ServiceDesc is the grpc.ServiceDesc for Admin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Admin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "data_transform_arrow.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CacheStats",
+			Handler:    _Admin_CacheStats_Handler,
+		},
+		{
+			MethodName: "CaptureProfile",
+			Handler:    _Admin_CaptureProfile_Handler,
+		},
+		{
+			MethodName: "QueryHistory",
+			Handler:    _Admin_QueryHistory_Handler,
+		},
+		{
+			MethodName: "ReloadConfig",
+			Handler:    _Admin_ReloadConfig_Handler,
+		},
 	},
+	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/services/grpc_arrow/data_transform/data_tranform_arrow.proto",
 }
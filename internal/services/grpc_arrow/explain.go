@@ -0,0 +1,84 @@
+package grpc_arrow
+
+import (
+	"context"
+	"duckdb-server/internal/metrics"
+	querybuilder "duckdb-server/internal/query_builder"
+	pb "duckdb-server/internal/services/grpc_arrow/data_transform"
+	"encoding/json"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// queryIn returns the QueryIn of a request, the one of an ExplainIn included,
+// so that the interceptors check explained transformations like the others.
+func queryIn(m any) (*pb.QueryIn, bool) {
+	switch m := m.(type) {
+	case *pb.QueryIn:
+		return m, true
+	case *pb.ExplainIn:
+		return m.Query, m.Query != nil
+	}
+	return nil, false
+}
+
+func (t dataTransform) Explain(ctx context.Context, in *pb.ExplainIn) (*pb.ExplainOut, error) {
+	if in.Query == nil {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+
+	ws := workspaceFromContext(ctx)
+
+	if err := t.loadSource(ctx, in.Query, ws.table); err != nil {
+		return nil, err
+	}
+
+	query, err := t.transformQuery(ctx, ws, in.Query)
+	if err != nil {
+		slog.WarnContext(ctx, "error compiling transformation", "err", err)
+		return nil, err
+	}
+	if query == "" {
+		return nil, status.Error(codes.InvalidArgument, "query, pipeline or subtotal is required")
+	}
+
+	if err := t.createView(ctx, ws, query); err != nil {
+		return nil, err
+	}
+
+	var (
+		plan   string
+		parsed *querybuilder.Plan
+	)
+	_, run := startPhase(ctx, metrics.PhaseQuery)
+	if in.Analyze {
+		// the query runs once, its profile is the plan
+		plan, parsed, err = t.qb.ExplainPlan(ctx, querybuilder.SelectAll(ws.view), true)
+	} else {
+		plan, err = t.qb.Explain(ctx, querybuilder.SelectAll(ws.view), false)
+		if err == nil {
+			_, parsed, err = t.qb.ExplainPlan(ctx, querybuilder.SelectAll(ws.view), false)
+		}
+	}
+	run.end(err)
+	if err != nil && plan == "" {
+		slog.ErrorContext(ctx, "error explaining query", "err", err)
+		return nil, err
+	}
+
+	out := &pb.ExplainOut{Plan: plan}
+	if err != nil {
+		// the plan rendered by DuckDB is still of use
+		slog.WarnContext(ctx, "error parsing plan", "err", err)
+		return out, nil
+	}
+
+	js, err := json.Marshal(parsed)
+	if err != nil {
+		return nil, err
+	}
+	out.PlanJson = string(js)
+	return out, nil
+}
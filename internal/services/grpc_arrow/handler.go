@@ -3,20 +3,29 @@ package grpc_arrow
 import (
 	"context"
 	"duckdb-server/config"
+	"duckdb-server/internal/fetch"
+	"duckdb-server/internal/logging"
+	"duckdb-server/internal/metrics"
+	"duckdb-server/internal/objectstore"
 	querybuilder "duckdb-server/internal/query_builder"
+	"duckdb-server/internal/sandbox"
 	pb "duckdb-server/internal/services/grpc_arrow/data_transform"
+	"duckdb-server/internal/sqlcheck"
+	"duckdb-server/internal/tracing"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
 	"path"
-	"runtime/pprof"
-	"strings"
+	"sync/atomic"
 	"time"
 
 	utilsQuery "duckdb-server/internal/utils/query"
 
 	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -27,123 +36,211 @@ const _ = grpc.SupportPackageIsVersion7
 // UnimplementedDataTransformServer must be embedded to have forward compatible implementations.
 type dataTransform struct {
 	pb.UnimplementedDataTransformServer
-	qb *querybuilder.DuckDBQueryBuilder
+	// cfg is swapped by the configuration reloads, requests take it once
+	cfg *atomic.Pointer[config.Config]
+	qb  *querybuilder.DuckDBQueryBuilder
+	// tmp tracks the downloaded and exported files
+	tmp *tempFiles
+	// sandbox holds the directories local sources can be read from
+	sandbox *sandbox.Sandbox
+	// sql checks the transformations given as SQL
+	sql *sqlcheck.Validator
+	// fetcher downloads the http(s) sources
+	fetcher *fetch.Fetcher
+	// cache keeps the http(s) sources, nil when disabled
+	cache *fetch.Cache
+	// store reads the s3:// sources and writes the s3:// destinations
+	store *objectstore.Store
+	// sizes caches the sizes of the remote sources for admission
+	sizes *sizeCache
 }
 
-func NewDataTransformService() *dataTransform {
-	p := path.Join(config.DUCKDB_DIR, fmt.Sprintf("data-%d.duckdb", time.Now().Unix()))
-	qb, err := querybuilder.NewDuckDBQueryBuilder(p)
+func NewDataTransformService(cfg *config.Config) (*dataTransform, error) {
+	sb, err := sandbox.New(cfg.Sandbox.Roots)
 	if err != nil {
-		log.Fatalf("Error creating query builder, err: %v\n", err)
+		slog.Error("error creating sandbox", "err", err)
+		return nil, err
 	}
 
-	return &dataTransform{
-		qb: qb,
+	opts := querybuilder.Options{
+		LockConfiguration: cfg.Sandbox.LockConfiguration,
+		MemoryLimit:       cfg.DuckDB.MemoryLimit,
+		Threads:           cfg.DuckDB.Threads,
+	}
+	if sb.Enabled() {
+		// the server itself writes downloads, exports and spills there
+		opts.AllowedDirectories = append([]string{cfg.Dirs.TempDownload, cfg.Dirs.TempDuckDB}, sb.Roots()...)
+	} else {
+		slog.Warn("SANDBOX_ROOTS is not set, requests can read any file of the server")
+	}
+
+	fetcher, err := fetch.New(fetch.Config{
+		Timeout:        cfg.Download.Timeout,
+		ConnectTimeout: cfg.Download.ConnectTimeout,
+		Retries:        cfg.Download.Retries,
+		Backoff:        cfg.Download.Backoff,
+		MaxBytes:       cfg.Download.MaxBytes,
+		AllowHTTP:      cfg.Download.AllowHTTP,
+		AllowedHosts:   cfg.Download.AllowedHosts,
+		DeniedHosts:    cfg.Download.DeniedHosts,
+		AllowedCIDRs:   cfg.Download.AllowedCIDRs,
+		DeniedCIDRs:    cfg.Download.DeniedCIDRs,
+	})
+	if err != nil {
+		slog.Error("error creating fetcher", "err", err)
+		return nil, err
 	}
-}
-
-func (t dataTransform) TransformAndStreamArrow(in *pb.QueryIn, stream pb.DataTransform_TransformAndStreamArrowServer) error {
-	defer func() {
-		log.Println("computed transform")
-	}()
 
-	// mem profiler
-	{
-		p := path.Join(config.TEMP_PROF_DIR, fmt.Sprintf("online-arrow-grpc-mem-%s.prof", time.Now().UTC().Format("2006-01-02 15:04:05")))
-		f, err := os.Create(p)
+	var cache *fetch.Cache
+	if cfg.Download.CacheMaxBytes > 0 {
+		cache, err = fetch.NewCache(fetcher, path.Join(cfg.Dirs.TempDownload, "cache"), cfg.Download.CacheMaxBytes)
 		if err != nil {
-			fmt.Printf("error creating cpu profiler, err: %v\n", err)
-			return err
-
+			slog.Error("error opening download cache", "err", err)
+			return nil, err
 		}
-		defer pprof.WriteHeapProfile(f)
 	}
 
-	// cpu profiler
-	{
-		p := path.Join(config.TEMP_PROF_DIR, fmt.Sprintf("online-arrow-grpc-cpu-%s.prof", time.Now().UTC().Format("2006-01-02 15:04:05")))
-		f, err := os.Create(p)
-		if err != nil {
-			fmt.Printf("error creating cpu profiler, err: %v\n", err)
-			return err
+	store, err := objectstore.New(objectstore.Config{
+		Endpoint:        cfg.S3.Endpoint,
+		Region:          cfg.S3.Region,
+		AccessKeyID:     cfg.S3.AccessKeyID,
+		SecretAccessKey: cfg.S3.SecretAccessKey,
+		SessionToken:    cfg.S3.SessionToken,
+		PathStyle:       cfg.S3.PathStyle,
+		PartSize:        uint64(cfg.S3.PartSize),
+		AllowedBuckets:  cfg.S3.AllowedBuckets,
+	})
+	if err != nil {
+		slog.Error("error creating object store", "err", err)
+		return nil, err
+	}
 
-		}
-		pprof.StartCPUProfile(f)
-		defer pprof.StopCPUProfile()
+	p := path.Join(cfg.Dirs.DuckDB, fmt.Sprintf("data-%d.duckdb", time.Now().Unix()))
+	qb, err := querybuilder.NewDuckDBQueryBuilder(p, opts)
+	if errors.Is(err, querybuilder.ErrSandboxUnsupported) {
+		slog.Error("SANDBOX_ROOTS is set but queries can't be kept in the sandbox", "err", err)
+		return nil, err
 	}
+	if err != nil {
+		slog.Error("error creating query builder", "err", err)
+		return nil, err
+	}
+
+	current := new(atomic.Pointer[config.Config])
+	current.Store(cfg)
+
+	t := &dataTransform{
+		cfg:     current,
+		qb:      qb,
+		tmp:     newTempFiles(),
+		sandbox: sb,
+		sql: sqlcheck.NewValidator(sqlcheck.Rules{
+			MaxLength:             cfg.SQL.MaxLength,
+			AllowedRelations:      cfg.SQL.AllowedRelations,
+			AllowedTableFunctions: cfg.SQL.AllowedTableFunctions,
+			DeniedFunctions:       cfg.SQL.DeniedFunctions,
+		}),
+		fetcher: fetcher,
+		cache:   cache,
+		store:   store,
+	}
+	t.sizes = newSizeCache(t.remoteSize)
+	return t, nil
+}
 
-	ctx := context.Background()
+// config returns the current configuration.
+func (t dataTransform) config() *config.Config {
+	return t.cfg.Load()
+}
+
+// Close checkpoints and closes the database and removes the temporary files
+// left behind by the requests. It must only be called once no request is
+// running anymore.
+func (t dataTransform) Close() error {
+	var errs []error
+	if err := t.qb.Exec("CHECKPOINT"); err != nil {
+		errs = append(errs, fmt.Errorf("checkpoint: %w", err))
+	}
+	if err := t.qb.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("close: %w", err))
+	}
+	if err := t.tmp.RemoveAll(); err != nil {
+		errs = append(errs, fmt.Errorf("remove temp files: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+func (t dataTransform) TransformAndStreamArrow(in *pb.QueryIn, stream pb.DataTransform_TransformAndStreamArrowServer) error {
+	ctx := stream.Context()
 	arrowQB, err := t.qb.GetArrow(ctx)
 	if err != nil {
-		log.Fatalf("Error creating arrow query builder, err: %v\n", err)
+		slog.ErrorContext(ctx, "error creating arrow query builder", "err", err)
+		return err
 	}
 
 	defer func() {
 		if err := arrowQB.Close(); err != nil {
-			log.Printf("Error closing arrow query builder, err: %v\n", err)
+			slog.ErrorContext(ctx, "error closing arrow query builder", "err", err)
 		}
 	}()
 
-	const (
-		tableName = "loadtest"
-		viewName  = "v_loadtest"
-	)
+	ws := workspaceFromContext(ctx)
 
-	var filePath string
-	if !strings.Contains(in.Path, "https://") {
-		filePath = in.Path
-	} else {
-		log.Println("Downloading file since received path is http(s)")
-		filePath = path.Join(config.TEMP_DOWNLOAD_DIR, fmt.Sprintf("%d .csv", time.Now().Unix()))
-		f, err := os.Create(filePath)
-		if err != nil {
-			log.Printf("error creating file, err: %v\n", err)
-			return err
-		}
-
-		if err := utilsQuery.DownloadFile(in.Path, f); err != nil {
-			log.Printf("error downloading file, err: %v\n", err)
-			return err
-		}
+	if err := t.loadSource(ctx, in, ws.table); err != nil {
+		return err
 	}
 
-	log.Println("Loading data to duckDB")
-	if err := t.qb.CSVToTable(tableName, filePath); err != nil {
-		log.Printf("error loading data to duck-db, err: %v\n", err)
+	slog.DebugContext(ctx, "creating view for the transformation query")
+	query, err := t.transformQuery(ctx, ws, in)
+	if err != nil {
+		slog.WarnContext(ctx, "error compiling transformation", "err", err)
 		return err
 	}
 
-	log.Println("Creating view for the transformation query")
-	if err := t.qb.Exec(utilsQuery.CreateView(viewName, tableName)); err != nil {
-		log.Printf("error creating view, err: %v\n", err)
+	if query == "" {
+		// no transformation given, run the customers load test
+		query, err = utilsQuery.CustomersQuery(sourceRelation)
+		if err != nil {
+			slog.WarnContext(ctx, "error compiling transformation", "err", err)
+			return err
+		}
+	}
+
+	if err := t.createView(ctx, ws, query); err != nil {
 		return err
 	}
 
-	log.Println("Querying the view")
-	rows, err := arrowQB.Query(ctx, fmt.Sprintf("SELECT * FROM %s", viewName))
+	slog.DebugContext(ctx, "querying the view")
+	_, run := startPhase(ctx, metrics.PhaseQuery)
+	unwatch := progressFromContext(ctx).watch(arrowQB)
+	rows, err := arrowQB.Query(ctx, querybuilder.SelectAll(ws.view))
+	unwatch()
+	run.end(err)
 	if err != nil {
-		log.Printf("Error querying data, err: %s\n", err.Error())
+		slog.ErrorContext(ctx, "error querying data", "err", err)
 		return err
 	}
 
-	limit := config.CHUNK_SIZE
+	limit := t.config().Stream.ChunkSize
 	sequencyNumber := 1
-	log.Println("Chunking the query result")
+	slog.DebugContext(ctx, "chunking the query result")
 	for {
-		q, err := utilsQuery.GetChunk(rows, int64(config.CHUNK_SIZE))
+		_, encode := startPhase(ctx, metrics.PhaseEncode)
+		q, err := utilsQuery.GetChunk(rows, int64(limit))
+		encode.end(err, chunkAttributes(q)...)
 		if err != nil {
-			log.Printf("error getting data, err: %v\n", err)
+			slog.ErrorContext(ctx, "error getting data", "err", err)
 			return err
 		}
 
 		q.SequencyNumber = int32(sequencyNumber)
 		if err := stream.Send(q); err != nil {
-			log.Printf("error streaming data, err: %v\n", err)
+			slog.ErrorContext(ctx, "error streaming data", "err", err)
 			return err
 		}
 
 		if int(q.Count) < limit {
-			log.Printf("breaking because got %d rows out of %d", q.Count, limit)
+			slog.DebugContext(ctx, "last chunk", "rows", q.Count, "limit", limit)
 			break
 		}
 
@@ -151,208 +248,167 @@ func (t dataTransform) TransformAndStreamArrow(in *pb.QueryIn, stream pb.DataTra
 		sequencyNumber += 1
 	}
 
-	log.Println("Sent all chunks from the server")
+	slog.DebugContext(ctx, "sent all chunks")
 	return nil
 }
 
 func (t dataTransform) TransformAndStreamParquet(in *pb.QueryIn, stream pb.DataTransform_TransformAndStreamParquetServer) error {
-	defer func() {
-		log.Println("computed transform")
-	}()
-
-	// mem profiler
-	{
-		p := path.Join(config.TEMP_PROF_DIR, fmt.Sprintf("online-parquet-grpc-mem-%s.prof", time.Now().UTC().Format("2006-01-02 15:04:05")))
-		f, err := os.Create(p)
-		if err != nil {
-			fmt.Printf("error creating cpu profiler, err: %v\n", err)
-			return err
-
-		}
-		defer pprof.WriteHeapProfile(f)
-	}
-
-	// cpu profiler
-	{
-		p := path.Join(config.TEMP_PROF_DIR, fmt.Sprintf("online-parquet-grpc-cpu-%s.prof", time.Now().UTC().Format("2006-01-02 15:04:05")))
-		f, err := os.Create(p)
-		if err != nil {
-			fmt.Printf("error creating cpu profiler, err: %v\n", err)
-			return err
-
-		}
-		pprof.StartCPUProfile(f)
-		defer pprof.StopCPUProfile()
+	if err := checkDestination(in.Destination); err != nil {
+		return err
 	}
 
-	ctx := context.Background()
+	ctx := stream.Context()
 	arrowQB, err := t.qb.GetArrow(ctx)
 	if err != nil {
-		log.Fatalf("Error creating arrow query builder, err: %v\n", err)
+		slog.ErrorContext(ctx, "error creating arrow query builder", "err", err)
+		return err
 	}
 
 	defer func() {
 		if err := arrowQB.Close(); err != nil {
-			log.Printf("Error closing arrow query builder, err: %v\n", err)
+			slog.ErrorContext(ctx, "error closing arrow query builder", "err", err)
 		}
 	}()
 
-	const (
-		tableName = "loadtest"
-		viewName  = "v_loadtest"
-	)
+	ws := workspaceFromContext(ctx)
 
-	downloadPath := path.Join(config.TEMP_DOWNLOAD_DIR, fmt.Sprintf("%d.csv", time.Now().Unix()))
-	f, err := os.Create(downloadPath)
-	if err != nil {
-		log.Printf("error creating file, err: %v\n", err)
+	if err := t.loadSource(ctx, in, ws.table); err != nil {
 		return err
 	}
 
-	if err := utilsQuery.DownloadFile(in.Path, f); err != nil {
-		log.Printf("error downloading file, err: %v\n", err)
+	slog.DebugContext(ctx, "creating view for the transformation query")
+	query, err := t.transformQuery(ctx, ws, in)
+	if err != nil {
+		slog.WarnContext(ctx, "error compiling transformation", "err", err)
 		return err
 	}
 
-	log.Println("Loading data to duckDB")
-	if err := t.qb.CSVToTable(tableName, downloadPath); err != nil {
-		log.Printf("error loading data to duck-db, err: %v\n", err)
+	if err := t.createView(ctx, ws, query); err != nil {
 		return err
 	}
 
-	log.Println("Creating view for the transformation query")
-	if err := t.qb.Exec(utilsQuery.CreateViewV2(viewName, in.Query)); err != nil {
-		log.Printf("error creating view, err: %v\n", err)
+	slog.DebugContext(ctx, "querying the view")
+	exportPath := t.tempPath(".parquet")
+	t.tmp.Add(exportPath)
+	defer t.tmp.Remove(exportPath)
+	_, encode := startPhase(ctx, metrics.PhaseEncode)
+	unwatch := progressFromContext(ctx).watch(arrowQB)
+	err = arrowQB.CopyToParquet(ctx, ws.view, exportPath, querybuilder.ParquetOptions{Compression: "gzip", FooterKey: querybuilder.PARQUET_KEY_NAME})
+	unwatch()
+	encode.end(err, fileAttributes(exportPath)...)
+	if err != nil {
+		slog.ErrorContext(ctx, "error writing data to parquet", "err", err)
 		return err
 	}
 
-	log.Println("Querying the view")
-	exportPath := path.Join(config.TEMP_DOWNLOAD_DIR, fmt.Sprintf("%d.parquet", time.Now().Unix()))
-	_, err = t.qb.Query(fmt.Sprintf("COPY %s TO '%s' (ENCRYPTION_CONFIG {footer_key: 'key256'}, FORMAT PARQUET, COMPRESSION 'gzip');", viewName, exportPath))
-	if err != nil {
-		log.Printf("Error writing data to parquet, err: %s\n", err.Error())
-		return err
+	if in.Destination != "" {
+		return t.upload(ctx, exportPath, in.Destination)
 	}
 
 	outFile, err := os.Open(exportPath)
 	if err != nil {
-		log.Printf("Error reading data from parquet, err: %s\n", err.Error())
+		slog.ErrorContext(ctx, "error reading data from parquet", "err", err)
 		return err
 	}
+	defer outFile.Close()
 
 	sequencyNumber := 1
-	buf := make([]byte, config.FILE_CHUNK_SIZE)
-	log.Println("Chunking the query result")
+	buf := make([]byte, t.config().Stream.FileChunkSize)
+	slog.DebugContext(ctx, "chunking the query result")
 	for {
 		q := &pb.QueryOut{}
 		_, err := outFile.Read(buf)
 		if err == io.EOF {
-			log.Printf("reached EOF thus breaking\n")
+			slog.DebugContext(ctx, "reached the end of the export")
 			break
 		}
 		if err != nil {
-			log.Printf("error getting data, err: %v\n", err)
+			slog.ErrorContext(ctx, "error getting data", "err", err)
 			return err
 		}
 
 		q.SequencyNumber = int32(sequencyNumber)
 		if err := stream.Send(q); err != nil {
-			log.Printf("error streaming data, err: %v\n", err)
+			slog.ErrorContext(ctx, "error streaming data", "err", err)
 			return err
 		}
 
 		sequencyNumber += 1
 	}
 
-	log.Println("Sent all chunks from the server")
+	slog.DebugContext(ctx, "sent all chunks")
 	return nil
 }
 
 func (t dataTransform) LocalTransformAndStreamArrow(in *pb.QueryIn, stream pb.DataTransform_LocalTransformAndStreamArrowServer) error {
-	defer func() {
-		log.Println("computed transform")
-	}()
-
-	// mem profiler
-	{
-		p := path.Join(config.TEMP_PROF_DIR, fmt.Sprintf("local-arrow-grpc-mem-%s.prof", time.Now().UTC().Format("2006-01-02 15:04:05")))
-		f, err := os.Create(p)
-		if err != nil {
-			fmt.Printf("error creating cpu profiler, err: %v\n", err)
-			return err
-
-		}
-		defer pprof.WriteHeapProfile(f)
-	}
-
-	// cpu profiler
-	{
-		p := path.Join(config.TEMP_PROF_DIR, fmt.Sprintf("local-arrow-grpc-cpu-%s.prof", time.Now().UTC().Format("2006-01-02 15:04:05")))
-		f, err := os.Create(p)
-		if err != nil {
-			fmt.Printf("error creating cpu profiler, err: %v\n", err)
-			return err
-
-		}
-		pprof.StartCPUProfile(f)
-		defer pprof.StopCPUProfile()
-	}
-
-	ctx := context.Background()
+	ctx := stream.Context()
 	arrowQB, err := t.qb.GetArrow(ctx)
 	if err != nil {
-		log.Fatalf("Error creating arrow query builder, err: %v\n", err)
+		slog.ErrorContext(ctx, "error creating arrow query builder", "err", err)
+		return err
 	}
 
 	defer func() {
 		if err := arrowQB.Close(); err != nil {
-			log.Printf("Error closing arrow query builder, err: %v\n", err)
+			slog.ErrorContext(ctx, "error closing arrow query builder", "err", err)
 		}
 	}()
 
-	const (
-		tableName = "loadtest"
-		viewName  = "v_loadtest"
-	)
+	ws := workspaceFromContext(ctx)
+
+	filePath, err := t.sandbox.Resolve(in.Path)
+	if err != nil {
+		slog.WarnContext(ctx, "error resolving path", "err", err)
+		return err
+	}
+
+	if err := t.loadCSV(ctx, ws.table, filePath); err != nil {
+		return err
+	}
 
-	log.Println("Loading data to duckDB")
-	if err := t.qb.CSVToTable(tableName, in.Path); err != nil {
-		log.Printf("error loading data to duck-db, err: %v\n", err)
+	slog.DebugContext(ctx, "creating view for the transformation query")
+	query, err := t.transformQuery(ctx, ws, in)
+	if err != nil {
+		slog.WarnContext(ctx, "error compiling transformation", "err", err)
 		return err
 	}
 
-	log.Println("Creating view for the transformation query")
-	if err := t.qb.Exec(utilsQuery.CreateViewV2(viewName, in.Query)); err != nil {
-		log.Printf("error creating view, err: %v\n", err)
+	if err := t.createView(ctx, ws, query); err != nil {
 		return err
 	}
 
-	log.Println("Querying the view")
-	rows, err := arrowQB.Query(ctx, fmt.Sprintf("SELECT * FROM %s", viewName))
+	slog.DebugContext(ctx, "querying the view")
+	_, run := startPhase(ctx, metrics.PhaseQuery)
+	unwatch := progressFromContext(ctx).watch(arrowQB)
+	rows, err := arrowQB.Query(ctx, querybuilder.SelectAll(ws.view))
+	unwatch()
+	run.end(err)
 	if err != nil {
-		log.Printf("Error querying data, err: %s\n", err.Error())
+		slog.ErrorContext(ctx, "error querying data", "err", err)
 		return err
 	}
 
-	limit := config.CHUNK_SIZE
+	limit := t.config().Stream.ChunkSize
 	sequencyNumber := 1
-	log.Println("Chunking the query result")
+	slog.DebugContext(ctx, "chunking the query result")
 	for {
 		// q, err := utilsQuery.ArrowTransformV2(arrowQB, fmt.Sprintf("SELECT * FROM %s LIMIT %d OFFSET %d", viewName, limit, offset))
-		q, err := utilsQuery.GetChunk(rows, int64(config.CHUNK_SIZE))
+		_, encode := startPhase(ctx, metrics.PhaseEncode)
+		q, err := utilsQuery.GetChunk(rows, int64(limit))
+		encode.end(err, chunkAttributes(q)...)
 		if err != nil {
-			log.Printf("error getting data, err: %v\n", err)
+			slog.ErrorContext(ctx, "error getting data", "err", err)
 			return err
 		}
 
 		q.SequencyNumber = int32(sequencyNumber)
 		if err := stream.Send(q); err != nil {
-			log.Printf("error streaming data, err: %v\n", err)
+			slog.ErrorContext(ctx, "error streaming data", "err", err)
 			return err
 		}
 
 		if int(q.Count) < limit {
-			log.Printf("breaking because got %d rows out of %d", q.Count, limit)
+			slog.DebugContext(ctx, "last chunk", "rows", q.Count, "limit", limit)
 			break
 		}
 
@@ -360,107 +416,333 @@ func (t dataTransform) LocalTransformAndStreamArrow(in *pb.QueryIn, stream pb.Da
 		sequencyNumber += 1
 	}
 
-	log.Println("Sent all chunks from the server")
+	slog.DebugContext(ctx, "sent all chunks")
 	return nil
 }
 
 func (t dataTransform) LocalTransformAndStreamParquet(in *pb.QueryIn, stream pb.DataTransform_LocalTransformAndStreamParquetServer) error {
+	if err := checkDestination(in.Destination); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	arrowQB, err := t.qb.GetArrow(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "error creating arrow query builder", "err", err)
+		return err
+	}
+
 	defer func() {
-		log.Println("computed transform")
+		if err := arrowQB.Close(); err != nil {
+			slog.ErrorContext(ctx, "error closing arrow query builder", "err", err)
+		}
 	}()
 
-	// mem profiler
-	{
-		p := path.Join(config.TEMP_PROF_DIR, fmt.Sprintf("local-parquet-grpc-mem-%s.prof", time.Now().UTC().Format("2006-01-02 15:04:05")))
-		f, err := os.Create(p)
-		if err != nil {
-			fmt.Printf("error creating cpu profiler, err: %v\n", err)
-			return err
+	ws := workspaceFromContext(ctx)
 
-		}
-		defer pprof.WriteHeapProfile(f)
+	filePath, err := t.sandbox.Resolve(in.Path)
+	if err != nil {
+		slog.WarnContext(ctx, "error resolving path", "err", err)
+		return err
+	}
+
+	if err := t.loadCSV(ctx, ws.table, filePath); err != nil {
+		return err
+	}
+
+	slog.DebugContext(ctx, "creating view for the transformation query")
+	query, err := t.transformQuery(ctx, ws, in)
+	if err != nil {
+		slog.WarnContext(ctx, "error compiling transformation", "err", err)
+		return err
+	}
+
+	if err := t.createView(ctx, ws, query); err != nil {
+		return err
+	}
+
+	slog.DebugContext(ctx, "querying the view")
+	exportPath := t.tempPath(".parquet")
+	t.tmp.Add(exportPath)
+	defer t.tmp.Remove(exportPath)
+	_, encode := startPhase(ctx, metrics.PhaseEncode)
+	unwatch := progressFromContext(ctx).watch(arrowQB)
+	err = arrowQB.CopyToParquet(ctx, ws.view, exportPath, querybuilder.ParquetOptions{Compression: "gzip", FooterKey: querybuilder.PARQUET_KEY_NAME})
+	unwatch()
+	encode.end(err, fileAttributes(exportPath)...)
+	if err != nil {
+		slog.ErrorContext(ctx, "error writing data to parquet", "err", err)
+		return err
+	}
+
+	if in.Destination != "" {
+		return t.upload(ctx, exportPath, in.Destination)
+	}
+
+	outFile, err := os.Open(exportPath)
+	if err != nil {
+		slog.ErrorContext(ctx, "error reading data from parquet", "err", err)
+		return err
 	}
+	defer outFile.Close()
 
-	// cpu profiler
-	{
-		p := path.Join(config.TEMP_PROF_DIR, fmt.Sprintf("local-parquet-grpc-cpu-%s.prof", time.Now().UTC().Format("2006-01-02 15:04:05")))
-		f, err := os.Create(p)
+	sequencyNumber := 1
+	buf := make([]byte, t.config().Stream.FileChunkSize)
+	slog.DebugContext(ctx, "chunking the query result")
+	for {
+		q := &pb.QueryOut{}
+		_, err := outFile.Read(buf)
+		if err == io.EOF {
+			slog.DebugContext(ctx, "reached the end of the export")
+			break
+		}
 		if err != nil {
-			fmt.Printf("error creating cpu profiler, err: %v\n", err)
+			slog.ErrorContext(ctx, "error getting data", "err", err)
 			return err
+		}
 
+		q.SequencyNumber = int32(sequencyNumber)
+		if err := stream.Send(q); err != nil {
+			slog.ErrorContext(ctx, "error streaming data", "err", err)
+			return err
 		}
-		pprof.StartCPUProfile(f)
-		defer pprof.StopCPUProfile()
+
+		sequencyNumber += 1
+	}
+
+	slog.DebugContext(ctx, "sent all chunks")
+	return nil
+}
+
+func (t dataTransform) TransformAndStreamJSON(in *pb.QueryIn, stream pb.DataTransform_TransformAndStreamJSONServer) error {
+	ws := workspaceFromContext(stream.Context())
+	if err := t.loadSource(stream.Context(), in, ws.table); err != nil {
+		return err
+	}
+
+	return t.streamJSON(ws, in, stream)
+}
+
+func (t dataTransform) LocalTransformAndStreamJSON(in *pb.QueryIn, stream pb.DataTransform_LocalTransformAndStreamJSONServer) error {
+	ctx := stream.Context()
+	ws := workspaceFromContext(ctx)
+	filePath, err := t.sandbox.Resolve(in.Path)
+	if err != nil {
+		slog.WarnContext(ctx, "error resolving path", "err", err)
+		return err
 	}
 
-	ctx := context.Background()
+	if err := t.loadCSV(ctx, ws.table, filePath); err != nil {
+		return err
+	}
+
+	return t.streamJSON(ws, in, stream)
+}
+
+// streamJSON runs the transformation query over the loaded table and streams
+// the result as JSON chunks of at most JSONOptions.chunk_bytes bytes.
+func (t dataTransform) streamJSON(ws workspace, in *pb.QueryIn, stream grpc.ServerStreamingServer[pb.QueryOut]) error {
+	ctx := stream.Context()
 	arrowQB, err := t.qb.GetArrow(ctx)
 	if err != nil {
-		log.Fatalf("Error creating arrow query builder, err: %v\n", err)
+		slog.ErrorContext(ctx, "error creating arrow query builder", "err", err)
+		return err
 	}
 
 	defer func() {
 		if err := arrowQB.Close(); err != nil {
-			log.Printf("Error closing arrow query builder, err: %v\n", err)
+			slog.ErrorContext(ctx, "error closing arrow query builder", "err", err)
 		}
 	}()
 
-	const (
-		tableName = "loadtest"
-		viewName  = "v_loadtest"
-	)
-
-	log.Println("Loading data to duckDB")
-	if err := t.qb.CSVToTable(tableName, in.Path); err != nil {
-		log.Printf("error loading data to duck-db, err: %v\n", err)
+	slog.DebugContext(ctx, "creating view for the transformation query")
+	query, err := t.transformQuery(ctx, ws, in)
+	if err != nil {
+		slog.WarnContext(ctx, "error compiling transformation", "err", err)
 		return err
 	}
 
-	log.Println("Creating view for the transformation query")
-	if err := t.qb.Exec(utilsQuery.CreateViewV2(viewName, in.Query)); err != nil {
-		log.Printf("error creating view, err: %v\n", err)
+	if err := t.createView(ctx, ws, query); err != nil {
 		return err
 	}
 
-	log.Println("Querying the view")
-	exportPath := path.Join(config.TEMP_DOWNLOAD_DIR, fmt.Sprintf("%d.parquet", time.Now().Unix()))
-	_, err = t.qb.Query(fmt.Sprintf("COPY %s TO '%s' (ENCRYPTION_CONFIG {footer_key: 'key256'}, FORMAT PARQUET, COMPRESSION 'gzip');", viewName, exportPath))
+	slog.DebugContext(ctx, "querying the view")
+	_, run := startPhase(ctx, metrics.PhaseQuery)
+	unwatch := progressFromContext(ctx).watch(arrowQB)
+	rows, err := arrowQB.Query(ctx, querybuilder.SelectAll(ws.view))
+	unwatch()
+	run.end(err)
 	if err != nil {
-		log.Printf("Error writing data to parquet, err: %s\n", err.Error())
+		slog.ErrorContext(ctx, "error querying data", "err", err)
 		return err
 	}
+	defer rows.Release()
 
-	outFile, err := os.Open(exportPath)
-	if err != nil {
-		log.Printf("Error reading data from parquet, err: %s\n", err.Error())
-		return err
+	chunkBytes := int(in.GetJsonOptions().GetChunkBytes())
+	if chunkBytes <= 0 {
+		chunkBytes = t.config().Stream.FileChunkSize
 	}
 
+	chunker := utilsQuery.NewJSONChunker(rows, in.GetJsonOptions())
+	defer chunker.Release()
+
 	sequencyNumber := 1
-	buf := make([]byte, config.FILE_CHUNK_SIZE)
-	log.Println("Chunking the query result")
+	slog.DebugContext(ctx, "chunking the query result")
 	for {
-		q := &pb.QueryOut{}
-		_, err := outFile.Read(buf)
+		_, encode := startPhase(ctx, metrics.PhaseEncode)
+		q, err := chunker.Next(chunkBytes)
 		if err == io.EOF {
-			log.Printf("reached EOF thus breaking\n")
+			encode.end(nil)
 			break
 		}
+		encode.end(err, chunkAttributes(q)...)
 		if err != nil {
-			log.Printf("error getting data, err: %v\n", err)
+			slog.ErrorContext(ctx, "error getting data", "err", err)
 			return err
 		}
 
 		q.SequencyNumber = int32(sequencyNumber)
 		if err := stream.Send(q); err != nil {
-			log.Printf("error streaming data, err: %v\n", err)
+			slog.ErrorContext(ctx, "error streaming data", "err", err)
 			return err
 		}
 
 		sequencyNumber += 1
 	}
 
-	log.Println("Sent all chunks from the server")
+	slog.DebugContext(ctx, "sent all chunks")
+	return nil
+}
+
+func (t dataTransform) CompilePipeline(ctx context.Context, in *pb.QueryIn) (*pb.CompiledQuery, error) {
+	if in.Pipeline == nil && in.Subtotal == nil {
+		return nil, status.Error(codes.InvalidArgument, "pipeline or subtotal is required")
+	}
+
+	ws := workspaceFromContext(ctx)
+	if err := t.loadSource(ctx, in, ws.table); err != nil {
+		return nil, err
+	}
+
+	query, err := t.transformQuery(ctx, ws, in)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CompiledQuery{Sql: query}, nil
+}
+
+// transformQuery returns the query of the transformation, compiling the
+// pipeline or subtotal against the loaded table when one is given. Queries
+// given as SQL are validated first. The query reads the table as
+// sourceRelation.
+func (t dataTransform) transformQuery(ctx context.Context, ws workspace, in *pb.QueryIn) (query string, err error) {
+	_, transform := startPhase(ctx, metrics.PhaseTransform)
+	defer func() {
+		transform.end(err, tracing.QueryHash.String(tracing.HashQuery(query)))
+		recordFromContext(ctx).setQuery(query, "")
+	}()
+
+	spec := in.Pipeline
+	if in.Subtotal != nil {
+		spec = &pb.Pipeline{Steps: []*pb.Step{{Kind: &pb.Step_Subtotal{Subtotal: in.Subtotal}}}}
+	}
+
+	if spec == nil {
+		if in.Query == "" {
+			return "", nil
+		}
+		if err := t.sql.Validate(in.Query); err != nil {
+			slog.WarnContext(ctx, "rejected query", logging.SQL(in.Query), "err", err)
+			return "", err
+		}
+		return sqlcheck.TrimTerminator(in.Query), nil
+	}
+
+	pipeline, err := utilsQuery.PipelineFromProto(spec)
+	if err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "invalid pipeline: %v", err)
+	}
+
+	schema, err := t.qb.Describe(ws.table)
+	if err != nil {
+		return "", err
+	}
+
+	query, err = pipeline.Compile(sourceRelation, schema)
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	slog.DebugContext(ctx, "compiled pipeline", logging.SQL(query))
+	return query, nil
+}
+
+// createView creates the view of the workspace over the result of the
+// transformation query.
+func (t dataTransform) createView(ctx context.Context, ws workspace, query string) error {
+	setQueryHash(ctx, query)
+	recordFromContext(ctx).setQuery(query, ws.view)
+	_, view := startPhase(ctx, metrics.PhaseView, tracing.QueryHash.String(tracing.HashQuery(query)))
+	err := t.qb.CreateView(ws.view, ws.bind(query))
+	view.end(err)
+	if err != nil {
+		slog.ErrorContext(ctx, "error creating view", "err", err)
+	}
+	return err
+}
+
+// checkDestination checks the destination of an export before any work is
+// done.
+func checkDestination(destination string) error {
+	if destination == "" {
+		return nil
+	}
+	if _, _, err := objectstore.ParseURI(destination); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
 	return nil
 }
+
+// upload writes the export to object storage.
+func (t dataTransform) upload(ctx context.Context, filePath, destination string) error {
+	slog.InfoContext(ctx, "uploading the export", "destination", destination)
+	err := t.store.Upload(ctx, filePath, destination, "application/vnd.apache.parquet")
+	if err == nil {
+		return nil
+	}
+
+	slog.ErrorContext(ctx, "error uploading file", "err", err)
+	switch {
+	case errors.Is(err, objectstore.ErrForbidden):
+		return status.Errorf(codes.PermissionDenied, "uploading to %s: %v", destination, err)
+	case errors.Is(err, objectstore.ErrNotFound):
+		return status.Errorf(codes.NotFound, "uploading to %s: %v", destination, err)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		return status.Errorf(codes.Unavailable, "uploading to %s: %v", destination, err)
+	}
+}
+
+// sourceSize returns the size of the source, defaultSourceSize when unknown.
+// The size of remote sources comes from the cache, see sizeCache.
+func (t dataTransform) sourceSize(ctx context.Context, p string) int64 {
+	if isRemote(p) {
+		return t.sizes.get(p)
+	}
+
+	info, err := os.Stat(p)
+	if err != nil || info.Size() <= 0 {
+		return defaultSourceSize
+	}
+	return info.Size()
+}
+
+// remoteSize asks the server of a remote source for its size.
+func (t dataTransform) remoteSize(ctx context.Context, p string) (int64, error) {
+	if objectstore.IsURI(p) {
+		return t.store.Size(ctx, p)
+	}
+	return t.fetcher.Head(ctx, p)
+}
@@ -0,0 +1,71 @@
+package grpc_arrow
+
+import (
+	"context"
+	"duckdb-server/config"
+	"duckdb-server/internal/admission"
+	"duckdb-server/internal/health"
+	pb "duckdb-server/internal/services/grpc_arrow/data_transform"
+	"fmt"
+	"log/slog"
+	"time"
+
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// publicMethods are served without authentication, for the orchestrators.
+var publicMethods = map[string]bool{
+	healthpb.Health_Check_FullMethodName: true,
+	healthpb.Health_Watch_FullMethodName: true,
+}
+
+// quietMethods are called often by the orchestrators, their requests are only
+// logged at the debug level unless they fail.
+var quietMethods = publicMethods
+
+// newChecker returns the readiness checks: DuckDB answers, the directories
+// the server writes to have room left and the admission queue isn't full.
+func newChecker(service *dataTransform, ac *admission.Controller, cfg *config.Config) *health.Checker {
+	minFree := cfg.Health.MinFreeDisk
+	return health.NewChecker(cfg.Health.CheckTimeout,
+		health.Check{Name: "duckdb", Run: service.qb.Ping},
+		health.DiskSpace("temp_download_dir", cfg.Dirs.TempDownload, minFree),
+		health.DiskSpace("duckdb_dir", cfg.Dirs.DuckDB, minFree),
+		health.Check{Name: "admission_queue", Run: func(ctx context.Context) error {
+			stats := ac.Stats()
+			if stats.Queued >= stats.Capacity.MaxQueued {
+				return fmt.Errorf("the admission queue is full, %d requests waiting", stats.Queued)
+			}
+			return nil
+		}},
+	)
+}
+
+// watchHealth runs the readiness checks every interval until ctx is done,
+// reflecting them in the status of the gRPC health service. The server as a
+// whole and the DataTransform service are serving when ready, the Admin
+// service as long as the server runs.
+func watchHealth(ctx context.Context, checker *health.Checker, hs *grpchealth.Server, interval time.Duration) {
+	ready := true
+	update := func(r health.Report) {
+		status := healthpb.HealthCheckResponse_SERVING
+		if !r.Ready {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		hs.SetServingStatus("", status)
+		hs.SetServingStatus(pb.DataTransform_ServiceDesc.ServiceName, status)
+
+		switch {
+		case ready && !r.Ready:
+			slog.Warn("server not ready", "failed", r.Failed())
+		case !ready && r.Ready:
+			slog.Info("server ready")
+		}
+		ready = r.Ready
+	}
+
+	hs.SetServingStatus(pb.Admin_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	update(checker.Run(ctx))
+	checker.Watch(ctx, interval, update)
+}
@@ -0,0 +1,249 @@
+package grpc_arrow
+
+import (
+	"context"
+	"duckdb-server/internal/logging"
+	"duckdb-server/internal/metrics"
+	querybuilder "duckdb-server/internal/query_builder"
+	"duckdb-server/internal/requestid"
+	pb "duckdb-server/internal/services/grpc_arrow/data_transform"
+	"duckdb-server/internal/sqlcheck"
+	"duckdb-server/internal/tracing"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// memorySampleInterval is how often the memory of DuckDB is sampled while a
+// request runs, to find its peak.
+const memorySampleInterval = 250 * time.Millisecond
+
+// history records the transformations in the history table of the database
+// and logs the slow ones with their plan.
+type history struct {
+	qb *querybuilder.DuckDBQueryBuilder
+	// maxRows is the number of entries kept, all of them when 0
+	maxRows int
+	// slowThreshold is the duration from which a query is logged as slow,
+	// none is when 0
+	slowThreshold time.Duration
+}
+
+// queryRecord collects the history entry of a request as it runs.
+type queryRecord struct {
+	mu    sync.Mutex
+	entry querybuilder.HistoryEntry
+	// view is set once the query ran against the loaded table, as the view
+	// of the request, the plan of slow queries can then be explained
+	view string
+}
+
+type recordKey struct{}
+
+// recordFromContext returns the record of the request, nil when it is not
+// recorded. The methods of queryRecord accept a nil receiver.
+func recordFromContext(ctx context.Context) *queryRecord {
+	r, _ := ctx.Value(recordKey{}).(*queryRecord)
+	return r
+}
+
+func (r *queryRecord) addPhase(name string, d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entry.PhaseMillis[name] += millis(d)
+}
+
+// setQuery records the query of the transformation and the view it runs as,
+// empty while it didn't run against the loaded table.
+func (r *queryRecord) setQuery(query, view string) {
+	if r == nil || query == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entry.SQL = query
+	r.entry.QueryHash = tracing.HashQuery(sqlcheck.Normalize(query))
+	if view != "" {
+		r.view = view
+	}
+}
+
+func (r *queryRecord) setRequest(in *pb.QueryIn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entry.Source = logging.RedactURL(in.Path)
+}
+
+func (r *queryRecord) addSent(out *pb.QueryOut, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entry.RowsOut += int64(out.Count)
+	r.entry.BytesOut += chunkSize(out)
+	r.entry.PhaseMillis[metrics.PhaseSend] += millis(d)
+}
+
+func (r *queryRecord) observeMemory(bytes int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entry.PeakMemoryBytes = max(r.entry.PeakMemoryBytes, bytes)
+}
+
+func isTransform(method string) bool {
+	return strings.HasPrefix(method, "/"+pb.DataTransform_ServiceDesc.ServiceName+"/")
+}
+
+func (h *history) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if !isTransform(info.FullMethod) {
+		return handler(ctx, req)
+	}
+
+	r := h.start(ctx, info.FullMethod)
+	if in, ok := queryIn(req); ok {
+		r.setRequest(in)
+	}
+
+	ctx = context.WithValue(ctx, recordKey{}, r)
+	stop := h.sampleMemory(r)
+	res, err := handler(ctx, req)
+	stop()
+
+	h.finish(ctx, r, err)
+	return res, err
+}
+
+func (h *history) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !isTransform(info.FullMethod) {
+		return handler(srv, ss)
+	}
+
+	r := h.start(ss.Context(), info.FullMethod)
+	ctx := context.WithValue(ss.Context(), recordKey{}, r)
+	stop := h.sampleMemory(r)
+	err := handler(srv, &recordedStream{ServerStream: ss, ctx: ctx, record: r})
+	stop()
+
+	h.finish(ctx, r, err)
+	return err
+}
+
+// recordedStream records the request and the chunks sent.
+type recordedStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	record *queryRecord
+}
+
+func (s *recordedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *recordedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if in, ok := queryIn(m); ok {
+		s.record.setRequest(in)
+	}
+	return nil
+}
+
+func (s *recordedStream) SendMsg(m any) error {
+	start := time.Now()
+	err := s.ServerStream.SendMsg(m)
+	if out, ok := m.(*pb.QueryOut); ok && err == nil {
+		s.record.addSent(out, time.Since(start))
+	}
+	return err
+}
+
+func (h *history) start(ctx context.Context, method string) *queryRecord {
+	return &queryRecord{entry: querybuilder.HistoryEntry{
+		StartedAt:   time.Now(),
+		RequestID:   requestid.FromContext(ctx),
+		Method:      method,
+		Caller:      caller(ctx),
+		PhaseMillis: map[string]float64{},
+	}}
+}
+
+// sampleMemory samples the memory of DuckDB until the returned function is
+// called.
+func (h *history) sampleMemory(r *queryRecord) func() {
+	sample := func() {
+		if stats, err := h.qb.Stats(); err == nil {
+			r.observeMemory(stats.MemoryBytes)
+		}
+	}
+
+	sample()
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(memorySampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				sample()
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+		sample()
+	}
+}
+
+// finish records the entry once the request is done. Slow queries are
+// explained first, while the request still holds its admission slot and its
+// workspace is still there.
+func (h *history) finish(ctx context.Context, r *queryRecord, err error) {
+	// the client may be gone, the entry is recorded anyway
+	ctx = context.WithoutCancel(ctx)
+
+	elapsed := time.Since(r.entry.StartedAt)
+	r.mu.Lock()
+	r.entry.DurationMillis = millis(elapsed)
+	r.entry.Status = status.Code(err).String()
+	if err != nil {
+		r.entry.Error = err.Error()
+	}
+	entry, view := r.entry, r.view
+	r.mu.Unlock()
+
+	if h.slowThreshold > 0 && elapsed >= h.slowThreshold && entry.SQL != "" {
+		if view != "" && err == nil {
+			// the query runs again, give it as long as the first time
+			explainCtx, cancel := context.WithTimeout(ctx, elapsed)
+			plan, err := h.qb.Explain(explainCtx, querybuilder.SelectAll(view), true)
+			cancel()
+			if err != nil {
+				slog.ErrorContext(ctx, "error explaining slow query", "err", err)
+			}
+			entry.Plan = plan
+		}
+
+		slog.WarnContext(ctx, "slow query", "duration_ms", entry.DurationMillis, "query_hash", entry.QueryHash,
+			logging.SQL(entry.SQL), "plan", entry.Plan)
+	}
+
+	if err := h.qb.RecordHistory(ctx, entry, h.maxRows); err != nil {
+		slog.ErrorContext(ctx, "error recording query history", "err", err)
+	}
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,108 @@
+package grpc_arrow
+
+import (
+	"context"
+	"duckdb-server/internal/admission"
+	"duckdb-server/internal/metrics"
+	querybuilder "duckdb-server/internal/query_builder"
+	pb "duckdb-server/internal/services/grpc_arrow/data_transform"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricsUnaryInterceptor counts the RPCs and times them. It comes first, only
+// preceded by the progress interceptor for streams, so that the requests
+// rejected by the other interceptors are counted too.
+func metricsUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	res, err := handler(ctx, req)
+	observeRPC(info.FullMethod, start, err)
+	return res, err
+}
+
+func metricsStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, &measuredStream{ServerStream: ss, method: info.FullMethod})
+	observeRPC(info.FullMethod, start, err)
+	return err
+}
+
+func observeRPC(method string, start time.Time, err error) {
+	metrics.RPCDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	metrics.RPCs.WithLabelValues(method, status.Code(err).String()).Inc()
+}
+
+// measuredStream counts the chunks sent and times their sending.
+type measuredStream struct {
+	grpc.ServerStream
+	method string
+}
+
+func (s *measuredStream) SendMsg(m any) error {
+	start := time.Now()
+	err := s.ServerStream.SendMsg(m)
+	metrics.PhaseSeconds.WithLabelValues(s.method, metrics.PhaseSend).Add(time.Since(start).Seconds())
+
+	if out, ok := m.(*pb.QueryOut); ok && err == nil {
+		metrics.ChunksSent.WithLabelValues(s.method).Inc()
+		metrics.RowsSent.WithLabelValues(s.method).Add(float64(out.Count))
+		metrics.BytesSent.WithLabelValues(s.method).Add(float64(chunkSize(out)))
+	}
+	return err
+}
+
+// registerGauges exposes the state of the admission queue, the database and
+// the temporary directories, downloads going to downloadDir.
+func registerGauges(ac *admission.Controller, service *dataTransform, downloadDir string) {
+	metrics.GaugeFunc("admission_queued_requests", "Requests waiting for admission.", nil, func() float64 {
+		return float64(ac.Stats().Queued)
+	})
+	metrics.GaugeFunc("admission_running_requests", "Requests admitted and running.", nil, func() float64 {
+		return float64(ac.Stats().Running)
+	})
+	metrics.GaugeFunc("admission_memory_in_use_bytes", "Estimated memory of the running requests.", nil, func() float64 {
+		return float64(ac.Stats().InUse)
+	})
+
+	metrics.GaugeFunc("duckdb_connections", "Open DuckDB connections.", nil, func() float64 {
+		stats, _ := service.qb.Stats()
+		return float64(stats.Connections)
+	})
+	metrics.GaugeFunc("duckdb_memory_bytes", "Memory used by DuckDB.", nil, func() float64 {
+		stats, err := service.qb.Stats()
+		if err != nil {
+			slog.Error("error getting duckdb memory usage", "err", err)
+		}
+		return float64(stats.MemoryBytes)
+	})
+
+	for name, dir := range map[string]string{"download": downloadDir, "spill": querybuilder.DEFAULT_TEMP_DIRECTORY} {
+		metrics.GaugeFunc("temp_dir_bytes", "Size of the files in the temporary directories.", prometheus.Labels{"dir": name}, func() float64 {
+			return float64(dirSize(dir))
+		})
+	}
+}
+
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// files come and go during the walk
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,31 @@
+package grpc_arrow
+
+import (
+	"context"
+	"duckdb-server/internal/profiling"
+	"duckdb-server/internal/requestid"
+
+	grpc "google.golang.org/grpc"
+)
+
+// profilingUnaryInterceptor labels the CPU samples with the request ID and
+// method, and profiles the requests asked for by CaptureProfile. It comes
+// last, so that only the handler is profiled.
+func profilingUnaryInterceptor(p *profiling.Profiler) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res any, err error) {
+		p.Do(ctx, requestid.FromContext(ctx), info.FullMethod, func(ctx context.Context) {
+			res, err = handler(ctx, req)
+		})
+		return res, err
+	}
+}
+
+func profilingStreamInterceptor(p *profiling.Profiler) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		ctx := ss.Context()
+		p.Do(ctx, requestid.FromContext(ctx), info.FullMethod, func(ctx context.Context) {
+			err = handler(srv, ss)
+		})
+		return err
+	}
+}
@@ -0,0 +1,237 @@
+package grpc_arrow
+
+import (
+	"context"
+	"duckdb-server/internal/metrics"
+	"duckdb-server/internal/progress"
+	querybuilder "duckdb-server/internal/query_builder"
+	pb "duckdb-server/internal/services/grpc_arrow/data_transform"
+	"log/slog"
+	"sync"
+	"time"
+
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// minProgressInterval bounds how often progress messages are sent.
+const minProgressInterval = 100 * time.Millisecond
+
+// progressStreamInterceptor interleaves progress messages with the chunks of
+// the requests asking for them with QueryIn.progress_interval_ms. It comes
+// first, so that the other interceptors don't take the progress messages for
+// chunks. Nothing is sent before the handler starts its first phase, that is
+// before the request is authorized and admitted.
+//
+// The progress of every request is tracked, as the other interceptors take
+// the context of the stream before the request is received.
+func progressStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	tracker := newProgressTracker()
+	ctx := progress.NewContext(context.WithValue(ss.Context(), progressKey{}, tracker), tracker.transfer)
+	stream := &progressStream{ServerStream: ss, ctx: ctx, tracker: tracker, stop: make(chan struct{})}
+	err := handler(srv, stream)
+	stream.close()
+	return err
+}
+
+// progressStream sends the progress messages from its own goroutine, started
+// once the request is received. Sends are serialized, as a stream doesn't
+// support concurrent ones.
+type progressStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	tracker *progressTracker
+
+	sendMu sync.Mutex
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func (s *progressStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *progressStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	in, ok := m.(*pb.QueryIn)
+	if !ok || in.ProgressIntervalMs == 0 || s.done != nil {
+		return nil
+	}
+	if in.ProgressIntervalMs < 0 {
+		return status.Error(codes.InvalidArgument, "progress_interval_ms can't be negative")
+	}
+
+	s.done = make(chan struct{})
+	go s.report(max(time.Duration(in.ProgressIntervalMs)*time.Millisecond, minProgressInterval))
+	return nil
+}
+
+func (s *progressStream) SendMsg(m any) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	err := s.ServerStream.SendMsg(m)
+	if out, ok := m.(*pb.QueryOut); ok && err == nil {
+		s.tracker.sent(out)
+	}
+	return err
+}
+
+func (s *progressStream) report(interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+
+		p := s.tracker.snapshot()
+		if p == nil {
+			continue
+		}
+
+		s.sendMu.Lock()
+		err := s.ServerStream.SendMsg(&pb.QueryOut{Progress: p})
+		s.sendMu.Unlock()
+		if err != nil {
+			// the handler sees the broken stream on its next send
+			slog.DebugContext(s.ctx, "error sending progress", "err", err)
+			return
+		}
+	}
+}
+
+// close stops the progress messages, the handler having returned.
+func (s *progressStream) close() {
+	close(s.stop)
+	if s.done != nil {
+		<-s.done
+	}
+}
+
+type progressKey struct{}
+
+// progressFromContext returns the progress tracker of the request, nil for
+// unary RPCs. The methods of progressTracker accept a nil receiver.
+func progressFromContext(ctx context.Context) *progressTracker {
+	t, _ := ctx.Value(progressKey{}).(*progressTracker)
+	return t
+}
+
+// progressTracker follows the progress of a request.
+type progressTracker struct {
+	start time.Time
+
+	mu            sync.Mutex
+	phase         string
+	downloadStart time.Time
+	downloaded    int64
+	downloadTotal int64
+	// query is the connection running the current query, nil when none runs
+	query      *querybuilder.DuckDBArrowQueryBuilder
+	queryStart time.Time
+	rows       int64
+	chunks     int64
+}
+
+func newProgressTracker() *progressTracker {
+	return &progressTracker{start: time.Now()}
+}
+
+func (t *progressTracker) setPhase(name string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phase = name
+}
+
+// transfer is the progress.Func of the downloads.
+func (t *progressTracker) transfer(done, total int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if done == 0 {
+		t.downloadStart = time.Now()
+	}
+	t.downloaded, t.downloadTotal = done, total
+}
+
+// watch follows the progress of the query about to run on the connection,
+// until the returned function is called.
+func (t *progressTracker) watch(qb *querybuilder.DuckDBArrowQueryBuilder) func() {
+	if t == nil {
+		return func() {}
+	}
+
+	t.mu.Lock()
+	t.query, t.queryStart = qb, time.Now()
+	t.mu.Unlock()
+
+	return func() {
+		t.mu.Lock()
+		t.query = nil
+		t.mu.Unlock()
+	}
+}
+
+func (t *progressTracker) sent(out *pb.QueryOut) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rows += int64(out.Count)
+	t.chunks++
+}
+
+// snapshot returns the progress of the request, nil until its first phase.
+// The time left is estimated from the progress of the query, or of the
+// download, so far.
+func (t *progressTracker) snapshot() *pb.Progress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.phase == "" {
+		return nil
+	}
+
+	p := &pb.Progress{
+		Phase:              t.phase,
+		DownloadBytes:      t.downloaded,
+		DownloadTotalBytes: t.downloadTotal,
+		QueryPercent:       -1,
+		RowsEmitted:        t.rows,
+		ChunksEmitted:      t.chunks,
+		ElapsedMs:          time.Since(t.start).Milliseconds(),
+		EtaMs:              -1,
+	}
+
+	switch {
+	case t.query != nil:
+		qp, err := t.query.Progress()
+		if err != nil || qp.Percentage < 0 {
+			break
+		}
+		p.QueryPercent = qp.Percentage
+		if qp.Percentage > 0 {
+			p.EtaMs = eta(time.Since(t.queryStart), qp.Percentage/100)
+		}
+	case t.phase == metrics.PhaseDownload && t.downloadTotal > 0 && t.downloaded > 0:
+		p.EtaMs = eta(time.Since(t.downloadStart), float64(t.downloaded)/float64(t.downloadTotal))
+	}
+	return p
+}
+
+// eta estimates the time left from the time elapsed and the share done, in
+// milliseconds.
+func eta(elapsed time.Duration, done float64) int64 {
+	return time.Duration(float64(elapsed) * (1 - done) / done).Milliseconds()
+}
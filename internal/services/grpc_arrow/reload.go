@@ -0,0 +1,93 @@
+package grpc_arrow
+
+import (
+	"context"
+	"duckdb-server/config"
+	"duckdb-server/internal/admission"
+	"duckdb-server/internal/logging"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+var (
+	// errInvalidConfig is returned by reloads when the configuration doesn't
+	// load, errRestartRequired when it changes settings that can't be
+	// reloaded. The current configuration is kept in both cases.
+	errInvalidConfig   = errors.New("invalid configuration")
+	errRestartRequired = errors.New("settings changed that require a restart")
+)
+
+// reloader swaps in the configuration changes that don't need a restart. The
+// requests already running keep the configuration they started with.
+type reloader struct {
+	// load reads the configuration again, from the sources it was read from
+	// at startup
+	load    func() (*config.Config, error)
+	service *dataTransform
+	ac      *admission.Controller
+
+	mu sync.Mutex
+}
+
+// reload reads the configuration and applies its changes, which it returns.
+// Either all the changes are applied or none.
+func (r *reloader) reload(ctx context.Context) ([]config.Change, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := r.load()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidConfig, err)
+	}
+
+	current := r.service.config()
+	changes := next.Diff(current)
+
+	var restart []string
+	var resources bool
+	for _, c := range changes {
+		isResource := strings.HasPrefix(c.Path, "duckdb.")
+		// the database refuses the changes once its configuration is locked
+		if !c.Reloadable || isResource && current.Sandbox.LockConfiguration {
+			restart = append(restart, fmt.Sprintf("%s (%s)", c.Path, c.Env))
+		}
+		resources = resources || isResource
+	}
+	if len(restart) > 0 {
+		return nil, fmt.Errorf("%w: %s", errRestartRequired, strings.Join(restart, ", "))
+	}
+	if len(changes) == 0 {
+		slog.InfoContext(ctx, "configuration reloaded, nothing changed")
+		return nil, nil
+	}
+
+	// the only step that can fail comes first, and undoes itself when it does
+	if resources {
+		if err := r.service.qb.SetResources(next.DuckDB.MemoryLimit, next.DuckDB.Threads, current.DuckDB.MemoryLimit); err != nil {
+			return nil, fmt.Errorf("failed to change the database resources: %w", err)
+		}
+	}
+	level, _ := logging.ParseLevel(next.Log.Level) // validated by load
+	logging.SetLevel(level)
+	r.ac.SetConfig(admissionConfig(next))
+	r.service.cfg.Store(next)
+
+	for _, c := range changes {
+		slog.InfoContext(ctx, "setting changed", "setting", c.Path, "old", c.Old, "new", c.New)
+	}
+	slog.InfoContext(ctx, "configuration reloaded", "changes", len(changes))
+	return changes, nil
+}
+
+// admissionConfig returns the limits of the admission controller.
+func admissionConfig(cfg *config.Config) admission.Config {
+	return admission.Config{
+		MaxConcurrent: cfg.Admission.MaxConcurrentQueries,
+		MemoryBudget:  cfg.Admission.MemoryBudget,
+		MaxQueued:     cfg.Admission.QueueSize,
+		QueueTimeout:  cfg.Admission.QueueTimeout,
+	}
+}
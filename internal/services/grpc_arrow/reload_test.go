@@ -0,0 +1,226 @@
+package grpc_arrow
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"duckdb-server/config"
+	"duckdb-server/internal/admission"
+	"duckdb-server/internal/logging"
+	querybuilder "duckdb-server/internal/query_builder"
+)
+
+// testReloader is a reloader of a service on a database of its own, loading
+// next.
+type testReloader struct {
+	*reloader
+	current *config.Config
+	next    *config.Config
+}
+
+func newTestReloader(t *testing.T, lock bool) *testReloader {
+	t.Helper()
+
+	current := config.Default()
+	current.Server.Port = 9006
+	current.Dirs.DuckDB = t.TempDir()
+	current.Stream.ChunkSize = 1000
+	current.Stream.FileChunkSize = 65536
+	current.DuckDB.MemoryLimit = "512MB"
+	current.Sandbox.LockConfiguration = lock
+
+	qb, err := querybuilder.NewDuckDBQueryBuilder(filepath.Join(current.Dirs.DuckDB, "db.duckdb"), querybuilder.Options{
+		LockConfiguration: lock,
+		MemoryLimit:       current.DuckDB.MemoryLimit,
+		Threads:           current.DuckDB.Threads,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { qb.Close() })
+
+	logging.SetLevel(slog.LevelInfo)
+	t.Cleanup(func() { logging.SetLevel(slog.LevelInfo) })
+
+	service := &dataTransform{cfg: &atomic.Pointer[config.Config]{}, qb: qb}
+	service.cfg.Store(current)
+	next := *current
+	tr := &testReloader{current: current, next: &next}
+	tr.reloader = &reloader{
+		load:    func() (*config.Config, error) { next := *tr.next; return &next, nil },
+		service: service,
+		ac:      admission.NewController(admissionConfig(current)),
+	}
+	return tr
+}
+
+// setting returns the current value of the database setting.
+func (tr *testReloader) setting(t *testing.T, name string) string {
+	t.Helper()
+
+	rows, err := tr.service.qb.Query("SELECT current_setting(" + querybuilder.QuoteLiteral(name) + ")")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var value string
+	if !rows.Next() {
+		t.Fatalf("no value for %s: %v", name, rows.Err())
+	}
+	if err := rows.Scan(&value); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+// unchanged fails unless the configuration, the admission limits, the log
+// level and the database resources are those of the start.
+func (tr *testReloader) unchanged(t *testing.T, memoryLimit string) {
+	t.Helper()
+
+	if tr.service.config() != tr.current {
+		t.Error("configuration swapped")
+	}
+	if got := tr.ac.Stats().Capacity; got != admissionConfig(tr.current) {
+		t.Errorf("admission limits %+v, want %+v", got, admissionConfig(tr.current))
+	}
+	if debugLogging() {
+		t.Error("log level changed")
+	}
+	if got := tr.setting(t, "threads"); got != "4" {
+		t.Errorf("threads = %s, want 4", got)
+	}
+	if got := tr.setting(t, "memory_limit"); got != memoryLimit {
+		t.Errorf("memory_limit = %s, want %s", got, memoryLimit)
+	}
+}
+
+// debugLogging reports whether the debug level is enabled, queries then
+// being logged in full.
+func debugLogging() bool {
+	return logging.SQL("SELECT 1").Value.Kind() == slog.KindString
+}
+
+func TestReload(t *testing.T) {
+	tr := newTestReloader(t, false)
+	memoryLimit := tr.setting(t, "memory_limit")
+
+	if changes, err := tr.reload(context.Background()); err != nil || changes != nil {
+		t.Errorf("reload without change = %+v, %v", changes, err)
+	}
+
+	tr.next.Log.Level = "debug"
+	tr.next.Admission.MaxConcurrentQueries = 3
+	tr.next.Admission.QueueTimeout = time.Second
+	tr.next.Stream.ChunkSize = 10
+	tr.next.DuckDB.MemoryLimit = "1GB"
+	tr.next.DuckDB.Threads = 2
+	changes, err := tr.reload(context.Background())
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if len(changes) != 6 {
+		t.Errorf("changes %+v, want 6", changes)
+	}
+
+	if got := tr.service.config(); got.Stream.ChunkSize != 10 || got.DuckDB.Threads != 2 || got.Log.Level != "debug" {
+		t.Errorf("configuration %+v not swapped", got)
+	}
+	if got := tr.ac.Stats().Capacity; got.MaxConcurrent != 3 || got.QueueTimeout != time.Second {
+		t.Errorf("admission limits %+v", got)
+	}
+	if !debugLogging() {
+		t.Error("log level not changed")
+	}
+	if got := tr.setting(t, "threads"); got != "2" {
+		t.Errorf("threads = %s, want 2", got)
+	}
+	if got := tr.setting(t, "memory_limit"); got == memoryLimit {
+		t.Errorf("memory_limit = %s, unchanged", got)
+	}
+}
+
+func TestReloadRestartRequired(t *testing.T) {
+	restart := map[string]func(c *config.Config){
+		"server.listen (LISTEN)":        func(c *config.Config) { c.Server.Listen = []string{"unix:/run/duckdb.sock"} },
+		"tls.cert_file (TLS_CERT_FILE)": func(c *config.Config) { c.TLS.CertFile, c.TLS.KeyFile = "/tls/cert.pem", "/tls/key.pem" },
+		"dirs.duckdb (DUCKDB_DIR)":      func(c *config.Config) { c.Dirs.DuckDB = "/other" },
+	}
+	for want, change := range restart {
+		t.Run(want, func(t *testing.T) {
+			tr := newTestReloader(t, false)
+			memoryLimit := tr.setting(t, "memory_limit")
+
+			// along with settings that alone would be reloaded
+			change(tr.next)
+			tr.next.Log.Level = "debug"
+			tr.next.Admission.MaxConcurrentQueries = 3
+			tr.next.DuckDB.Threads = 2
+			tr.next.DuckDB.MemoryLimit = "1GB"
+			changes, err := tr.reload(context.Background())
+			if !errors.Is(err, errRestartRequired) || !strings.Contains(err.Error(), want) {
+				t.Fatalf("reload = %+v, %v, want %s to require a restart", changes, err, want)
+			}
+			if strings.Contains(err.Error(), "log.level") || strings.Contains(err.Error(), "duckdb.threads") {
+				t.Errorf("error %q lists reloadable settings", err)
+			}
+			tr.unchanged(t, memoryLimit)
+		})
+	}
+}
+
+func TestReloadResourcesFailure(t *testing.T) {
+	tr := newTestReloader(t, false)
+	memoryLimit := tr.setting(t, "memory_limit")
+
+	// the threads can't be fewer than the external threads, the memory
+	// limit set before them must be undone
+	if err := tr.service.qb.Exec("SET external_threads=2"); err != nil {
+		t.Fatal(err)
+	}
+	tr.next.Log.Level = "debug"
+	tr.next.Admission.MaxConcurrentQueries = 3
+	tr.next.DuckDB.MemoryLimit = "1GB"
+	tr.next.DuckDB.Threads = 1
+	if changes, err := tr.reload(context.Background()); err == nil {
+		t.Fatalf("reload = %+v with fewer threads than external threads", changes)
+	}
+	tr.unchanged(t, memoryLimit)
+}
+
+func TestReloadLockedConfiguration(t *testing.T) {
+	tr := newTestReloader(t, true)
+	memoryLimit := tr.setting(t, "memory_limit")
+
+	tr.next.Log.Level = "debug"
+	tr.next.DuckDB.MemoryLimit = "1GB"
+	changes, err := tr.reload(context.Background())
+	if !errors.Is(err, errRestartRequired) || !strings.Contains(err.Error(), "duckdb.memory_limit (DUCKDB_MEMORY_LIMIT)") {
+		t.Fatalf("reload = %+v, %v, want the memory limit to require a restart", changes, err)
+	}
+	tr.unchanged(t, memoryLimit)
+
+	// the other settings are still reloaded
+	tr.next.DuckDB.MemoryLimit = tr.current.DuckDB.MemoryLimit
+	if changes, err := tr.reload(context.Background()); err != nil || len(changes) != 1 || !debugLogging() {
+		t.Errorf("reload of the log level = %+v, %v", changes, err)
+	}
+}
+
+func TestReloadInvalidConfiguration(t *testing.T) {
+	tr := newTestReloader(t, false)
+	tr.load = func() (*config.Config, error) { return nil, errors.New("log.level must be debug, info, warn or error") }
+
+	if _, err := tr.reload(context.Background()); !errors.Is(err, errInvalidConfig) {
+		t.Errorf("reload = %v, want errInvalidConfig", err)
+	}
+	if tr.service.config() != tr.current {
+		t.Error("configuration swapped")
+	}
+}
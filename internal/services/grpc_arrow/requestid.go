@@ -0,0 +1,67 @@
+package grpc_arrow
+
+import (
+	"context"
+	"duckdb-server/internal/requestid"
+	"log/slog"
+	"time"
+
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDUnaryInterceptor gives every request an ID, the one sent by the
+// client when valid, and returns it in the response headers. The end of the
+// request is logged with its outcome.
+func requestIDUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	id := incomingRequestID(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs(requestid.Header, id))
+
+	ctx = requestid.NewContext(ctx, id)
+	start := time.Now()
+	res, err := handler(ctx, req)
+	logOutcome(ctx, info.FullMethod, start, err)
+	return res, err
+}
+
+func requestIDStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	id := incomingRequestID(ss.Context())
+	ss.SetHeader(metadata.Pairs(requestid.Header, id))
+
+	ctx := requestid.NewContext(ss.Context(), id)
+	start := time.Now()
+	err := handler(srv, &identifiedStream{ServerStream: ss, ctx: ctx})
+	logOutcome(ctx, info.FullMethod, start, err)
+	return err
+}
+
+type identifiedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identifiedStream) Context() context.Context {
+	return s.ctx
+}
+
+func incomingRequestID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ids := md.Get(requestid.Header); len(ids) > 0 && requestid.Valid(ids[0]) {
+		return ids[0]
+	}
+	return requestid.New()
+}
+
+func logOutcome(ctx context.Context, method string, start time.Time, err error) {
+	level := slog.LevelInfo
+	if quietMethods[method] {
+		level = slog.LevelDebug
+	}
+	attrs := []any{"method", method, "code", status.Code(err).String(), "duration_ms", time.Since(start).Milliseconds()}
+	if err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, "err", err)
+	}
+	slog.Log(ctx, level, "request finished", attrs...)
+}
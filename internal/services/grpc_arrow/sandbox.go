@@ -0,0 +1,31 @@
+package grpc_arrow
+
+import (
+	"context"
+	"duckdb-server/internal/sandbox"
+	"log/slog"
+
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sandboxUnaryInterceptor turns the errors DuckDB returns when a query
+// accesses a file or setting it is not allowed to into PERMISSION_DENIED.
+func sandboxUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	return resp, sandboxError(ctx, err)
+}
+
+func sandboxStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return sandboxError(ss.Context(), handler(srv, ss))
+}
+
+func sandboxError(ctx context.Context, err error) error {
+	if !sandbox.IsPermissionError(err) {
+		return err
+	}
+
+	slog.WarnContext(ctx, "denied by the sandbox", "err", err)
+	return status.Error(codes.PermissionDenied, err.Error())
+}
@@ -1,13 +1,30 @@
 package grpc_arrow
 
 import (
+	"context"
+	"duckdb-server/config"
+	"duckdb-server/internal/admission"
+	"duckdb-server/internal/auth"
+	"duckdb-server/internal/health"
+	"duckdb-server/internal/listen"
+	"duckdb-server/internal/profiling"
 	pb "duckdb-server/internal/services/grpc_arrow/data_transform"
+	"duckdb-server/internal/tlsconfig"
+	"duckdb-server/internal/tracing"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
+	"net/http"
+	"time"
 
 	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 )
 
 // func InitServer(host string, port int) {
@@ -24,16 +41,255 @@ import (
 // 	r.Run(fmt.Sprintf("%s:%d", host, port))
 // }
 
-func InitServer(host string, port int) {
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+type Server struct {
+	grpcServer *grpc.Server
+	listeners  []net.Listener
+	// admin serves the admin and health RPCs when they have their own
+	// listeners, nil when grpcServer serves them
+	admin    *grpc.Server
+	certs    *tlsconfig.Reloader
+	service  *dataTransform
+	health   *grpchealth.Server
+	checker  *health.Checker
+	reloader *reloader
+	// stop ends the background goroutines of the server
+	stop context.CancelFunc
+}
+
+// InitServer opens the database and starts listening, Serve then has to be
+// called to accept requests. load reads the configuration again on reloads.
+func InitServer(cfg *config.Config, load func() (*config.Config, error)) (*Server, error) {
+	service, err := NewDataTransformService(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	mode, err := listen.ParseMode(cfg.Server.SocketMode)
+	if err != nil {
+		service.Close()
+		return nil, err
+	}
+	listeners, err := listen.Listen(cfg.Server.Addresses(), mode)
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		service.Close()
+		return nil, fmt.Errorf("failed to listen: %w", err)
 	}
 
+	ac := admission.NewController(admissionConfig(cfg))
+
+	registerGauges(ac, service, cfg.Dirs.TempDownload)
+
+	unary := []grpc.UnaryServerInterceptor{metricsUnaryInterceptor, requestIDUnaryInterceptor}
+	stream := []grpc.StreamServerInterceptor{progressStreamInterceptor, metricsStreamInterceptor, requestIDStreamInterceptor}
+
 	var opts []grpc.ServerOption
+	if cfg.Tracing.Exporter != tracing.ExporterNone {
+		// starts the span of every RPC, continuing the trace of the caller
+		opts = append(opts, grpc.StatsHandler(otelgrpc.NewServerHandler()))
+		stream = append(stream, tracingStreamInterceptor)
+	}
+
+	if cfg.Auth.File != "" {
+		authenticator, err := auth.NewAuthenticator(auth.Config{
+			PolicyFile: cfg.Auth.File,
+			JWKSFile:   cfg.Auth.JWKSFile,
+			Issuer:     cfg.Auth.JWTIssuer,
+			Audience:   cfg.Auth.JWTAudience,
+			RolesClaim: cfg.Auth.JWTRolesClaim,
+		})
+		if err != nil {
+			listen.Close(listeners)
+			service.Close()
+			return nil, fmt.Errorf("failed to load auth configuration: %w", err)
+		}
+
+		unary = append(unary, authUnaryInterceptor(authenticator))
+		stream = append(stream, authStreamInterceptor(authenticator))
+		slog.Info("bearer token authentication enabled")
+	}
+
+	// admission comes last, so that rejected requests don't take a slot
+	unary = append(unary, auditUnaryInterceptor, sandboxUnaryInterceptor, admissionUnaryInterceptor(ac, service.sourceSize))
+	stream = append(stream, auditStreamInterceptor, sandboxStreamInterceptor, admissionStreamInterceptor(ac, service.sourceSize))
+
+	// the relations of a request are dropped once the query history is done
+	// with them
+	unary = append(unary, service.workspaceUnaryInterceptor)
+	stream = append(stream, service.workspaceStreamInterceptor)
+
+	var queryHistory *history
+	if cfg.QueryHistory.Enabled {
+		if err := service.qb.CreateHistoryTable(); err != nil {
+			listen.Close(listeners)
+			service.Close()
+			return nil, fmt.Errorf("failed to create the query history table: %w", err)
+		}
+
+		queryHistory = &history{
+			qb:            service.qb,
+			maxRows:       cfg.QueryHistory.MaxRows,
+			slowThreshold: cfg.QueryHistory.SlowThreshold,
+		}
+		// after admission, so that slow queries are explained while their
+		// request still holds its slot
+		unary = append(unary, queryHistory.unaryInterceptor)
+		stream = append(stream, queryHistory.streamInterceptor)
+	}
+
+	var profiler *profiling.Profiler
+	if cfg.Profiling.Enabled {
+		profiler, err = profiling.New(profiling.Config{
+			Dir:      cfg.Dirs.TempProf,
+			MaxFiles: cfg.Profiling.MaxFiles,
+			MaxAge:   cfg.Profiling.MaxAge,
+		})
+		if err != nil {
+			listen.Close(listeners)
+			service.Close()
+			return nil, fmt.Errorf("failed to set up profiling: %w", err)
+		}
+
+		unary = append(unary, profilingUnaryInterceptor(profiler))
+		stream = append(stream, profilingStreamInterceptor(profiler))
+		slog.Info("profiling enabled")
+	}
+
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)
+
+	ctx, stop := context.WithCancel(context.Background())
+	var certs *tlsconfig.Reloader
+	if cfg.TLS.CertFile != "" {
+		certs, err = tlsconfig.NewReloader(tlsconfig.Config{
+			CertFile:       cfg.TLS.CertFile,
+			KeyFile:        cfg.TLS.KeyFile,
+			ClientCAFile:   cfg.TLS.ClientCAFile,
+			ReloadInterval: cfg.TLS.ReloadInterval,
+		})
+		if err != nil {
+			stop()
+			listen.Close(listeners)
+			service.Close()
+			return nil, fmt.Errorf("failed to load TLS certificates: %w", err)
+		}
+
+		go certs.Watch(ctx)
+		opts = append(opts, grpc.Creds(credentials.NewTLS(certs.TLSConfig("h2"))))
+		slog.Info("TLS enabled", "mutual_tls", cfg.TLS.ClientCAFile != "")
+	}
+
 	grpcServer := grpc.NewServer(opts...)
-	pb.RegisterDataTransformServer(grpcServer, NewDataTransformService())
+	pb.RegisterDataTransformServer(grpcServer, service)
 	reflection.Register(grpcServer) // for grpc-curl
-	log.Printf("strings grpc_arrow server on %s:%d", host, port)
-	grpcServer.Serve(lis)
+
+	// the admin listeners, served by http_admin, keep the admin and health
+	// RPCs off the API addresses
+	var adminServer *grpc.Server
+	if len(cfg.Server.AdminListen) > 0 {
+		adminServer = grpc.NewServer(opts...)
+		reflection.Register(adminServer)
+	}
+	registrar := grpcServer
+	if adminServer != nil {
+		registrar = adminServer
+	}
+
+	reloader := &reloader{load: load, service: service, ac: ac}
+	pb.RegisterAdminServer(registrar, &admin{service: service, profiler: profiler, history: queryHistory, reloader: reloader})
+
+	hs := grpchealth.NewServer()
+	healthpb.RegisterHealthServer(registrar, hs)
+	checker := newChecker(service, ac, cfg)
+	go watchHealth(ctx, checker, hs, cfg.Health.CheckInterval)
+
+	return &Server{
+		grpcServer: grpcServer,
+		listeners:  listeners,
+		admin:      adminServer,
+		certs:      certs,
+		service:    service,
+		health:     hs,
+		checker:    checker,
+		reloader:   reloader,
+		stop:       stop,
+	}, nil
+}
+
+// Reload reads the configuration again and applies the settings that can
+// change while the server runs. The configuration is left as is when it is
+// invalid or changes other settings.
+func (s *Server) Reload(ctx context.Context) error {
+	_, err := s.reloader.reload(ctx)
+	return err
+}
+
+// Health returns the readiness checks of the server, for the HTTP probes.
+func (s *Server) Health() *health.Checker {
+	return s.checker
+}
+
+// Admin returns the handler of the admin and health RPCs when they are served
+// on the admin listeners, nil when they are served on the API addresses.
+func (s *Server) Admin() http.Handler {
+	if s.admin == nil {
+		return nil
+	}
+	return s.admin
+}
+
+// TLS returns the certificates of the server, nil when TLS is disabled.
+func (s *Server) TLS() *tlsconfig.Reloader {
+	return s.certs
+}
+
+// Serve accepts requests on all the listeners until Shutdown is called. It
+// returns nil after a shutdown and the error that stopped the server
+// otherwise.
+func (s *Server) Serve() error {
+	return listen.Serve(s.listeners, func(lis net.Listener) error {
+		slog.Info("grpc_arrow server listening", "network", lis.Addr().Network(), "addr", lis.Addr().String())
+		if err := s.grpcServer.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			return err
+		}
+		return nil
+	})
+}
+
+// Shutdown stops accepting requests and waits up to grace for the running
+// ones to finish before cancelling them. The database is then checkpointed
+// and closed and the temporary files removed.
+func (s *Server) Shutdown(grace time.Duration) error {
+	defer s.stop()
+
+	// the orchestrators stop routing requests here while they drain
+	s.checker.Drain()
+	s.health.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+
+	select {
+	case <-stopped:
+		slog.Info("all requests completed")
+	case <-timer.C:
+		slog.Warn("requests still running, cancelling them", "grace", grace)
+		s.grpcServer.Stop()
+		<-stopped
+	}
+
+	// ends the health watches, which would hold up the shutdown of the admin
+	// listeners
+	if s.admin != nil {
+		s.admin.Stop()
+	}
+
+	return s.service.Close()
 }
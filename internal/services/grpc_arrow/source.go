@@ -0,0 +1,239 @@
+package grpc_arrow
+
+import (
+	"context"
+	"duckdb-server/internal/fetch"
+	"duckdb-server/internal/logging"
+	"duckdb-server/internal/metrics"
+	"duckdb-server/internal/objectstore"
+	pb "duckdb-server/internal/services/grpc_arrow/data_transform"
+	"duckdb-server/internal/tracing"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// tempSeq tells apart the temporary files created within the same second.
+var tempSeq atomic.Uint64
+
+// tempPath returns a new path in the download directory with the given
+// extension.
+func (t dataTransform) tempPath(ext string) string {
+	return path.Join(t.config().Dirs.TempDownload, fmt.Sprintf("%d-%d%s", time.Now().Unix(), tempSeq.Add(1), ext))
+}
+
+// isRemote reports whether the source has to be downloaded. Only the scheme
+// is looked at, as presigned URLs can contain other URLs.
+func isRemote(p string) bool {
+	u, err := url.Parse(p)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "s3")
+}
+
+// loadSource loads the CSV source of the request in the table. Local paths
+// must be inside the sandbox. http(s) sources are taken from the download
+// cache when enabled. Other remote sources are downloaded first, or piped to
+// DuckDB as they arrive when STREAM_REMOTE_SOURCES is set, and the temporary
+// file is removed once loaded.
+func (t dataTransform) loadSource(ctx context.Context, in *pb.QueryIn, tableName string) error {
+	if !isRemote(in.Path) {
+		filePath, err := t.sandbox.Resolve(in.Path)
+		if err != nil {
+			slog.WarnContext(ctx, "error resolving path", "err", err)
+			return err
+		}
+		return t.loadCSV(ctx, tableName, filePath)
+	}
+
+	if t.cache != nil && !objectstore.IsURI(in.Path) {
+		slog.DebugContext(ctx, "getting the source from the download cache")
+		ctx, download := startPhase(ctx, metrics.PhaseDownload)
+		filePath, release, err := t.cache.Get(ctx, in.Path, in.SourceSha256)
+		download.end(err, fileAttributes(filePath)...)
+		if err != nil {
+			slog.ErrorContext(ctx, "error downloading file", "err", err)
+			return downloadError(in.Path, err)
+		}
+		defer release()
+
+		return t.loadCSV(ctx, tableName, filePath)
+	}
+
+	if t.config().Download.StreamRemoteSources {
+		return t.loadPiped(ctx, in, tableName)
+	}
+
+	slog.DebugContext(ctx, "downloading the source")
+	filePath := t.tempPath(".csv")
+	if err := t.download(ctx, in, filePath); err != nil {
+		return err
+	}
+	defer t.tmp.Remove(filePath)
+
+	return t.loadCSV(ctx, tableName, filePath)
+}
+
+func (t dataTransform) loadCSV(ctx context.Context, tableName, filePath string) error {
+	_, load := startPhase(ctx, metrics.PhaseLoad, fileAttributes(filePath)...)
+
+	slog.DebugContext(ctx, "loading data to duckdb")
+	err := t.qb.CSVToTable(tableName, filePath)
+	load.end(err)
+	if err != nil {
+		slog.ErrorContext(ctx, "error loading data to duckdb", "err", err)
+		return err
+	}
+	return nil
+}
+
+// loadPiped loads a remote source through a named pipe written to as the
+// download goes on, so that DuckDB reads while the transfer is in progress and
+// nothing is kept on disk. DuckDB sees a failed download as a short file, so
+// the download error wins over the result of the load.
+func (t dataTransform) loadPiped(ctx context.Context, in *pb.QueryIn, tableName string) error {
+	pipePath := t.tempPath(".csv")
+	if err := syscall.Mkfifo(pipePath, 0o600); err != nil {
+		slog.ErrorContext(ctx, "error creating pipe", "err", err)
+		return err
+	}
+	t.tmp.Add(pipePath)
+	defer t.tmp.Remove(pipePath)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	slog.DebugContext(ctx, "streaming the source to duckdb")
+	done := make(chan error, 1)
+	go func() {
+		ctx, download := startPhase(ctx, metrics.PhaseDownload)
+		out, err := openPipe(ctx, pipePath)
+		if err != nil {
+			download.end(err)
+			done <- err
+			return
+		}
+
+		w := &countingWriter{w: out}
+		err = t.stream(ctx, in, w)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		download.end(err, tracing.Bytes.Int64(w.n))
+		done <- err
+	}()
+
+	if loadErr := t.loadCSV(ctx, tableName, pipePath); loadErr != nil {
+		select {
+		case err := <-done:
+			// a download failing first is the likely cause, unless it failed
+			// writing to the pipe DuckDB closed
+			if err != nil && !errors.Is(err, syscall.EPIPE) {
+				slog.ErrorContext(ctx, "error streaming file", "err", err)
+				return downloadError(in.Path, err)
+			}
+		default:
+			// DuckDB stopped reading early, writes fail from now on
+			cancel()
+			<-done
+		}
+		return loadErr
+	}
+
+	if err := <-done; err != nil {
+		slog.ErrorContext(ctx, "error streaming file", "err", err)
+		return downloadError(in.Path, err)
+	}
+	return nil
+}
+
+// openPipe opens the named pipe for writing once DuckDB opened it for
+// reading. Until then the pipe can't hold data: it would be lost when closed
+// before DuckDB opens it, and DuckDB would then wait for another writer.
+func openPipe(ctx context.Context, pipePath string) (*os.File, error) {
+	for {
+		f, err := os.OpenFile(pipePath, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+		if !errors.Is(err, syscall.ENXIO) {
+			return f, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// download fetches the remote source to filePath, checking its checksum when
+// the request has one.
+func (t dataTransform) download(ctx context.Context, in *pb.QueryIn, filePath string) error {
+	ctx, download := startPhase(ctx, metrics.PhaseDownload)
+
+	t.tmp.Add(filePath)
+
+	var err error
+	if objectstore.IsURI(in.Path) {
+		err = t.store.Download(ctx, in.Path, filePath, t.config().Download.MaxBytes, in.SourceSha256)
+	} else {
+		err = t.fetcher.DownloadToFile(ctx, in.Path, filePath, in.SourceSha256)
+	}
+	download.end(err, fileAttributes(filePath)...)
+	if err == nil {
+		return nil
+	}
+
+	slog.ErrorContext(ctx, "error downloading file", "err", err)
+	t.tmp.Remove(filePath)
+	return downloadError(in.Path, err)
+}
+
+// stream writes the remote source to w, checking its checksum when the
+// request has one.
+func (t dataTransform) stream(ctx context.Context, in *pb.QueryIn, w io.Writer) error {
+	if objectstore.IsURI(in.Path) {
+		return t.store.Stream(ctx, in.Path, w, t.config().Download.MaxBytes, in.SourceSha256)
+	}
+	return t.fetcher.Stream(ctx, in.Path, w, in.SourceSha256)
+}
+
+// downloadError converts a download error to a status error. The source is
+// redacted as the error ends up in the logs.
+func downloadError(source string, err error) error {
+	source = logging.RedactURL(source)
+	var statusErr *fetch.StatusError
+	switch {
+	case errors.Is(err, fetch.ErrForbidden), errors.Is(err, objectstore.ErrForbidden):
+		return status.Errorf(codes.PermissionDenied, "downloading %s: %v", source, err)
+	case errors.Is(err, fetch.ErrTooLarge), errors.Is(err, fetch.ErrChecksumMismatch), errors.Is(err, fetch.ErrContentType),
+		errors.Is(err, objectstore.ErrTooLarge), errors.Is(err, objectstore.ErrChecksumMismatch), errors.Is(err, objectstore.ErrInvalidURI):
+		return status.Errorf(codes.InvalidArgument, "downloading %s: %v", source, err)
+	case errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound, errors.Is(err, objectstore.ErrNotFound):
+		return status.Errorf(codes.NotFound, "downloading %s: %v", source, err)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		return status.Errorf(codes.Unavailable, "downloading %s: %v", source, err)
+	}
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,190 @@
+package grpc_arrow
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"duckdb-server/config"
+	"duckdb-server/internal/fetch"
+	querybuilder "duckdb-server/internal/query_builder"
+	pb "duckdb-server/internal/services/grpc_arrow/data_transform"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newPipeService returns a service streaming the remote sources from the
+// loopback servers to its database.
+func newPipeService(t *testing.T) *dataTransform {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.Dirs.TempDownload = t.TempDir()
+	cfg.Download.StreamRemoteSources = true
+
+	qb, err := querybuilder.NewDuckDBQueryBuilder(filepath.Join(t.TempDir(), "db.duckdb"), querybuilder.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { qb.Close() })
+
+	fetcher, err := fetch.New(fetch.Config{
+		Timeout:        10 * time.Second,
+		ConnectTimeout: time.Second,
+		AllowHTTP:      true,
+		AllowedCIDRs:   []string{"127.0.0.0/8"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service := &dataTransform{cfg: &atomic.Pointer[config.Config]{}, qb: qb, tmp: newTempFiles(), fetcher: fetcher}
+	service.cfg.Store(cfg)
+	return service
+}
+
+// loadPipedWithin loads the source in the table t, failing the test unless
+// the load returns within a few seconds, the pipe removed.
+func loadPipedWithin(t *testing.T, service *dataTransform, source, table string) error {
+	t.Helper()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- service.loadPiped(context.Background(), &pb.QueryIn{Path: source}, table)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("loadPiped blocked")
+	}
+
+	entries, readErr := os.ReadDir(service.config().Dirs.TempDownload)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	for _, e := range entries {
+		t.Errorf("%s left in the download directory", e.Name())
+	}
+	if n := len(service.tmp.paths); n != 0 {
+		t.Errorf("%d temporary files still tracked", n)
+	}
+	return err
+}
+
+func TestLoadPiped(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		fmt.Fprint(w, "a,b\n")
+		for i := range 10000 {
+			fmt.Fprintf(w, "%d,%d\n", i, 2*i)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	service := newPipeService(t)
+
+	if err := loadPipedWithin(t, service, srv.URL+"/data.csv", "t"); err != nil {
+		t.Fatalf("loadPiped: %v", err)
+	}
+	rows, err := service.qb.Query("SELECT count(*), sum(b) FROM t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var count, total int64
+	if !rows.Next() {
+		t.Fatal(rows.Err())
+	}
+	if err := rows.Scan(&count, &total); err != nil {
+		t.Fatal(err)
+	}
+	if count != 10000 || total != 10000*9999 {
+		t.Errorf("%d rows summing to %d, want 10000 rows summing to %d", count, total, 10000*9999)
+	}
+}
+
+func TestLoadPipedDownloadFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    codes.Code
+	}{
+		{"not found", func(w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		}, codes.NotFound},
+		{"truncated", func(w http.ResponseWriter, r *http.Request) {
+			// the rows received so far load, the download error wins
+			w.Header().Set("Content-Type", "text/csv")
+			w.Header().Set("Content-Length", "1000")
+			fmt.Fprint(w, "a,b\n1,2\n3,4\n")
+		}, codes.Unavailable},
+		{"failing mid-stream", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/csv")
+			fmt.Fprint(w, "a,b\n1,2\n3,4\n")
+			w.(http.Flusher).Flush()
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+		}, codes.Unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			t.Cleanup(srv.Close)
+
+			err := loadPipedWithin(t, newPipeService(t), srv.URL+"/data.csv", "t")
+			if status.Code(err) != tt.want {
+				t.Errorf("loadPiped = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPipedLoadFailure(t *testing.T) {
+	// the source never ends, the download is only stopped by the load, which
+	// fails on a value unlike those of the rows sniffed
+	var disconnected atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		line := []byte(strings.Repeat("1,2\n", 1024))
+		fmt.Fprint(w, "a,b\n", strings.Repeat(string(line), 100), "x,2\n")
+		for {
+			if _, err := w.Write(line); err != nil || r.Context().Err() != nil {
+				disconnected.Store(true)
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Run("stopped early", func(t *testing.T) {
+		err := loadPipedWithin(t, newPipeService(t), srv.URL+"/data.csv", "t")
+		if err == nil || status.Code(err) != codes.Unknown {
+			t.Errorf("loadPiped = %v, want the error of the load", err)
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for !disconnected.Load() && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if !disconnected.Load() {
+			t.Error("download still running")
+		}
+	})
+
+	t.Run("pipe never opened", func(t *testing.T) {
+		err := loadPipedWithin(t, newPipeService(t), srv.URL+"/data.csv", "t\x00")
+		if err == nil {
+			t.Error("loadPiped succeeded with an invalid table name")
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package grpc_arrow
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// tempFiles keeps track of the files created on behalf of requests, so that
+// none of them survives the server.
+type tempFiles struct {
+	mu    sync.Mutex
+	paths map[string]struct{}
+}
+
+func newTempFiles() *tempFiles {
+	return &tempFiles{paths: map[string]struct{}{}}
+}
+
+func (t *tempFiles) Add(p string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.paths[p] = struct{}{}
+}
+
+// Remove deletes the file and stops tracking it.
+func (t *tempFiles) Remove(p string) error {
+	t.mu.Lock()
+	delete(t.paths, p)
+	t.mu.Unlock()
+
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// RemoveAll deletes every tracked file.
+func (t *tempFiles) RemoveAll() error {
+	t.mu.Lock()
+	paths := t.paths
+	t.paths = map[string]struct{}{}
+	t.mu.Unlock()
+
+	var errs []error
+	for p := range paths {
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
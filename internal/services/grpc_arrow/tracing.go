@@ -0,0 +1,105 @@
+package grpc_arrow
+
+import (
+	"context"
+	"duckdb-server/internal/metrics"
+	pb "duckdb-server/internal/services/grpc_arrow/data_transform"
+	"duckdb-server/internal/tracing"
+	"os"
+	"time"
+
+	grpc "google.golang.org/grpc"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// phase is a phase of a request, timed in the metrics and the query history
+// and traced as a span.
+type phase struct {
+	name   string
+	start  time.Time
+	record *queryRecord
+	span   trace.Span
+	done   func()
+}
+
+func startPhase(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, phase) {
+	done := metrics.StartPhase(ctx, name)
+	progressFromContext(ctx).setPhase(name)
+	ctx, span := tracing.Start(ctx, name, attrs...)
+	return ctx, phase{name: name, start: time.Now(), record: recordFromContext(ctx), span: span, done: done}
+}
+
+// end ends the phase, adding attrs to its span.
+func (p phase) end(err error, attrs ...attribute.KeyValue) {
+	p.done()
+	p.record.addPhase(p.name, time.Since(p.start))
+	p.span.SetAttributes(attrs...)
+	tracing.End(p.span, err)
+}
+
+// tracingStreamInterceptor traces the sending of every chunk and records the
+// totals on the span of the RPC, started by the otelgrpc stats handler.
+func tracingStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	s := &tracedStream{ServerStream: ss}
+	err := handler(srv, s)
+	trace.SpanFromContext(ss.Context()).SetAttributes(
+		tracing.Chunks.Int64(s.chunks),
+		tracing.Rows.Int64(s.rows),
+		tracing.Bytes.Int64(s.bytes),
+	)
+	return err
+}
+
+type tracedStream struct {
+	grpc.ServerStream
+	chunks, rows, bytes int64
+}
+
+func (s *tracedStream) SendMsg(m any) error {
+	out, _ := m.(*pb.QueryOut)
+	_, span := tracing.Start(s.Context(), metrics.PhaseSend, chunkAttributes(out)...)
+	err := s.ServerStream.SendMsg(m)
+	tracing.End(span, err)
+
+	if out != nil && err == nil {
+		s.chunks++
+		s.rows += int64(out.Count)
+		s.bytes += chunkSize(out)
+	}
+	return err
+}
+
+// chunkAttributes describes a chunk, nil for none.
+func chunkAttributes(out *pb.QueryOut) []attribute.KeyValue {
+	if out == nil {
+		return nil
+	}
+	return []attribute.KeyValue{
+		tracing.Rows.Int64(int64(out.Count)),
+		tracing.Bytes.Int64(chunkSize(out)),
+	}
+}
+
+func chunkSize(out *pb.QueryOut) int64 {
+	var size int64
+	for _, data := range out.Data {
+		size += int64(len(data))
+	}
+	return size
+}
+
+// fileAttributes describes a file, nothing for pipes and missing files.
+func fileAttributes(filePath string) []attribute.KeyValue {
+	info, err := os.Stat(filePath)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil
+	}
+	return []attribute.KeyValue{tracing.FileSize.Int64(info.Size())}
+}
+
+// setQueryHash records the hash of the query on the span of the RPC.
+func setQueryHash(ctx context.Context, query string) {
+	trace.SpanFromContext(ctx).SetAttributes(tracing.QueryHash.String(tracing.HashQuery(query)))
+}
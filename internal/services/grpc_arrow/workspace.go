@@ -0,0 +1,84 @@
+package grpc_arrow
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+
+	querybuilder "duckdb-server/internal/query_builder"
+
+	grpc "google.golang.org/grpc"
+)
+
+// sourceRelation is the name transformations read the source as.
+const sourceRelation = "loadtest"
+
+// workspaceSeq numbers the workspaces. Request IDs can be chosen by clients,
+// so they can't tell requests apart.
+var workspaceSeq atomic.Uint64
+
+// workspace names the table the source of a request is loaded in and the view
+// over its transformation. Requests run concurrently, each one has a table of
+// its own which the transformation reads as sourceRelation.
+type workspace struct {
+	table string
+	view  string
+}
+
+func newWorkspace() workspace {
+	n := workspaceSeq.Add(1)
+	return workspace{
+		table: fmt.Sprintf("%s_%d", sourceRelation, n),
+		view:  fmt.Sprintf("v_%s_%d", sourceRelation, n),
+	}
+}
+
+type workspaceKey struct{}
+
+// workspaceFromContext returns the workspace of the request.
+func workspaceFromContext(ctx context.Context) workspace {
+	if ws, ok := ctx.Value(workspaceKey{}).(workspace); ok {
+		return ws
+	}
+	// not set up by workspaceUnaryInterceptor, nothing drops its relations
+	slog.WarnContext(ctx, "request without a workspace")
+	return newWorkspace()
+}
+
+// workspaceUnaryInterceptor gives the transformations a workspace and drops
+// its relations once the request is done, the interceptors it wraps included.
+func (t dataTransform) workspaceUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if !isTransform(info.FullMethod) {
+		return handler(ctx, req)
+	}
+
+	ws := newWorkspace()
+	defer t.dropWorkspace(ctx, ws)
+	return handler(context.WithValue(ctx, workspaceKey{}, ws), req)
+}
+
+func (t dataTransform) workspaceStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !isTransform(info.FullMethod) {
+		return handler(srv, ss)
+	}
+
+	ws := newWorkspace()
+	defer t.dropWorkspace(ss.Context(), ws)
+	return handler(srv, &identifiedStream{ServerStream: ss, ctx: context.WithValue(ss.Context(), workspaceKey{}, ws)})
+}
+
+func (t dataTransform) dropWorkspace(ctx context.Context, ws workspace) {
+	if err := t.qb.DropView(ws.view); err != nil {
+		slog.ErrorContext(ctx, "error dropping view", "view", ws.view, "err", err)
+	}
+	if err := t.qb.DropTable(ws.table); err != nil {
+		slog.ErrorContext(ctx, "error dropping table", "table", ws.table, "err", err)
+	}
+}
+
+// bind returns the query reading the table of the workspace as
+// sourceRelation.
+func (ws workspace) bind(query string) string {
+	return querybuilder.BindTable(query, sourceRelation, ws.table)
+}
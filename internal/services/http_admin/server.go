@@ -0,0 +1,121 @@
+// Package http_admin serves the HTTP endpoints used to operate the server,
+// next to the gRPC API. On the admin listeners it also serves the admin and
+// health RPCs, over HTTP/2.
+package http_admin
+
+import (
+	"context"
+	"crypto/tls"
+	"duckdb-server/config"
+	"duckdb-server/internal/health"
+	"duckdb-server/internal/listen"
+	"duckdb-server/internal/metrics"
+	"duckdb-server/internal/tlsconfig"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+type Server struct {
+	httpServer *http.Server
+	listeners  []net.Listener
+}
+
+// InitServer starts listening on the admin listeners, or on the HTTP port of
+// the API host without them, Serve then has to be called to accept requests. The probes
+// report the last run of the checks of checker. grpcAdmin serves the gRPC
+// requests of the admin listeners, which use TLS when certs isn't nil.
+func InitServer(cfg *config.Config, checker *health.Checker, grpcAdmin http.Handler, certs *tlsconfig.Reloader) (*Server, error) {
+	mode, err := listen.ParseMode(cfg.Server.SocketMode)
+	if err != nil {
+		return nil, err
+	}
+	listeners, err := listen.Listen(cfg.Server.AdminAddresses(), mode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		readyz(w, checker.Last())
+	})
+	if cfg.Profiling.Enabled {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+
+	httpServer := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	if grpcAdmin != nil {
+		httpServer.Handler = withGRPC(mux, grpcAdmin)
+		if certs != nil {
+			tlsConfig := certs.TLSConfig("h2", "http/1.1")
+			for i, lis := range listeners {
+				listeners[i] = tls.NewListener(lis, tlsConfig)
+			}
+			if err := http2.ConfigureServer(httpServer, nil); err != nil {
+				listen.Close(listeners)
+				return nil, fmt.Errorf("failed to set up HTTP/2: %w", err)
+			}
+		} else {
+			// HTTP/2 without TLS, which the gRPC clients use
+			httpServer.Handler = h2c.NewHandler(httpServer.Handler, &http2.Server{})
+		}
+	}
+
+	return &Server{httpServer: httpServer, listeners: listeners}, nil
+}
+
+// withGRPC sends the gRPC requests to grpcHandler and the others to handler.
+func withGRPC(handler, grpcHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			grpcHandler.ServeHTTP(w, r)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Serve accepts requests on all the listeners until Shutdown is called. It
+// returns nil after a shutdown and the error that stopped the server
+// otherwise.
+func (s *Server) Serve() error {
+	return listen.Serve(s.listeners, func(lis net.Listener) error {
+		slog.Info("http_admin server listening", "network", lis.Addr().Network(), "addr", lis.Addr().String())
+		if err := s.httpServer.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
+}
+
+// readyz writes the report as JSON, with a 503 status when not ready.
+func readyz(w http.ResponseWriter, report health.Report) {
+	w.Header().Set("Content-Type", "application/json")
+	if !report.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		slog.Debug("error writing readiness report", "err", err)
+	}
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
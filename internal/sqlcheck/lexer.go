@@ -0,0 +1,243 @@
+package sqlcheck
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	// tokQuotedIdent is a double quoted identifier, never a keyword
+	tokQuotedIdent
+	tokString
+	tokNumber
+	tokParam
+	tokPunct
+	tokOperator
+)
+
+type token struct {
+	kind tokenKind
+	// text is the identifier, unquoted and lower cased unless quoted, or the
+	// raw text of the other tokens
+	text   string
+	offset int
+}
+
+func (t token) is(kind tokenKind, text string) bool {
+	return t.kind == kind && t.text == text
+}
+
+func (t token) keyword(kw string) bool {
+	return t.kind == tokIdent && t.text == kw
+}
+
+// lex splits a query into tokens, dropping whitespace and comments. It
+// follows the DuckDB syntax closely enough to find where every string,
+// identifier and statement starts and ends.
+func lex(query string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		start := i
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += end
+			}
+
+		case strings.HasPrefix(query[i:], "/*"):
+			end, err := blockCommentEnd(query, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+
+		case c == '\'':
+			end, err := stringEnd(query, i+1, false)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokString, text: query[start:end], offset: start})
+			i = end
+
+		case (c == 'e' || c == 'E') && i+1 < len(query) && query[i+1] == '\'':
+			end, err := stringEnd(query, i+2, true)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokString, text: query[start:end], offset: start})
+			i = end
+
+		case c == '"':
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(query) {
+					return nil, &Violation{Rule: RuleSyntax, Message: "unterminated quoted identifier", Offset: start}
+				}
+				if query[i] == '"' {
+					if i+1 < len(query) && query[i+1] == '"' {
+						b.WriteByte('"')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				b.WriteByte(query[i])
+				i++
+			}
+			tokens = append(tokens, token{kind: tokQuotedIdent, text: b.String(), offset: start})
+
+		case c == '$':
+			if i+1 < len(query) && isDigit(query[i+1]) {
+				i++
+				for i < len(query) && isDigit(query[i]) {
+					i++
+				}
+				tokens = append(tokens, token{kind: tokParam, text: query[start:i], offset: start})
+				break
+			}
+
+			end, err := dollarStringEnd(query, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokString, text: query[start:end], offset: start})
+			i = end
+
+		case c == '?':
+			i++
+			tokens = append(tokens, token{kind: tokParam, text: "?", offset: start})
+
+		case isDigit(c) || (c == '.' && i+1 < len(query) && isDigit(query[i+1])):
+			for i < len(query) && (isIdentPart(query[i]) || query[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: query[start:i], offset: start})
+
+		case isIdentStart(query, i):
+			for i < len(query) {
+				r, size := utf8.DecodeRuneInString(query[i:])
+				if r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				i += size
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: strings.ToLower(query[start:i]), offset: start})
+
+		case strings.ContainsRune("(),;[]{}.", rune(c)):
+			i++
+			tokens = append(tokens, token{kind: tokPunct, text: query[start:i], offset: start})
+
+		default:
+			for i < len(query) && strings.ContainsRune("+-*/<>=~!@#%^&|`:", rune(query[i])) {
+				// a comment start ends the operator
+				if strings.HasPrefix(query[i:], "--") || strings.HasPrefix(query[i:], "/*") {
+					break
+				}
+				i++
+			}
+			if i == start {
+				r, _ := utf8.DecodeRuneInString(query[i:])
+				return nil, &Violation{Rule: RuleSyntax, Message: fmt.Sprintf("unexpected character %q", r), Offset: start}
+			}
+			tokens = append(tokens, token{kind: tokOperator, text: query[start:i], offset: start})
+		}
+	}
+
+	return tokens, nil
+}
+
+// blockCommentEnd returns the offset following the comment starting at i,
+// block comments nest.
+func blockCommentEnd(query string, i int) (int, error) {
+	start := i
+	depth := 0
+	for i < len(query) {
+		switch {
+		case strings.HasPrefix(query[i:], "/*"):
+			depth++
+			i += 2
+		case strings.HasPrefix(query[i:], "*/"):
+			depth--
+			i += 2
+			if depth == 0 {
+				return i, nil
+			}
+		default:
+			i++
+		}
+	}
+	return 0, &Violation{Rule: RuleSyntax, Message: "unterminated comment", Offset: start}
+}
+
+// stringEnd returns the offset following the string whose content starts at
+// i. Quotes are escaped by doubling them, and by a backslash in escape
+// strings.
+func stringEnd(query string, i int, escapes bool) (int, error) {
+	start := i - 1
+	for i < len(query) {
+		switch {
+		case escapes && query[i] == '\\':
+			i += 2
+		case query[i] == '\'':
+			if i+1 < len(query) && query[i+1] == '\'' {
+				i += 2
+				continue
+			}
+			return i + 1, nil
+		default:
+			i++
+		}
+	}
+	return 0, &Violation{Rule: RuleSyntax, Message: "unterminated string", Offset: start}
+}
+
+// dollarStringEnd returns the offset following the $tag$...$tag$ string
+// starting at i.
+func dollarStringEnd(query string, i int) (int, error) {
+	end := strings.IndexByte(query[i+1:], '$')
+	if end < 0 {
+		return 0, &Violation{Rule: RuleSyntax, Message: "unexpected character '$'", Offset: i}
+	}
+
+	tag := query[i : i+end+2]
+	for _, r := range tag[1 : len(tag)-1] {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return 0, &Violation{Rule: RuleSyntax, Message: "unexpected character '$'", Offset: i}
+		}
+	}
+
+	close := strings.Index(query[i+len(tag):], tag)
+	if close < 0 {
+		return 0, &Violation{Rule: RuleSyntax, Message: "unterminated dollar-quoted string", Offset: i}
+	}
+	return i + len(tag) + close + len(tag), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentPart(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentStart(query string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(query[i:])
+	return r == '_' || unicode.IsLetter(r)
+}
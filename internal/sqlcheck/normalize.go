@@ -0,0 +1,44 @@
+package sqlcheck
+
+import "strings"
+
+// Normalize returns the query with its literals and parameters replaced by ?,
+// its comments removed and its tokens separated by single spaces, so that
+// queries differing only by their values or layout normalize the same. The
+// query is returned as is when it can't be split into tokens.
+func Normalize(query string) string {
+	tokens, err := lex(query)
+	if err != nil {
+		return query
+	}
+
+	parts := make([]string, len(tokens))
+	for i, t := range tokens {
+		switch t.kind {
+		case tokString, tokNumber, tokParam:
+			parts[i] = "?"
+		case tokQuotedIdent:
+			parts[i] = `"` + strings.ReplaceAll(t.text, `"`, `""`) + `"`
+		default:
+			parts[i] = t.text
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// TrimTerminator returns the query without the semicolon ending it and what
+// follows, so that it can be nested in another query. The query is returned as
+// is when it can't be split into tokens.
+func TrimTerminator(query string) string {
+	tokens, err := lex(query)
+	if err != nil {
+		return query
+	}
+
+	for _, t := range tokens {
+		if t.is(tokPunct, ";") {
+			return query[:t.offset]
+		}
+	}
+	return query
+}
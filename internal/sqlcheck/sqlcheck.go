@@ -0,0 +1,385 @@
+// Package sqlcheck validates the SQL of a transformation before it is run:
+// it must be a single read-only SELECT over the allowed relations, without
+// file access or denied functions.
+package sqlcheck
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Rule identifies the check a query failed.
+type Rule string
+
+const (
+	RuleSyntax             Rule = "SYNTAX"
+	RuleLength             Rule = "LENGTH"
+	RuleMultipleStatements Rule = "MULTIPLE_STATEMENTS"
+	RuleStatement          Rule = "STATEMENT"
+	RuleRelation           Rule = "RELATION"
+	RuleTableFunction      Rule = "TABLE_FUNCTION"
+	RuleFunction           Rule = "FUNCTION"
+	RuleFileAccess         Rule = "FILE_ACCESS"
+)
+
+// Violation is a query failing a rule, at a position of the query.
+type Violation struct {
+	Rule    Rule
+	Message string
+	// Offset is in bytes, Line and Column start at 1 and count runes.
+	Offset int
+	Line   int
+	Column int
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", v.Line, v.Column, v.Message)
+}
+
+// GRPCStatus makes the violation an INVALID_ARGUMENT error carrying the rule
+// and the position.
+func (v *Violation) GRPCStatus() *status.Status {
+	st := status.New(codes.InvalidArgument, "invalid query: "+v.Error())
+	detailed, err := st.WithDetails(
+		&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "query", Description: v.Error()},
+		}},
+		&errdetails.ErrorInfo{
+			Reason: string(v.Rule),
+			Domain: "duckdb-server",
+			Metadata: map[string]string{
+				"offset": strconv.Itoa(v.Offset),
+				"line":   strconv.Itoa(v.Line),
+				"column": strconv.Itoa(v.Column),
+			},
+		},
+	)
+	if err != nil {
+		return st
+	}
+	return detailed
+}
+
+type Rules struct {
+	// MaxLength is the longest query accepted in bytes, no limit when 0.
+	MaxLength int
+	// AllowedRelations are the tables and views queries can read, on top of
+	// the CTEs they define.
+	AllowedRelations []string
+	// AllowedTableFunctions are the table functions queries can call, such as
+	// range or unnest.
+	AllowedTableFunctions []string
+	// DeniedFunctions are the scalar functions queries can't call.
+	DeniedFunctions []string
+}
+
+// deniedKeywords start statements that are not read-only. They are rejected
+// anywhere in the query when not quoted.
+var deniedKeywords = map[string]bool{
+	"alter": true, "attach": true, "call": true, "checkpoint": true, "copy": true,
+	"create": true, "deallocate": true, "delete": true, "detach": true, "drop": true,
+	"execute": true, "export": true, "import": true, "insert": true, "install": true,
+	"load": true, "merge": true, "pragma": true, "prepare": true, "truncate": true,
+	"update": true, "vacuum": true,
+}
+
+// queryStarts are the keywords a query or subquery can start with.
+var queryStarts = map[string]bool{
+	"select": true, "with": true, "from": true, "values": true, "table": true,
+}
+
+// fromClauseEnds are the keywords ending the FROM clause of a query.
+var fromClauseEnds = map[string]bool{
+	"where": true, "group": true, "having": true, "qualify": true, "window": true,
+	"order": true, "limit": true, "offset": true, "union": true, "except": true,
+	"intersect": true, "select": true,
+}
+
+type Validator struct {
+	rules           Rules
+	relations       map[string]bool
+	tableFunctions  map[string]bool
+	deniedFunctions map[string]bool
+}
+
+func NewValidator(rules Rules) *Validator {
+	return &Validator{
+		rules:           rules,
+		relations:       lowerSet(rules.AllowedRelations),
+		tableFunctions:  lowerSet(rules.AllowedTableFunctions),
+		deniedFunctions: lowerSet(rules.DeniedFunctions),
+	}
+}
+
+func lowerSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	return set
+}
+
+// level is a nesting level of parentheses or brackets.
+type level struct {
+	// query is set when the level holds a query rather than an expression
+	// or function arguments
+	query bool
+	// from is set while in the FROM clause of the query
+	from bool
+}
+
+// Validate returns a *Violation when the query breaks a rule.
+func (v *Validator) Validate(query string) error {
+	if err := v.validate(query); err != nil {
+		if violation, ok := err.(*Violation); ok {
+			violation.Line, violation.Column = position(query, violation.Offset)
+		}
+		return err
+	}
+	return nil
+}
+
+func (v *Validator) validate(query string) error {
+	if v.rules.MaxLength > 0 && len(query) > v.rules.MaxLength {
+		return &Violation{Rule: RuleLength, Message: fmt.Sprintf("query is longer than %d bytes", v.rules.MaxLength), Offset: v.rules.MaxLength}
+	}
+
+	tokens, err := lex(query)
+	if err != nil {
+		return err
+	}
+
+	// a trailing semicolon is allowed, anything after it is another statement
+	for i, t := range tokens {
+		if !t.is(tokPunct, ";") {
+			continue
+		}
+		for _, next := range tokens[i+1:] {
+			if !next.is(tokPunct, ";") {
+				return &Violation{Rule: RuleMultipleStatements, Message: "only a single statement is allowed", Offset: next.offset}
+			}
+		}
+		tokens = tokens[:i]
+		break
+	}
+
+	if len(tokens) == 0 {
+		return &Violation{Rule: RuleSyntax, Message: "empty query", Offset: 0}
+	}
+
+	first := tokens[0]
+	for _, t := range tokens {
+		if !t.is(tokPunct, "(") {
+			first = t
+			break
+		}
+	}
+	if first.kind != tokIdent || !queryStarts[first.text] {
+		return &Violation{Rule: RuleStatement, Message: fmt.Sprintf("only SELECT queries are allowed, got %q", first.text), Offset: first.offset}
+	}
+
+	ctes := cteNames(tokens)
+	stack := []level{{query: true}}
+	expectRelation := false
+
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		top := &stack[len(stack)-1]
+
+		if expectRelation {
+			switch {
+			case t.keyword("lateral"):
+				continue
+			case t.kind == tokString:
+				return &Violation{Rule: RuleFileAccess, Message: "reading files is not allowed", Offset: t.offset}
+			case t.kind == tokIdent || t.kind == tokQuotedIdent:
+				expectRelation = false
+
+				// qualified names are matched as a whole
+				name, last := qualifiedName(tokens, i)
+				if last+1 < len(tokens) && tokens[last+1].is(tokPunct, "(") {
+					if !v.tableFunctions[name] {
+						return &Violation{Rule: RuleTableFunction, Message: fmt.Sprintf("table function %s is not allowed", name), Offset: t.offset}
+					}
+				} else if !v.relations[name] && !ctes[name] {
+					return &Violation{Rule: RuleRelation, Message: fmt.Sprintf("relation %s is not allowed", name), Offset: t.offset}
+				}
+				i = last
+				continue
+			case t.is(tokPunct, "("):
+				if i+1 < len(tokens) && tokens[i+1].kind == tokIdent && queryStarts[tokens[i+1].text] {
+					// a subquery
+					expectRelation = false
+					stack = append(stack, level{query: true})
+					continue
+				}
+				// parenthesized joins, starting with a relation
+				stack = append(stack, level{query: true, from: true})
+				continue
+			default:
+				expectRelation = false
+			}
+		}
+
+		switch t.kind {
+		case tokPunct:
+			switch t.text {
+			case "(", "[", "{":
+				query := t.text == "(" && i+1 < len(tokens) && tokens[i+1].kind == tokIdent && queryStarts[tokens[i+1].text]
+				stack = append(stack, level{query: query})
+			case ")", "]", "}":
+				if len(stack) > 1 {
+					stack = stack[:len(stack)-1]
+				}
+			case ",":
+				expectRelation = top.query && top.from
+			}
+
+		case tokIdent:
+			qualified := i > 0 && tokens[i-1].is(tokPunct, ".")
+			if deniedKeywords[t.text] && !qualified {
+				return &Violation{Rule: RuleStatement, Message: fmt.Sprintf("%s is not allowed, double-quote it when used as a name", strings.ToUpper(t.text)), Offset: t.offset}
+			}
+
+			if i+1 < len(tokens) && tokens[i+1].is(tokPunct, "(") && v.deniedFunctions[t.text] {
+				return &Violation{Rule: RuleFunction, Message: fmt.Sprintf("function %s is not allowed", t.text), Offset: t.offset}
+			}
+
+			// TABLE x reads x, wherever it starts a query
+			if t.text == "table" && !qualified {
+				expectRelation = true
+				continue
+			}
+
+			if !top.query || qualified {
+				continue
+			}
+
+			switch {
+			case t.text == "from":
+				// IS [NOT] DISTINCT FROM compares values
+				if i > 0 && tokens[i-1].keyword("distinct") {
+					continue
+				}
+				top.from = true
+				expectRelation = true
+			case t.text == "join":
+				expectRelation = true
+			case fromClauseEnds[t.text]:
+				top.from = false
+			}
+
+		case tokQuotedIdent:
+			if i+1 < len(tokens) && tokens[i+1].is(tokPunct, "(") && v.deniedFunctions[strings.ToLower(t.text)] {
+				return &Violation{Rule: RuleFunction, Message: fmt.Sprintf("function %s is not allowed", t.text), Offset: t.offset}
+			}
+		}
+	}
+
+	if expectRelation {
+		return &Violation{Rule: RuleSyntax, Message: "missing relation", Offset: len(query)}
+	}
+	return nil
+}
+
+// qualifiedName returns the lower cased dotted name starting at tokens[i] and
+// the index of its last token.
+func qualifiedName(tokens []token, i int) (string, int) {
+	parts := []string{strings.ToLower(tokens[i].text)}
+	for i+2 < len(tokens) && tokens[i+1].is(tokPunct, ".") &&
+		(tokens[i+2].kind == tokIdent || tokens[i+2].kind == tokQuotedIdent) {
+		parts = append(parts, strings.ToLower(tokens[i+2].text))
+		i += 2
+	}
+	return strings.Join(parts, "."), i
+}
+
+// cteNames returns the names of the common table expressions the WITH
+// clauses of the query define, as `name AS (`, `name AS [NOT] MATERIALIZED (`
+// or `name (columns) AS (` separated by commas. Other `name AS (`, like named
+// windows, don't define relations.
+func cteNames(tokens []token) map[string]bool {
+	names := map[string]bool{}
+	for i, t := range tokens {
+		if !t.keyword("with") || i > 0 && tokens[i-1].is(tokPunct, ".") {
+			continue
+		}
+
+		j := i + 1
+		if j < len(tokens) && tokens[j].keyword("recursive") {
+			j++
+		}
+		for j < len(tokens) && (tokens[j].kind == tokIdent || tokens[j].kind == tokQuotedIdent) {
+			name := strings.ToLower(tokens[j].text)
+			j++
+			if j < len(tokens) && tokens[j].is(tokPunct, "(") {
+				// skip the column list
+				j = closingParen(tokens, j) + 1
+			}
+
+			if j >= len(tokens) || !tokens[j].keyword("as") {
+				break
+			}
+			j++
+			if j < len(tokens) && tokens[j].keyword("not") {
+				j++
+			}
+			if j < len(tokens) && tokens[j].keyword("materialized") {
+				j++
+			}
+			if j >= len(tokens) || !tokens[j].is(tokPunct, "(") {
+				break
+			}
+			names[name] = true
+
+			// the next CTE follows the query of this one
+			j = closingParen(tokens, j) + 1
+			if j >= len(tokens) || !tokens[j].is(tokPunct, ",") {
+				break
+			}
+			j++
+		}
+	}
+	return names
+}
+
+// closingParen returns the index of the parenthesis closing tokens[open], the
+// last index when it is not closed.
+func closingParen(tokens []token, open int) int {
+	depth := 0
+	for i := open; i < len(tokens); i++ {
+		switch {
+		case tokens[i].is(tokPunct, "("):
+			depth++
+		case tokens[i].is(tokPunct, ")"):
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(tokens) - 1
+}
+
+// position converts a byte offset to a line and a column, both starting at 1.
+func position(query string, offset int) (int, int) {
+	if offset > len(query) {
+		offset = len(query)
+	}
+
+	line, column := 1, 1
+	for _, r := range query[:offset] {
+		if r == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
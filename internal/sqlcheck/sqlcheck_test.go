@@ -0,0 +1,163 @@
+package sqlcheck
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestValidator() *Validator {
+	return NewValidator(Rules{
+		MaxLength:             200,
+		AllowedRelations:      []string{"loadtest", "main.Other"},
+		AllowedTableFunctions: []string{"range", "unnest"},
+		DeniedFunctions:       []string{"getenv", "current_setting"},
+	})
+}
+
+func TestValidate(t *testing.T) {
+	v := newTestValidator()
+
+	tests := []struct {
+		name  string
+		query string
+		rule  Rule // empty when valid
+	}{
+		{"select", "SELECT * FROM loadtest", ""},
+		{"terminated", "SELECT * FROM loadtest;;", ""},
+		{"from first", "FROM loadtest SELECT a", ""},
+		{"values", "VALUES (1), (2)", ""},
+		{"qualified", `SELECT * FROM main."other"`, ""},
+		{"expressions", "SELECT a IS DISTINCT FROM b, [1, 2], {'k': 1} FROM loadtest", ""},
+		{"quoted keyword", `SELECT "drop" FROM loadtest`, ""},
+
+		{"joins", "SELECT * FROM loadtest a JOIN loadtest b USING (id), loadtest c", ""},
+		{"join", "SELECT * FROM loadtest JOIN secrets ON true", RuleRelation},
+		{"comma join", "SELECT * FROM loadtest, secrets", RuleRelation},
+		{"parenthesized join", "SELECT * FROM (loadtest CROSS JOIN loadtest)", ""},
+		{"parenthesized join first", "SELECT * FROM (secrets CROSS JOIN loadtest)", RuleRelation},
+		{"parenthesized join second", "SELECT * FROM (loadtest CROSS JOIN secrets)", RuleRelation},
+		{"nested parenthesized join", "SELECT * FROM ((secrets JOIN loadtest USING (id)))", RuleRelation},
+		{"parenthesized file", "SELECT * FROM ('/etc/passwd')", RuleFileAccess},
+		{"parenthesized table function", "SELECT * FROM (read_csv('/etc/passwd') CROSS JOIN loadtest)", RuleTableFunction},
+
+		{"subquery", "SELECT * FROM (SELECT a, b FROM loadtest) s", ""},
+		{"subquery relation", "SELECT * FROM (SELECT * FROM secrets)", RuleRelation},
+		{"subquery values", "SELECT * FROM (VALUES (1), (2)) v(n)", ""},
+		{"subquery from first", "SELECT * FROM (FROM secrets)", RuleRelation},
+		{"subquery join", "SELECT * FROM ((SELECT 1) JOIN secrets ON true)", RuleRelation},
+		{"scalar subquery", "SELECT (SELECT max(a) FROM secrets) FROM loadtest", RuleRelation},
+		{"in subquery", "SELECT * FROM loadtest WHERE a IN (SELECT a FROM secrets)", RuleRelation},
+		{"lateral", "SELECT * FROM loadtest, LATERAL (SELECT * FROM secrets)", RuleRelation},
+
+		{"cte", "WITH x AS (SELECT * FROM loadtest) SELECT * FROM x", ""},
+		{"cte columns", "WITH x(a) AS MATERIALIZED (SELECT 1) SELECT * FROM x JOIN loadtest ON true", ""},
+		{"cte relation", "WITH x AS (SELECT * FROM secrets) SELECT * FROM x", RuleRelation},
+		{"cte not a relation", "WITH x AS (SELECT 1) SELECT * FROM y", RuleRelation},
+		{"ctes", "WITH RECURSIVE x AS (SELECT (1)), y (a, b) AS NOT MATERIALIZED (SELECT * FROM x) SELECT * FROM y", ""},
+		{"cte in a subquery", "SELECT * FROM (WITH x AS (SELECT 1) SELECT * FROM x)", ""},
+		{"window named after a relation", "SELECT * FROM query_history WINDOW query_history AS (ORDER BY 1)", RuleRelation},
+		{"aliased window named after a relation", "SELECT * FROM query_history q WINDOW query_history AS ()", RuleRelation},
+		{"window", "SELECT sum(a) OVER w FROM loadtest WINDOW w AS (ORDER BY a)", ""},
+
+		{"table", "TABLE loadtest", ""},
+		{"table relation", "TABLE secrets", RuleRelation},
+		{"table subquery", "SELECT (TABLE query_history)", RuleRelation},
+		{"table in from", "SELECT * FROM (TABLE secrets)", RuleRelation},
+		{"table file", "SELECT (TABLE '/etc/passwd')", RuleFileAccess},
+
+		{"table function", "SELECT * FROM range(10)", ""},
+		{"table function joined", "SELECT * FROM loadtest, unnest([1, 2])", ""},
+		{"denied table function", "SELECT * FROM read_csv('/etc/passwd')", RuleTableFunction},
+		{"qualified table function", "SELECT * FROM main.range(10)", RuleTableFunction},
+		{"file", "SELECT * FROM '/etc/passwd'", RuleFileAccess},
+		{"joined file", "SELECT * FROM loadtest JOIN 'data.csv' USING (id)", RuleFileAccess},
+
+		{"denied function", "SELECT getenv('HOME') FROM loadtest", RuleFunction},
+		{"denied quoted function", `SELECT "GETENV"('HOME')`, RuleFunction},
+		{"denied statement", "DELETE FROM loadtest", RuleStatement},
+		{"denied keyword", "SELECT * FROM loadtest WHERE a = (COPY loadtest TO 'x')", RuleStatement},
+		{"not a query", "SHOW TABLES", RuleStatement},
+		{"multiple statements", "SELECT 1; DROP TABLE loadtest", RuleMultipleStatements},
+		{"empty", " ; ", RuleSyntax},
+		{"missing relation", "SELECT * FROM", RuleSyntax},
+		{"too long", "SELECT * FROM loadtest WHERE a = '" + string(make([]byte, 200)) + "'", RuleLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Validate(tt.query)
+			if tt.rule == "" {
+				if err != nil {
+					t.Fatalf("Validate(%q) = %v", tt.query, err)
+				}
+				return
+			}
+
+			var violation *Violation
+			if !errors.As(err, &violation) || violation.Rule != tt.rule {
+				t.Fatalf("Validate(%q) = %v, want a %s violation", tt.query, err, tt.rule)
+			}
+		})
+	}
+}
+
+func TestViolationPosition(t *testing.T) {
+	v := newTestValidator()
+	err := v.Validate("SELECT *\n  FROM loadtest\n  JOIN écrits ON true")
+
+	var violation *Violation
+	if !errors.As(err, &violation) {
+		t.Fatalf("Validate = %v, want a violation", err)
+	}
+	if violation.Offset != 32 || violation.Line != 3 || violation.Column != 8 {
+		t.Errorf("violation at offset %d, line %d, column %d, want 32, 3, 8", violation.Offset, violation.Line, violation.Column)
+	}
+
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("code %s, want INVALID_ARGUMENT", st.Code())
+	}
+	var info *errdetails.ErrorInfo
+	for _, detail := range st.Details() {
+		if d, ok := detail.(*errdetails.ErrorInfo); ok {
+			info = d
+		}
+	}
+	if info == nil || info.Reason != string(RuleRelation) || info.Metadata["line"] != "3" || info.Metadata["column"] != "8" {
+		t.Errorf("error info %v", info)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		query, want string
+	}{
+		{"SELECT  a,\n\"B\"  FROM t -- comment\nWHERE x = 'it''s' AND y > 1.5e3", `select a , "B" from t where x = ? and y > ?`},
+		{"select a, \"B\" from t /* other */ where x = $1 and y > ?", `select a , "B" from t where x = ? and y > ?`},
+		{"SELECT 'unterminated", "SELECT 'unterminated"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.query); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestTrimTerminator(t *testing.T) {
+	tests := []struct {
+		query, want string
+	}{
+		{"SELECT 1", "SELECT 1"},
+		{"SELECT 1 ;\n", "SELECT 1 "},
+		{"SELECT ';' -- ;\n;", "SELECT ';' -- ;\n"},
+		{"SELECT 'unterminated;", "SELECT 'unterminated;"},
+	}
+	for _, tt := range tests {
+		if got := TrimTerminator(tt.query); got != tt.want {
+			t.Errorf("TrimTerminator(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
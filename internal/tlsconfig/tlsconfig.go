@@ -0,0 +1,220 @@
+// Package tlsconfig builds the TLS configuration of the listeners from
+// certificate files, reloading them when they change on disk.
+package tlsconfig
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+type Config struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile enables mutual TLS, clients must present a certificate
+	// signed by one of the CAs it contains.
+	ClientCAFile string
+	// ReloadInterval is how often the files are checked for changes.
+	ReloadInterval time.Duration
+}
+
+// Reloader holds the current certificate and client CAs.
+type Reloader struct {
+	cfg Config
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	modTimes map[string]time.Time
+}
+
+func NewReloader(cfg Config) (*Reloader, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("both the certificate and the key file are required")
+	}
+
+	r := &Reloader{cfg: cfg}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// TLSConfig returns the server TLS configuration, every handshake using the
+// latest loaded certificate and client CAs. nextProtos are the protocols
+// negotiated with ALPN, like h2 for gRPC.
+func (r *Reloader) TLSConfig(nextProtos ...string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+				NextProtos:   nextProtos,
+			}
+			if r.clientCA != nil {
+				cfg.ClientCAs = r.clientCA
+				cfg.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return cfg, nil
+		},
+	}
+}
+
+// Watch reloads the files whenever their modification time changes, until the
+// context is cancelled. A file that fails to load keeps the previous
+// configuration in place.
+func (r *Reloader) Watch(ctx context.Context) {
+	interval := r.cfg.ReloadInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !r.changed() {
+			continue
+		}
+
+		if err := r.load(); err != nil {
+			slog.Error("error reloading TLS certificates, keeping the current ones", "err", err)
+			continue
+		}
+		slog.Info("reloaded TLS certificates")
+	}
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+	return files
+}
+
+func (r *Reloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			// the file may be in the middle of being replaced
+			continue
+		}
+		if !info.ModTime().Equal(r.modTimes[f]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Reloader) load() error {
+	modTimes := map[string]time.Time{}
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			return err
+		}
+		modTimes[f] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("loading key pair: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("reading client CA: %w", err)
+		}
+
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificate found in %s", r.cfg.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCA = pool
+	r.modTimes = modTimes
+	return nil
+}
+
+// ClientIdentity describes the verified certificate of a client.
+type ClientIdentity struct {
+	CommonName     string
+	Organization   []string
+	DNSNames       []string
+	EmailAddresses []string
+	URIs           []string
+	// Fingerprint is the hex encoded SHA-256 of the certificate.
+	Fingerprint string
+}
+
+func (id ClientIdentity) String() string {
+	if id.CommonName != "" {
+		return id.CommonName
+	}
+	if len(id.URIs) > 0 {
+		return id.URIs[0]
+	}
+	if len(id.DNSNames) > 0 {
+		return id.DNSNames[0]
+	}
+	return id.Fingerprint
+}
+
+// IdentityFromContext returns the identity of the client of a request, which
+// is only known when the client presented a certificate that was verified.
+func IdentityFromContext(ctx context.Context) (ClientIdentity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ClientIdentity{}, false
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return ClientIdentity{}, false
+	}
+
+	cert := info.State.VerifiedChains[0][0]
+	sum := sha256.Sum256(cert.Raw)
+	id := ClientIdentity{
+		CommonName:     cert.Subject.CommonName,
+		Organization:   cert.Subject.Organization,
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+		Fingerprint:    hex.EncodeToString(sum[:]),
+	}
+	for _, u := range cert.URIs {
+		id.URIs = append(id.URIs, u.String())
+	}
+	return id, true
+}
@@ -0,0 +1,345 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// testCA issues the certificates of the tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pool: pool,
+	}
+}
+
+// issue returns a certificate for localhost and its key, both PEM encoded.
+func (ca *testCA) issue(t *testing.T, commonName string) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"tests"}},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func (ca *testCA) clientCert(t *testing.T, commonName string) tls.Certificate {
+	t.Helper()
+
+	certPEM, keyPEM := ca.issue(t, commonName)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// writeFile writes a file, with a modification time that can't be confused
+// with the previous one.
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// serverFiles writes the certificate of the server, its key and the client
+// CA to a temporary directory.
+func serverFiles(t *testing.T, ca *testCA, commonName string) Config {
+	t.Helper()
+
+	dir := t.TempDir()
+	cfg := Config{
+		CertFile:     filepath.Join(dir, "cert.pem"),
+		KeyFile:      filepath.Join(dir, "key.pem"),
+		ClientCAFile: filepath.Join(dir, "ca.pem"),
+	}
+	certPEM, keyPEM := ca.issue(t, commonName)
+	modTime := time.Now().Add(-time.Minute)
+	writeFile(t, cfg.CertFile, certPEM, modTime)
+	writeFile(t, cfg.KeyFile, keyPEM, modTime)
+	writeFile(t, cfg.ClientCAFile, ca.pem, modTime)
+	return cfg
+}
+
+// handshake connects a client to a server over TCP, returning the state of
+// the server side of the connection.
+func handshake(t *testing.T, server, client *tls.Config) (tls.ConnectionState, tls.ConnectionState, error) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+
+	type result struct {
+		state tls.ConnectionState
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		defer conn.Close()
+
+		tlsConn := tls.Server(conn, server)
+		err = tlsConn.Handshake()
+		done <- result{state: tlsConn.ConnectionState(), err: err}
+	}()
+
+	conn, err := tls.Dial("tcp", lis.Addr().String(), client)
+	if err != nil {
+		<-done
+		return tls.ConnectionState{}, tls.ConnectionState{}, err
+	}
+	defer conn.Close()
+
+	res := <-done
+	return res.state, conn.ConnectionState(), res.err
+}
+
+func TestNewReloaderErrors(t *testing.T) {
+	ca := newTestCA(t)
+	valid := serverFiles(t, ca, "server")
+
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"no files", Config{}},
+		{"no key", Config{CertFile: valid.CertFile}},
+		{"missing cert", Config{CertFile: valid.CertFile + ".missing", KeyFile: valid.KeyFile}},
+		{"key as cert", Config{CertFile: valid.KeyFile, KeyFile: valid.KeyFile}},
+		{"missing client CA", Config{CertFile: valid.CertFile, KeyFile: valid.KeyFile, ClientCAFile: valid.ClientCAFile + ".missing"}},
+		{"no CA in client CA", Config{CertFile: valid.CertFile, KeyFile: valid.KeyFile, ClientCAFile: valid.KeyFile}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewReloader(tt.cfg); err == nil {
+				t.Fatal("NewReloader succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestHandshake(t *testing.T) {
+	ca := newTestCA(t)
+	cfg := serverFiles(t, ca, "server")
+	cfg.ClientCAFile = ""
+
+	r, err := NewReloader(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, client, err := handshake(t, r.TLSConfig("h2"), &tls.Config{
+		RootCAs:    ca.pool,
+		ServerName: "localhost",
+		NextProtos: []string{"h2"},
+	})
+	if err != nil {
+		t.Fatalf("handshake: %v", err)
+	}
+	if got := client.PeerCertificates[0].Subject.CommonName; got != "server" {
+		t.Errorf("server certificate %q, want %q", got, "server")
+	}
+	if client.NegotiatedProtocol != "h2" {
+		t.Errorf("negotiated protocol %q, want h2", client.NegotiatedProtocol)
+	}
+
+	// an old client
+	_, _, err = handshake(t, r.TLSConfig("h2"), &tls.Config{
+		RootCAs:    ca.pool,
+		ServerName: "localhost",
+		MaxVersion: tls.VersionTLS11,
+	})
+	if err == nil {
+		t.Error("TLS 1.1 handshake succeeded")
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	r, err := NewReloader(serverFiles(t, ca, "server"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientConfig := func(certs ...tls.Certificate) *tls.Config {
+		return &tls.Config{RootCAs: ca.pool, ServerName: "localhost", Certificates: certs}
+	}
+
+	t.Run("no client certificate", func(t *testing.T) {
+		if _, _, err := handshake(t, r.TLSConfig("h2"), clientConfig()); err == nil {
+			t.Fatal("handshake succeeded without a client certificate")
+		}
+	})
+
+	t.Run("certificate of another CA", func(t *testing.T) {
+		other := newTestCA(t)
+		if _, _, err := handshake(t, r.TLSConfig("h2"), clientConfig(other.clientCert(t, "intruder"))); err == nil {
+			t.Fatal("handshake succeeded with a certificate of another CA")
+		}
+	})
+
+	t.Run("valid certificate", func(t *testing.T) {
+		server, _, err := handshake(t, r.TLSConfig("h2"), clientConfig(ca.clientCert(t, "client")))
+		if err != nil {
+			t.Fatalf("handshake: %v", err)
+		}
+
+		ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: server}})
+		id, ok := IdentityFromContext(ctx)
+		if !ok {
+			t.Fatal("no identity for a verified client certificate")
+		}
+		sum := sha256.Sum256(server.PeerCertificates[0].Raw)
+		if id.CommonName != "client" || id.String() != "client" || id.Fingerprint != hex.EncodeToString(sum[:]) {
+			t.Errorf("identity %+v", id)
+		}
+		if len(id.Organization) != 1 || id.Organization[0] != "tests" || len(id.DNSNames) != 1 || id.DNSNames[0] != "localhost" {
+			t.Errorf("identity %+v", id)
+		}
+	})
+}
+
+func TestIdentityWithoutVerifiedCertificate(t *testing.T) {
+	if _, ok := IdentityFromContext(context.Background()); ok {
+		t.Error("identity without a peer")
+	}
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{}})
+	if _, ok := IdentityFromContext(ctx); ok {
+		t.Error("identity without a verified certificate")
+	}
+}
+
+func TestWatchReloadsCertificate(t *testing.T) {
+	ca := newTestCA(t)
+	cfg := serverFiles(t, ca, "v1")
+	cfg.ReloadInterval = 10 * time.Millisecond
+
+	r, err := NewReloader(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Watch(ctx)
+
+	serverName := func() string {
+		t.Helper()
+		_, client, err := handshake(t, r.TLSConfig("h2"), &tls.Config{
+			RootCAs:      ca.pool,
+			ServerName:   "localhost",
+			Certificates: []tls.Certificate{ca.clientCert(t, "client")},
+		})
+		if err != nil {
+			t.Fatalf("handshake: %v", err)
+		}
+		return client.PeerCertificates[0].Subject.CommonName
+	}
+
+	// a broken certificate keeps the current one
+	writeFile(t, cfg.CertFile, []byte("not a certificate"), time.Now())
+	time.Sleep(10 * cfg.ReloadInterval)
+	if got := serverName(); got != "v1" {
+		t.Fatalf("server certificate %q after a broken update, want v1", got)
+	}
+
+	certPEM, keyPEM := ca.issue(t, "v2")
+	modTime := time.Now().Add(time.Minute)
+	writeFile(t, cfg.KeyFile, keyPEM, modTime)
+	writeFile(t, cfg.CertFile, certPEM, modTime)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for serverName() != "v2" {
+		if time.Now().After(deadline) {
+			t.Fatal("the new certificate was not loaded")
+		}
+		time.Sleep(cfg.ReloadInterval)
+	}
+}
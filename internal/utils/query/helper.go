@@ -7,11 +7,10 @@ import (
 	pb "duckdb-server/internal/services/grpc/data_transform"
 	"errors"
 	"fmt"
-	"io"
-	"log"
-	"net/http"
+	"log/slog"
+	"slices"
 
-	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow-go/v18/arrow"
 )
 
 type ArrowQueryOut struct {
@@ -40,7 +39,7 @@ func ArrowTransform(qb *querybuilder.DuckDBArrowQueryBuilder, query string) (*Ar
 
 	rows, err := qb.Query(ctx, query)
 	if err != nil {
-		log.Printf("Error querying data, err: %s\n", err.Error())
+		slog.Error("error querying data", "err", err)
 		return nil, err
 	}
 
@@ -85,14 +84,14 @@ func Transform(qb *querybuilder.DuckDBQueryBuilder, query string) (*pb.QueryOut,
 
 	rows, err := qb.Query(query)
 	if err != nil {
-		log.Printf("Error querying data, err: %s\n", err.Error())
+		slog.Error("error querying data", "err", err)
 		return nil, err
 	}
 
 	// Get column names
 	columns, err := rows.Columns()
 	if err != nil {
-		log.Printf("Error getting col details, err: %s\n", err.Error())
+		slog.Error("error getting col details", "err", err)
 		return nil, err
 	}
 
@@ -107,10 +106,10 @@ func Transform(qb *querybuilder.DuckDBQueryBuilder, query string) (*pb.QueryOut,
 	for rows.Next() {
 		err = rows.Scan(values...)
 		if errors.Is(err, sql.ErrNoRows) {
-			log.Println("no rows")
+			slog.Debug("no rows")
 			break
 		} else if err != nil {
-			log.Printf("error scanning record, err: %v\n", err)
+			slog.Error("error scanning record", "err", err)
 			return nil, err
 		}
 
@@ -125,152 +124,46 @@ func Transform(qb *querybuilder.DuckDBQueryBuilder, query string) (*pb.QueryOut,
 	return &queryOut, nil
 }
 
-func CreateView(viewName, tableName string) string {
-	return fmt.Sprintf(`create or replace view %s as WITH 
-    cte_2_210717_0 AS (SELECT * FROM %s), 
-    cte_2_210717_1 AS (select 
-        "Customer Id", "First Name", "Last Name", Company, City, 
-        Country, "Phone 1", "Phone 2", Email, Website,  
-          "Subscription Date", Index from cte_2_210717_0
-    ), cte_2_210717_2 AS (
-        select 
-            CASE WHEN "Customer Id" IS NULL THEN '(blank)' ELSE  "Customer Id" END as "Customer Id", 
-            CASE WHEN "First Name" IS NULL THEN '(blank)' ELSE  "First Name" END as "First Name", 
-            CASE WHEN "Last Name" IS NULL THEN '(blank)' ELSE  "Last Name" END as "Last Name", 
-            CASE WHEN Company IS NULL THEN '(blank)' ELSE  Company END as Company, 
-            CASE WHEN City IS NULL THEN '(blank)' ELSE  City END as City, 
-            CASE WHEN Country IS NULL THEN '(blank)' ELSE  Country END as Country, 
-            CASE WHEN "Phone 1" IS NULL THEN '(blank)' ELSE  "Phone 1" END as "Phone 1", 
-            CASE WHEN "Phone 2" IS NULL THEN '(blank)' ELSE  "Phone 2" END as "Phone 2", 
-            CASE WHEN Email IS NULL THEN '(blank)' ELSE  Email END as Email, 
-            Website,    "Subscription Date", Index 
-        from cte_2_210717_1
-    ), cte_2_210717_3 AS (
-        select 
-            "Customer Id", "First Name", "Last Name", Company, City, 
-            Country, "Phone 1", "Phone 2", Email, Website,  
-              min("Subscription Date") as "Subscription Date", sum(Index) as Index 
-        from cte_2_210717_2 
-        group by GROUPING SETS (
-            ("Customer Id"),("Customer Id","First Name"),("Customer Id","First Name","Last Name"),
-            ("Customer Id","First Name","Last Name",Company),("Customer Id","First Name","Last Name",Company,City),
-            ("Customer Id","First Name","Last Name",Company,City,Country),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1"),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2"),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2",Email),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2",Email,Website),
-            ("Customer Id"),
-            ("Customer Id"),("Customer Id"),
-            ("Customer Id","First Name"),("Customer Id","First Name"),
-            ("Customer Id","First Name"),("Customer Id","First Name","Last Name"),
-            ("Customer Id","First Name","Last Name"),
-            ("Customer Id","First Name","Last Name"),(
-            "Customer Id","First Name","Last Name",Company),("Customer Id","First Name","Last Name",Company),
-            ("Customer Id","First Name","Last Name",Company),
-            ("Customer Id","First Name","Last Name",Company,City),
-            ("Customer Id","First Name","Last Name",Company,City),
-            ("Customer Id","First Name","Last Name",Company,City),
-            ("Customer Id","First Name","Last Name",Company,City,Country),
-            ("Customer Id","First Name","Last Name",Company,City,Country),
-            ("Customer Id","First Name","Last Name",Company,City,Country),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1"),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1"),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1"),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2"),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2"),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2"),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2",Email),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2",Email),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2",Email),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2",Email,Website),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2",Email,Website),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2",Email,Website),
-            ()
-        )
-    ) (select * from cte_2_210717_3);`, viewName, tableName)
+// customersSchema is the schema of the customers dataset used for load tests.
+var customersSchema = []querybuilder.Column{
+	{Name: "Index", Type: "BIGINT"},
+	{Name: "Customer Id", Type: "VARCHAR"},
+	{Name: "First Name", Type: "VARCHAR"},
+	{Name: "Last Name", Type: "VARCHAR"},
+	{Name: "Company", Type: "VARCHAR"},
+	{Name: "City", Type: "VARCHAR"},
+	{Name: "Country", Type: "VARCHAR"},
+	{Name: "Phone 1", Type: "VARCHAR"},
+	{Name: "Phone 2", Type: "VARCHAR"},
+	{Name: "Email", Type: "VARCHAR"},
+	{Name: "Subscription Date", Type: "DATE"},
+	{Name: "Website", Type: "VARCHAR"},
 }
 
-func CreateViewV2(viewName, query string) string {
-	return fmt.Sprintf(`create or replace view %s as %s;`, viewName, query)
-}
+// CustomersSubtotal is the load-test transformation over the customers
+// dataset: NULLs are shown as '(blank)' and the subscription date and index
+// are subtotalled over the customer hierarchy.
+func CustomersSubtotal() querybuilder.Pipeline {
+	dimensions := []string{
+		"Customer Id", "First Name", "Last Name", "Company", "City",
+		"Country", "Phone 1", "Phone 2", "Email", "Website",
+	}
 
-func getQuery(tableName string) string {
-	return fmt.Sprintf(`select * from ( WITH 
-    cte_2_210717_0 AS (SELECT * FROM %s), 
-    cte_2_210717_1 AS (select 
-        "Customer Id", "First Name", "Last Name", Company, City, 
-        Country, "Phone 1", "Phone 2", Email, Website,  
-          "Subscription Date", Index from cte_2_210717_0
-    ), cte_2_210717_2 AS (
-        select 
-            CASE WHEN "Customer Id" IS NULL THEN '(blank)' ELSE  "Customer Id" END as "Customer Id", 
-            CASE WHEN "First Name" IS NULL THEN '(blank)' ELSE  "First Name" END as "First Name", 
-            CASE WHEN "Last Name" IS NULL THEN '(blank)' ELSE  "Last Name" END as "Last Name", 
-            CASE WHEN Company IS NULL THEN '(blank)' ELSE  Company END as Company, 
-            CASE WHEN City IS NULL THEN '(blank)' ELSE  City END as City, 
-            CASE WHEN Country IS NULL THEN '(blank)' ELSE  Country END as Country, 
-            CASE WHEN "Phone 1" IS NULL THEN '(blank)' ELSE  "Phone 1" END as "Phone 1", 
-            CASE WHEN "Phone 2" IS NULL THEN '(blank)' ELSE  "Phone 2" END as "Phone 2", 
-            CASE WHEN Email IS NULL THEN '(blank)' ELSE  Email END as Email, 
-            Website,    "Subscription Date", Index 
-        from cte_2_210717_1
-    ), cte_2_210717_3 AS (
-        select 
-            "Customer Id", "First Name", "Last Name", Company, City, 
-            Country, "Phone 1", "Phone 2", Email, Website,  
-              min("Subscription Date") as "Subscription Date", sum(Index) as Index 
-        from cte_2_210717_2 
-        group by GROUPING SETS (
-            ("Customer Id"),("Customer Id","First Name"),("Customer Id","First Name","Last Name"),
-            ("Customer Id","First Name","Last Name",Company),("Customer Id","First Name","Last Name",Company,City),
-            ("Customer Id","First Name","Last Name",Company,City,Country),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1"),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2"),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2",Email),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2",Email,Website),
-            ("Customer Id"),
-            ("Customer Id"),("Customer Id"),
-            ("Customer Id","First Name"),("Customer Id","First Name"),
-            ("Customer Id","First Name"),("Customer Id","First Name","Last Name"),
-            ("Customer Id","First Name","Last Name"),
-            ("Customer Id","First Name","Last Name"),(
-            "Customer Id","First Name","Last Name",Company),("Customer Id","First Name","Last Name",Company),
-            ("Customer Id","First Name","Last Name",Company),
-            ("Customer Id","First Name","Last Name",Company,City),
-            ("Customer Id","First Name","Last Name",Company,City),
-            ("Customer Id","First Name","Last Name",Company,City),
-            ("Customer Id","First Name","Last Name",Company,City,Country),
-            ("Customer Id","First Name","Last Name",Company,City,Country),
-            ("Customer Id","First Name","Last Name",Company,City,Country),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1"),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1"),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1"),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2"),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2"),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2"),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2",Email),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2",Email),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2",Email),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2",Email,Website),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2",Email,Website),
-            ("Customer Id","First Name","Last Name",Company,City,Country,"Phone 1","Phone 2",Email,Website),
-            ()
-        ) order by 
-        "Customer Id" NULLS FIRST,"First Name" NULLS FIRST,"Last Name" NULLS FIRST,
-        Company NULLS FIRST,City NULLS FIRST,Country NULLS FIRST,"Phone 1" NULLS FIRST,
-        "Phone 2" NULLS FIRST,Email NULLS FIRST,Website NULLS FIRST,
-        "Subscription Date" ASC
-    ) (select * from cte_2_210717_3));`, tableName)
+	return querybuilder.Pipeline{Steps: []querybuilder.Step{
+		{Select: &querybuilder.SelectStep{Columns: append(slices.Clone(dimensions), "Subscription Date", "Index")}},
+		{FillNulls: &querybuilder.FillNullsStep{Columns: dimensions[:9], Value: "(blank)"}},
+		{Subtotal: &querybuilder.SubtotalStep{
+			Dimensions: dimensions,
+			Measures: []querybuilder.Measure{
+				{Column: "Subscription Date", Function: "min"},
+				{Column: "Index", Function: "sum"},
+			},
+		}},
+	}}
 }
 
-func DownloadFile(url string, out io.Writer) error {
-    log.Printf("Downloading file: %s", url)
-    res, err := http.Get(url)
-    if err!= nil {
-        return err
-    }
-
-    defer res.Body.Close()
-    _, err = io.Copy(out, res.Body)
-    return err
-}
\ No newline at end of file
+// CustomersQuery returns the load-test transformation over the customers
+// table.
+func CustomersQuery(tableName string) (string, error) {
+	return CustomersSubtotal().Compile(tableName, customersSchema)
+}
@@ -0,0 +1,416 @@
+package query
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	pb "duckdb-server/internal/services/grpc_arrow/data_transform"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+)
+
+// maxSafeInteger is the largest integer a JavaScript number can represent
+// exactly, 64-bit values beyond it are emitted as strings.
+const maxSafeInteger = 1<<53 - 1
+
+// JSONChunker turns an arrow record stream into JSON rows and groups them into
+// chunks of at most a given number of bytes. A row is never split across
+// chunks, so a single row larger than the limit is sent in a chunk of its own.
+type JSONChunker struct {
+	rows   array.RecordReader
+	format pb.JSONFormat
+	// decimalsAsNumber emits DECIMAL values as JSON numbers instead of strings.
+	decimalsAsNumber bool
+
+	record  arrow.Record
+	offset  int
+	written int64
+	started bool
+	done    bool
+
+	row  bytes.Buffer
+	locs map[string]*time.Location
+}
+
+// NewJSONChunker returns a chunker of the rows, NDJSON with DECIMAL values
+// as strings unless the options say otherwise.
+func NewJSONChunker(rows array.RecordReader, opts *pb.JSONOptions) *JSONChunker {
+	format := opts.GetFormat()
+	if format == pb.JSONFormat_JSON_FORMAT_UNSPECIFIED {
+		format = pb.JSONFormat_NDJSON
+	}
+	return &JSONChunker{
+		rows:             rows,
+		format:           format,
+		decimalsAsNumber: opts.GetDecimals() == pb.DecimalEncoding_DECIMAL_AS_NUMBER,
+		locs:             map[string]*time.Location{},
+	}
+}
+
+// Next returns the next chunk of at most maxBytes bytes, or io.EOF once all
+// the rows have been sent.
+func (c *JSONChunker) Next(maxBytes int) (*pb.QueryOut, error) {
+	if c.done {
+		return nil, io.EOF
+	}
+
+	var (
+		chunk bytes.Buffer
+		count int32
+	)
+
+	if !c.started {
+		c.started = true
+		if c.format == pb.JSONFormat_ARRAY {
+			chunk.WriteByte('[')
+		}
+	}
+
+	for {
+		if c.record == nil || c.offset >= int(c.record.NumRows()) {
+			if c.record != nil {
+				c.record.Release()
+				c.record = nil
+			}
+
+			if !c.rows.Next() {
+				if err := c.rows.Err(); err != nil {
+					return nil, err
+				}
+
+				c.done = true
+				if c.format == pb.JSONFormat_ARRAY {
+					chunk.WriteByte(']')
+				}
+				break
+			}
+
+			c.record = c.rows.Record()
+			c.record.Retain()
+			c.offset = 0
+			continue
+		}
+
+		c.row.Reset()
+		if err := c.appendRow(&c.row, c.record, c.offset); err != nil {
+			return nil, err
+		}
+
+		// the row is followed by a newline with NDJSON, and with ARRAY
+		// preceded by a comma unless first and maybe followed by the closing
+		// bracket
+		extra := 1
+		if c.format == pb.JSONFormat_ARRAY && c.written > 0 {
+			extra = 2
+		}
+		if count > 0 && chunk.Len()+c.row.Len()+extra > maxBytes {
+			break
+		}
+
+		if c.format == pb.JSONFormat_ARRAY {
+			if c.written > 0 {
+				chunk.WriteByte(',')
+			}
+			chunk.Write(c.row.Bytes())
+		} else {
+			chunk.Write(c.row.Bytes())
+			chunk.WriteByte('\n')
+		}
+
+		c.offset += 1
+		c.written += 1
+		count += 1
+	}
+
+	if count == 0 && chunk.Len() == 0 {
+		return nil, io.EOF
+	}
+
+	return &pb.QueryOut{
+		Count: count,
+		Data:  [][]byte{chunk.Bytes()},
+	}, nil
+}
+
+// Release frees the record the chunker is currently positioned on.
+func (c *JSONChunker) Release() {
+	if c.record != nil {
+		c.record.Release()
+		c.record = nil
+	}
+}
+
+func (c *JSONChunker) appendRow(buf *bytes.Buffer, record arrow.Record, i int) error {
+	buf.WriteByte('{')
+	for col := 0; col < int(record.NumCols()); col++ {
+		if col > 0 {
+			buf.WriteByte(',')
+		}
+
+		appendString(buf, record.ColumnName(col))
+		buf.WriteByte(':')
+		if err := c.appendValue(buf, record.Column(col), i); err != nil {
+			return fmt.Errorf("column %q: %w", record.ColumnName(col), err)
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+func (c *JSONChunker) appendValue(buf *bytes.Buffer, arr arrow.Array, i int) error {
+	if arr.IsNull(i) {
+		buf.WriteString("null")
+		return nil
+	}
+
+	switch a := arr.(type) {
+	case *array.Null:
+		buf.WriteString("null")
+	case *array.Boolean:
+		buf.WriteString(strconv.FormatBool(a.Value(i)))
+	case *array.Int8:
+		buf.WriteString(strconv.FormatInt(int64(a.Value(i)), 10))
+	case *array.Int16:
+		buf.WriteString(strconv.FormatInt(int64(a.Value(i)), 10))
+	case *array.Int32:
+		buf.WriteString(strconv.FormatInt(int64(a.Value(i)), 10))
+	case *array.Int64:
+		v := a.Value(i)
+		if v > maxSafeInteger || v < -maxSafeInteger {
+			appendString(buf, strconv.FormatInt(v, 10))
+		} else {
+			buf.WriteString(strconv.FormatInt(v, 10))
+		}
+	case *array.Uint8:
+		buf.WriteString(strconv.FormatUint(uint64(a.Value(i)), 10))
+	case *array.Uint16:
+		buf.WriteString(strconv.FormatUint(uint64(a.Value(i)), 10))
+	case *array.Uint32:
+		buf.WriteString(strconv.FormatUint(uint64(a.Value(i)), 10))
+	case *array.Uint64:
+		v := a.Value(i)
+		if v > maxSafeInteger {
+			appendString(buf, strconv.FormatUint(v, 10))
+		} else {
+			buf.WriteString(strconv.FormatUint(v, 10))
+		}
+	case *array.Float16:
+		appendFloat(buf, float64(a.Value(i).Float32()), 32)
+	case *array.Float32:
+		appendFloat(buf, float64(a.Value(i)), 32)
+	case *array.Float64:
+		appendFloat(buf, a.Value(i), 64)
+	case *array.Decimal128:
+		c.appendDecimal(buf, a.Value(i).ToString(a.DataType().(*arrow.Decimal128Type).Scale))
+	case *array.Decimal256:
+		c.appendDecimal(buf, a.Value(i).ToString(a.DataType().(*arrow.Decimal256Type).Scale))
+	case *array.String:
+		appendString(buf, a.Value(i))
+	case *array.LargeString:
+		appendString(buf, a.Value(i))
+	case *array.Binary:
+		appendString(buf, base64.StdEncoding.EncodeToString(a.Value(i)))
+	case *array.LargeBinary:
+		appendString(buf, base64.StdEncoding.EncodeToString(a.Value(i)))
+	case *array.FixedSizeBinary:
+		appendString(buf, base64.StdEncoding.EncodeToString(a.Value(i)))
+	case *array.Date32:
+		appendString(buf, a.Value(i).ToTime().Format(time.DateOnly))
+	case *array.Date64:
+		appendString(buf, a.Value(i).ToTime().Format(time.DateOnly))
+	case *array.Time32:
+		unit := a.DataType().(*arrow.Time32Type).Unit
+		appendString(buf, a.Value(i).ToTime(unit).Format("15:04:05.999999999"))
+	case *array.Time64:
+		unit := a.DataType().(*arrow.Time64Type).Unit
+		appendString(buf, a.Value(i).ToTime(unit).Format("15:04:05.999999999"))
+	case *array.Timestamp:
+		ts, err := c.timestamp(a, i)
+		if err != nil {
+			return err
+		}
+		appendString(buf, ts.Format(time.RFC3339Nano))
+	case *array.Duration:
+		unit := a.DataType().(*arrow.DurationType).Unit
+		appendString(buf, (time.Duration(a.Value(i)) * unit.Multiplier()).String())
+	case *array.MonthDayNanoInterval:
+		v := a.Value(i)
+		fmt.Fprintf(buf, `{"months":%d,"days":%d,"nanoseconds":%d}`, v.Months, v.Days, v.Nanoseconds)
+	case *array.Dictionary:
+		return c.appendValue(buf, a.Dictionary(), a.GetValueIndex(i))
+	case *array.Map:
+		return c.appendMap(buf, a, i)
+	case array.ListLike:
+		start, end := a.ValueOffsets(i)
+		values := a.ListValues()
+		buf.WriteByte('[')
+		for j := start; j < end; j++ {
+			if j > start {
+				buf.WriteByte(',')
+			}
+			if err := c.appendValue(buf, values, int(j)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case *array.Struct:
+		st := a.DataType().(*arrow.StructType)
+		buf.WriteByte('{')
+		for f := 0; f < a.NumField(); f++ {
+			if f > 0 {
+				buf.WriteByte(',')
+			}
+			appendString(buf, st.Field(f).Name)
+			buf.WriteByte(':')
+			if err := c.appendValue(buf, a.Field(f), i); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported arrow type %s", arr.DataType())
+	}
+
+	return nil
+}
+
+// appendMap writes maps with string keys as JSON objects and every other map
+// as a list of key/value objects.
+func (c *JSONChunker) appendMap(buf *bytes.Buffer, a *array.Map, i int) error {
+	start, end := a.ValueOffsets(i)
+	keys, items := a.Keys(), a.Items()
+
+	_, stringKeys := keys.(*array.String)
+	if stringKeys {
+		buf.WriteByte('{')
+	} else {
+		buf.WriteByte('[')
+	}
+
+	for j := int(start); j < int(end); j++ {
+		if j > int(start) {
+			buf.WriteByte(',')
+		}
+
+		if stringKeys {
+			appendString(buf, keys.(*array.String).Value(j))
+			buf.WriteByte(':')
+			if err := c.appendValue(buf, items, j); err != nil {
+				return err
+			}
+			continue
+		}
+
+		buf.WriteString(`{"key":`)
+		if err := c.appendValue(buf, keys, j); err != nil {
+			return err
+		}
+		buf.WriteString(`,"value":`)
+		if err := c.appendValue(buf, items, j); err != nil {
+			return err
+		}
+		buf.WriteByte('}')
+	}
+
+	if stringKeys {
+		buf.WriteByte('}')
+	} else {
+		buf.WriteByte(']')
+	}
+	return nil
+}
+
+func (c *JSONChunker) appendDecimal(buf *bytes.Buffer, v string) {
+	if c.decimalsAsNumber {
+		buf.WriteString(v)
+		return
+	}
+	appendString(buf, v)
+}
+
+// timestamp converts the value to a time in the zone of the column, timestamps
+// without a zone are treated as UTC.
+func (c *JSONChunker) timestamp(a *array.Timestamp, i int) (time.Time, error) {
+	typ := a.DataType().(*arrow.TimestampType)
+	t := a.Value(i).ToTime(typ.Unit)
+
+	if typ.TimeZone == "" {
+		return t.UTC(), nil
+	}
+
+	loc, ok := c.locs[typ.TimeZone]
+	if !ok {
+		var err error
+		loc, err = time.LoadLocation(typ.TimeZone)
+		if err != nil {
+			return time.Time{}, err
+		}
+		c.locs[typ.TimeZone] = loc
+	}
+
+	return t.In(loc), nil
+}
+
+func appendFloat(buf *bytes.Buffer, v float64, bitSize int) {
+	// NaN and infinities have no JSON representation
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		appendString(buf, strconv.FormatFloat(v, 'g', -1, bitSize))
+		return
+	}
+	buf.WriteString(strconv.FormatFloat(v, 'g', -1, bitSize))
+}
+
+const hex = "0123456789abcdef"
+
+func appendString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+
+			buf.WriteString(s[start:i])
+			switch b {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(b)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[b>>4])
+				buf.WriteByte(hex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`�`)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+}
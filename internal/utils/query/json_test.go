@@ -0,0 +1,275 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	pb "duckdb-server/internal/services/grpc_arrow/data_transform"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+var rowsSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+}, nil)
+
+// newRecords builds a reader over one record per JSON array of rows.
+func newRecords(t *testing.T, mem memory.Allocator, schema *arrow.Schema, batches ...string) array.RecordReader {
+	t.Helper()
+
+	records := make([]arrow.Record, 0, len(batches))
+	for _, batch := range batches {
+		record, _, err := array.RecordFromJSON(mem, schema, strings.NewReader(batch))
+		if err != nil {
+			t.Fatalf("RecordFromJSON(%s): %v", batch, err)
+		}
+		defer record.Release()
+		records = append(records, record)
+	}
+
+	reader, err := array.NewRecordReader(schema, records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reader
+}
+
+// chunkAll reads every chunk of a chunker.
+func chunkAll(t *testing.T, rows array.RecordReader, opts *pb.JSONOptions, maxBytes int) []*pb.QueryOut {
+	t.Helper()
+
+	chunker := NewJSONChunker(rows, opts)
+	defer chunker.Release()
+
+	var chunks []*pb.QueryOut
+	for {
+		chunk, err := chunker.Next(maxBytes)
+		if errors.Is(err, io.EOF) {
+			return chunks
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		chunks = append(chunks, chunk)
+	}
+}
+
+func join(chunks []*pb.QueryOut) (string, int32) {
+	var (
+		data  bytes.Buffer
+		count int32
+	)
+	for _, chunk := range chunks {
+		for _, part := range chunk.Data {
+			data.Write(part)
+		}
+		count += chunk.Count
+	}
+	return data.String(), count
+}
+
+func TestJSONChunkerNDJSON(t *testing.T) {
+	const (
+		row0 = `{"id":0,"name":"a"}`
+		row1 = `{"id":1,"name":null}`
+		row2 = `{"id":2,"name":"a much longer name than the others"}`
+		row3 = `{"id":3,"name":"b"}`
+	)
+
+	tests := []struct {
+		name     string
+		maxBytes int
+		chunks   []string
+	}{
+		{"one chunk", 1 << 20, []string{row0 + "\n" + row1 + "\n" + row2 + "\n" + row3 + "\n"}},
+		{"one row per chunk", 1, []string{row0 + "\n", row1 + "\n", row2 + "\n", row3 + "\n"}},
+		{"rows are not split", len(row0) + len(row1) + 2, []string{row0 + "\n" + row1 + "\n", row2 + "\n", row3 + "\n"}},
+		{"a chunk ends before an oversized row", len(row0) + len(row1) + 3, []string{row0 + "\n" + row1 + "\n", row2 + "\n", row3 + "\n"}},
+	}
+	for _, tt := range tests {
+		// NDJSON is the default
+		for _, format := range []pb.JSONFormat{pb.JSONFormat_NDJSON, pb.JSONFormat_JSON_FORMAT_UNSPECIFIED} {
+			t.Run(tt.name+"/"+format.String(), func(t *testing.T) {
+				mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+				defer mem.AssertSize(t, 0)
+
+				rows := newRecords(t, mem, rowsSchema,
+					`[{"id":0,"name":"a"},{"id":1,"name":null}]`,
+					`[]`,
+					`[{"id":2,"name":"a much longer name than the others"},{"id":3,"name":"b"}]`,
+				)
+				defer rows.Release()
+
+				chunks := chunkAll(t, rows, &pb.JSONOptions{Format: format}, tt.maxBytes)
+				if len(chunks) != len(tt.chunks) {
+					t.Fatalf("%d chunks, want %d", len(chunks), len(tt.chunks))
+				}
+				for i, chunk := range chunks {
+					if got := string(chunk.Data[0]); got != tt.chunks[i] {
+						t.Errorf("chunk %d = %q, want %q", i, got, tt.chunks[i])
+					}
+					if want := int32(strings.Count(tt.chunks[i], "\n")); chunk.Count != want {
+						t.Errorf("chunk %d has a count of %d, want %d", i, chunk.Count, want)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestJSONChunkerArray(t *testing.T) {
+	tests := []struct {
+		name    string
+		batches []string
+		want    string
+		count   int32
+	}{
+		{"no rows", nil, `[]`, 0},
+		{"empty records", []string{`[]`, `[]`}, `[]`, 0},
+		{"rows", []string{`[{"id":1,"name":"a"}]`, `[{"id":2,"name":"b"},{"id":3,"name":null}]`},
+			`[{"id":1,"name":"a"},{"id":2,"name":"b"},{"id":3,"name":null}]`, 3},
+	}
+	for _, tt := range tests {
+		// with 61 bytes the rows fit exactly, but not the closing bracket
+		for _, maxBytes := range []int{1, 30, 61, 1 << 20} {
+			mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+			rows := newRecords(t, mem, rowsSchema, tt.batches...)
+
+			chunks := chunkAll(t, rows, &pb.JSONOptions{Format: pb.JSONFormat_ARRAY}, maxBytes)
+			rows.Release()
+			mem.AssertSize(t, 0)
+
+			got, count := join(chunks)
+			if got != tt.want || count != tt.count {
+				t.Errorf("%s, %d bytes: %s with a count of %d, want %s with a count of %d", tt.name, maxBytes, got, count, tt.want, tt.count)
+			}
+			if !json.Valid([]byte(got)) {
+				t.Errorf("%s, %d bytes: invalid JSON %s", tt.name, maxBytes, got)
+			}
+			for i, chunk := range chunks {
+				if chunk.Count > 1 && len(chunk.Data[0]) > maxBytes {
+					t.Errorf("%s, %d bytes: chunk %d of %d bytes", tt.name, maxBytes, i, len(chunk.Data[0]))
+				}
+			}
+		}
+	}
+}
+
+func TestJSONChunkerValues(t *testing.T) {
+	decimal := &arrow.Decimal128Type{Precision: 10, Scale: 2}
+
+	tests := []struct {
+		name     string
+		typ      arrow.DataType
+		values   string
+		decimals pb.DecimalEncoding
+		want     string
+	}{
+		{"null", arrow.PrimitiveTypes.Int32, `[null]`, 0, `null`},
+		{"bool", arrow.FixedWidthTypes.Boolean, `[true]`, 0, `true`},
+		{"safe integer", arrow.PrimitiveTypes.Int64, `[-9007199254740991]`, 0, `-9007199254740991`},
+		{"unsafe integer", arrow.PrimitiveTypes.Int64, `[9007199254740992]`, 0, `"9007199254740992"`},
+		{"unsafe unsigned integer", arrow.PrimitiveTypes.Uint64, `[9223372036854775808]`, 0, `"9223372036854775808"`},
+		{"float", arrow.PrimitiveTypes.Float64, `[1.5]`, 0, `1.5`},
+		{"decimal by default", decimal, `["12.30"]`, pb.DecimalEncoding_DECIMAL_ENCODING_UNSPECIFIED, `"12.30"`},
+		{"decimal as string", decimal, `["12.30"]`, pb.DecimalEncoding_DECIMAL_AS_STRING, `"12.30"`},
+		{"decimal as number", decimal, `["12.30"]`, pb.DecimalEncoding_DECIMAL_AS_NUMBER, `12.30`},
+		{"escaped string", arrow.BinaryTypes.String, `["a\"b\\c\nd\u0001é"]`, 0, `"a\"b\\c\nd\u0001é"`},
+		{"binary", arrow.BinaryTypes.Binary, `["AQI="]`, 0, `"AQI="`},
+		{"date", arrow.FixedWidthTypes.Date32, `["2024-02-29"]`, 0, `"2024-02-29"`},
+		{"time", arrow.FixedWidthTypes.Time64us, `["13:14:15.5"]`, 0, `"13:14:15.5"`},
+		{"timestamp", arrow.FixedWidthTypes.Timestamp_us, `["2024-02-29T13:14:15Z"]`, 0, `"2024-02-29T13:14:15Z"`},
+		{"timestamp with a zone", &arrow.TimestampType{Unit: arrow.Second, TimeZone: "Europe/Paris"}, `["2024-02-29T13:14:15Z"]`, 0, `"2024-02-29T14:14:15+01:00"`},
+		{"list", arrow.ListOf(arrow.PrimitiveTypes.Int32), `[[1,null,3]]`, 0, `[1,null,3]`},
+		{"struct", arrow.StructOf(arrow.Field{Name: "a", Type: arrow.PrimitiveTypes.Int32}, arrow.Field{Name: "b", Type: arrow.BinaryTypes.String}),
+			`[{"a":1,"b":"x"}]`, 0, `{"a":1,"b":"x"}`},
+		{"map with string keys", arrow.MapOf(arrow.BinaryTypes.String, arrow.PrimitiveTypes.Int32),
+			`[[{"key":"a","value":1},{"key":"b","value":2}]]`, 0, `{"a":1,"b":2}`},
+		{"map with other keys", arrow.MapOf(arrow.PrimitiveTypes.Int32, arrow.BinaryTypes.String),
+			`[[{"key":1,"value":"a"}]]`, 0, `[{"key":1,"value":"a"}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mem := memory.NewCheckedAllocator(memory.DefaultAllocator)
+			defer mem.AssertSize(t, 0)
+
+			schema := arrow.NewSchema([]arrow.Field{{Name: "v", Type: tt.typ, Nullable: true}}, nil)
+			rows := newRecords(t, mem, schema, valueRows(t, tt.values))
+			defer rows.Release()
+
+			chunks := chunkAll(t, rows, &pb.JSONOptions{Format: pb.JSONFormat_NDJSON, Decimals: tt.decimals}, 1<<20)
+			got, _ := join(chunks)
+			if want := `{"v":` + tt.want + "}\n"; got != want {
+				t.Errorf("got %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+// valueRows turns a JSON array of values into rows of a single column v.
+func valueRows(t *testing.T, values string) string {
+	t.Helper()
+
+	var list []json.RawMessage
+	if err := json.Unmarshal([]byte(values), &list); err != nil {
+		t.Fatal(err)
+	}
+	rows := make([]string, len(list))
+	for i, v := range list {
+		rows[i] = `{"v":` + string(v) + `}`
+	}
+	return "[" + strings.Join(rows, ",") + "]"
+}
+
+func TestAppendFloat(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{0, `0`},
+		{-2.25, `-2.25`},
+		{1e21, `1e+21`},
+		{math.NaN(), `"NaN"`},
+		{math.Inf(1), `"+Inf"`},
+		{math.Inf(-1), `"-Inf"`},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		appendFloat(&buf, tt.v, 64)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("appendFloat(%v) = %s, want %s", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestAppendString(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"", `""`},
+		{"plain", `"plain"`},
+		{"tab\tquote\"", `"tab\tquote\""`},
+		{"\x1f", `"\u001f"`},
+		{"invalid \xff utf-8", `"invalid � utf-8"`},
+		{"数据 🦆", `"数据 🦆"`},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		appendString(&buf, tt.s)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("appendString(%q) = %s, want %s", tt.s, got, tt.want)
+		}
+		if !json.Valid(buf.Bytes()) {
+			t.Errorf("appendString(%q) = %s is not valid JSON", tt.s, buf.String())
+		}
+	}
+}
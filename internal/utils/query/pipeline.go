@@ -0,0 +1,23 @@
+package query
+
+import (
+	querybuilder "duckdb-server/internal/query_builder"
+	pb "duckdb-server/internal/services/grpc_arrow/data_transform"
+	"encoding/json"
+
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// PipelineFromProto converts the protobuf pipeline to the query builder one.
+// Both share the protobuf JSON mapping, so the conversion goes through it.
+func PipelineFromProto(p *pb.Pipeline) (querybuilder.Pipeline, error) {
+	var pipeline querybuilder.Pipeline
+
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(p)
+	if err != nil {
+		return pipeline, err
+	}
+
+	err = json.Unmarshal(data, &pipeline)
+	return pipeline, err
+}